@@ -4,18 +4,69 @@ type (
 	Collection interface {
 		Do(func(interface{})bool)
 	}
+
+	// RandomAccess is a Collection whose items can be reached by position
+	// without walking the items before them. GetRange uses it when available.
+	RandomAccess interface {
+		Collection
+
+		// Get the number of items
+		Len() int
+
+		// Get the item at position i. Panics if i is out of range.
+		At(i int) interface{}
+
+		// Get the items from position start up to but excluding end. Panics
+		// if the bounds are out of range.
+		Slice(start, end int) []interface{}
+
+		// Get the position of item, or -1 if it is not in the collection
+		IndexOf(item interface{}) int
+	}
+
+	// Entry is a key/value pair produced by the Entries view of a keyed container
+	Entry struct {
+		Key, Value interface{}
+	}
+
+	// DoFunc adapts an ordinary iteration function to the Collection interface
+	DoFunc func(func(interface{}) bool)
 )
 
+func (f DoFunc) Do(visit func(interface{}) bool) {
+	f(visit)
+}
+
+// Get up to length items starting at position start. Items before position
+// 0 are treated as missing, so a negative start shortens the page.
 func GetRange(c Collection, start, length int) []interface{} {
+	if start < 0 {
+		length += start
+		start = 0
+	}
+	if length <= 0 {
+		return []interface{}{}
+	}
+
+	if ra, ok := c.(RandomAccess); ok {
+		n := ra.Len()
+		if start >= n {
+			return []interface{}{}
+		}
+		end := n
+		if length < n-start {
+			end = start + length
+		}
+		return ra.Slice(start, end)
+	}
+
 	end := start + length
-	items := make([]interface{}, length)
+	items := make([]interface{}, 0)
 	i := 0
-	j := 0
 	c.Do(func(item interface{})bool{
 		if i >= start {
 			if i < end {
-				items[j] = item
-				j++
+				items = append(items, item)
 			} else {
 				return false
 			}
@@ -23,5 +74,5 @@ func GetRange(c Collection, start, length int) []interface{} {
 		i++
 		return true
 	})
-	return items[:j]
+	return items
 }
@@ -0,0 +1,119 @@
+package collections_test
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/billryan/collections"
+	"github.com/billryan/collections/grid"
+	"github.com/billryan/collections/queue"
+	"github.com/billryan/collections/set"
+	"github.com/billryan/collections/skip"
+	"github.com/billryan/collections/splay"
+	"github.com/billryan/collections/stack"
+	"github.com/billryan/collections/trie"
+	"github.com/billryan/collections/tst"
+)
+
+func TestGetRange(t *testing.T) {
+	less := func(a, b interface{}) bool { return a.(int) < b.(int) }
+
+	sl := skip.New(less)
+	tree := splay.New(less)
+	tr := trie.New()
+	tt := tst.New()
+	q := queue.New()
+	st := stack.New()
+	for i := 0; i < 5; i++ {
+		sl.Insert(i, i*10)
+		tree.Add(i)
+		tr.Insert(fmt.Sprint(i), i*10)
+		tt.Insert(fmt.Sprint(i), i*10)
+		q.Enqueue(i)
+		st.Push(4 - i)
+	}
+	g := grid.New(1, 5)
+	for i := 0; i < 5; i++ {
+		g.Set(Point{X: 0, Y: i}, i*10)
+	}
+
+	cases := []struct {
+		name string
+		c    Collection
+		want string
+	}{
+		{"skip keys", sl.Keys(), "[1 2]"},
+		{"skip values", sl.Values(), "[10 20]"},
+		{"skip entries", sl.Entries(), "[{1 10} {2 20}]"},
+		{"splay", tree, "[1 2]"},
+		{"trie keys", tr.Keys(), "[1 2]"},
+		{"trie values", tr.Values(), "[10 20]"},
+		{"trie entries", tr.Entries(), "[{1 10} {2 20}]"},
+		{"tst keys", tt.Keys(), "[1 2]"},
+		{"tst values", tt.Values(), "[10 20]"},
+		{"tst entries", tt.Entries(), "[{1 10} {2 20}]"},
+		{"grid keys", g.Keys(), "[{0 1} {0 2}]"},
+		{"grid values", g.Values(), "[10 20]"},
+		{"grid entries", g.Entries(), "[{{0 1} 10} {{0 2} 20}]"},
+		{"queue", q, "[1 2]"},
+		{"stack", st, "[1 2]"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprint(GetRange(c.c, 1, 2)); got != c.want {
+			t.Errorf("%s: expecting page %s, got %s", c.name, c.want, got)
+		}
+		if got := GetRange(c.c, 4, 10); len(got) != 1 {
+			t.Errorf("%s: expecting last page to hold 1 item, got %v", c.name, got)
+		}
+		if got := GetRange(c.c, 5, 2); len(got) != 0 {
+			t.Errorf("%s: expecting page past the end to be empty, got %v", c.name, got)
+		}
+	}
+
+	// Sets have no stable order, so only the page sizes can be checked
+	for _, s := range []set.Set{set.NewHashSet(1, 2, 3), set.NewConcurrentSet(1, 2, 3)} {
+		if got := GetRange(s.(Collection), 1, 2); len(got) != 2 {
+			t.Errorf("expecting a page of %T to hold 2 items, got %v", s, got)
+		}
+		if got := set.NewHashSet(GetRange(s.(Collection), 0, 10)...); got.Len() != 3 || !got.IsSubset(s) {
+			t.Errorf("expecting a single page of %T to hold the set, got %v", s, got.ToSlice())
+		}
+	}
+}
+
+type countingCollection struct {
+	RandomAccess
+	visited int
+}
+
+func (c *countingCollection) Do(f func(interface{}) bool) {
+	c.RandomAccess.Do(func(item interface{}) bool {
+		c.visited++
+		return f(item)
+	})
+}
+
+func TestGetRangeRandomAccess(t *testing.T) {
+	sl := skip.New(func(a, b interface{}) bool { return a.(int) < b.(int) })
+	for i := 0; i < 1000; i++ {
+		sl.Insert(i, i)
+	}
+
+	c := &countingCollection{RandomAccess: sl.Keys()}
+	if got := fmt.Sprint(GetRange(c, 990, 20)); got != "[990 991 992 993 994 995 996 997 998 999]" {
+		t.Errorf("expecting the last page, got %s", got)
+	}
+	if c.visited != 0 {
+		t.Errorf("expecting GetRange to skip the walk, visited %d items", c.visited)
+	}
+
+	if got := GetRange(sl.Keys(), 0, -1); len(got) != 0 {
+		t.Errorf("expecting negative length to give an empty page, got %v", got)
+	}
+	if got := GetRange(queue.New(), 0, -1); len(got) != 0 {
+		t.Errorf("expecting negative length to give an empty page, got %v", got)
+	}
+	if got := fmt.Sprint(GetRange(sl.Keys(), -2, 4)); got != "[0 1]" {
+		t.Errorf("expecting negative start to shorten the page, got %s", got)
+	}
+}
@@ -0,0 +1,127 @@
+// Package compare provides ready-made three-way comparators and combinators
+// for them.
+//
+// A Func can be passed straight to skip.NewMap and splay.NewMap. Its Less
+// method adapts it to the untyped less function taken by skip.New and
+// splay.New, checking the type of every value so that a mismatch panics with
+// a message naming the types involved rather than deep inside the container.
+package compare
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+type (
+	// Func is a three-way comparison in the style of cmp.Compare. It returns
+	// a negative number, zero or a positive number when a is less than,
+	// equal to or greater than b.
+	Func[T any] func(a, b T) int
+)
+
+var (
+	Int     Func[int]     = cmp.Compare[int]
+	Int8    Func[int8]    = cmp.Compare[int8]
+	Int16   Func[int16]   = cmp.Compare[int16]
+	Int32   Func[int32]   = cmp.Compare[int32]
+	Int64   Func[int64]   = cmp.Compare[int64]
+	Uint    Func[uint]    = cmp.Compare[uint]
+	Uint8   Func[uint8]   = cmp.Compare[uint8]
+	Uint16  Func[uint16]  = cmp.Compare[uint16]
+	Uint32  Func[uint32]  = cmp.Compare[uint32]
+	Uint64  Func[uint64]  = cmp.Compare[uint64]
+	Uintptr Func[uintptr] = cmp.Compare[uintptr]
+
+	// Floats order NaN before every other value, including -Inf, and treat
+	// all NaNs as equal, so they form a total order.
+	Float32 Func[float32] = cmp.Compare[float32]
+	Float64 Func[float64] = cmp.Compare[float64]
+
+	// String compares strings byte-wise.
+	String Func[string] = cmp.Compare[string]
+
+	// Time orders instants chronologically, ignoring the location.
+	Time Func[time.Time] = func(a, b time.Time) int {
+		return a.Compare(b)
+	}
+)
+
+// Get the natural order of an ordered type
+func Ordered[T cmp.Ordered]() Func[T] {
+	return cmp.Compare[T]
+}
+
+// Adapt the comparator to an untyped less function, as taken by skip.New and
+// splay.New. The function panics if either value is not a T.
+func (c Func[T]) Less() func(a, b interface{}) bool {
+	return func(a, b interface{}) bool {
+		return c(assert[T](a), assert[T](b)) < 0
+	}
+}
+
+// Get whether a and b are equal according to the comparator
+func (c Func[T]) Equal(a, b T) bool {
+	return c(a, b) == 0
+}
+
+// Reverse the order of a comparator
+func Reverse[T any](c Func[T]) Func[T] {
+	return func(a, b T) int {
+		return c(b, a)
+	}
+}
+
+// Compare with first, falling back to the others in turn while the values
+// are equal. This gives lexicographic order over composite keys.
+func Then[T any](first Func[T], rest ...Func[T]) Func[T] {
+	return func(a, b T) int {
+		if r := first(a, b); r != 0 {
+			return r
+		}
+		for _, c := range rest {
+			if r := c(a, b); r != 0 {
+				return r
+			}
+		}
+		return 0
+	}
+}
+
+// Compare values by a key extracted from them
+func By[T, K any](key func(T) K, c Func[K]) Func[T] {
+	return func(a, b T) int {
+		return c(key(a), key(b))
+	}
+}
+
+// Compare pointers by the values they point to, ordering nil before
+// everything else
+func NilsFirst[T any](c Func[T]) Func[*T] {
+	return func(a, b *T) int {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		case b == nil:
+			return 1
+		}
+		return c(*a, *b)
+	}
+}
+
+// Compare pointers by the values they point to, ordering nil after
+// everything else
+func NilsLast[T any](c Func[T]) Func[*T] {
+	return Reverse(NilsFirst(Reverse(c)))
+}
+
+func assert[T any](v interface{}) T {
+	t, ok := v.(T)
+	if !ok {
+		panic(fmt.Sprintf("compare: expected a %v, got %T (%v)", reflect.TypeFor[T](), v, v))
+	}
+	return t
+}
@@ -0,0 +1,105 @@
+package compare
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/billryan/collections"
+	"github.com/billryan/collections/skip"
+	"github.com/billryan/collections/splay"
+)
+
+func TestLess(t *testing.T) {
+	sl := skip.New(Int.Less())
+	sl.Insert(2, "b")
+	sl.Insert(1, "a")
+	if k, _ := sl.At(0); k.(int) != 1 {
+		t.Errorf("expecting 1 first, got %v", k)
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(r.(string), "expected a int, got string") {
+			t.Errorf("expecting a type mismatch panic, got %v", r)
+		}
+	}()
+	sl.Insert("3", "c")
+}
+
+func TestFloat(t *testing.T) {
+	nan := math.NaN()
+	fs := []float64{1, nan, math.Inf(-1), 0, nan}
+	sort.Slice(fs, func(i, j int) bool { return Float64(fs[i], fs[j]) < 0 })
+	if !math.IsNaN(fs[0]) || !math.IsNaN(fs[1]) || fs[2] != math.Inf(-1) || fs[4] != 1 {
+		t.Errorf("expecting NaNs first, got %v", fs)
+	}
+	if !Float64.Equal(nan, nan) {
+		t.Errorf("expecting NaNs to be equal")
+	}
+}
+
+func TestTime(t *testing.T) {
+	now := time.Now()
+	if Time(now, now.Add(time.Second)) >= 0 || Time(now, now.In(time.UTC)) != 0 {
+		t.Errorf("expecting chronological order regardless of location")
+	}
+}
+
+func TestPoint(t *testing.T) {
+	if RowMajor(collections.Point{X: 5, Y: 0}, collections.Point{X: 0, Y: 1}) >= 0 {
+		t.Errorf("expecting row 0 before row 1")
+	}
+
+	var ps []collections.Point
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			ps = append(ps, collections.Point{X: x, Y: y})
+		}
+	}
+	sort.Slice(ps, func(i, j int) bool { return ZOrder(ps[i], ps[j]) < 0 })
+	want := [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {2, 0}, {3, 0}, {2, 1}, {3, 1}}
+	for i, p := range want {
+		if ps[i].X != p[0] || ps[i].Y != p[1] {
+			t.Fatalf("expecting %v at %d, got %v", p, i, ps[i])
+		}
+	}
+	if ZOrder(collections.Point{X: -1, Y: 0}, collections.Point{X: 0, Y: 0}) >= 0 {
+		t.Errorf("expecting negative coordinates first")
+	}
+}
+
+type person struct {
+	name string
+	age  int
+}
+
+func TestCombinators(t *testing.T) {
+	byAge := By(func(p person) int { return p.age }, Int)
+	byName := By(func(p person) string { return p.name }, String)
+	people := []person{{"bob", 30}, {"al", 30}, {"cy", 20}}
+
+	sort.Slice(people, func(i, j int) bool { return Then(Reverse(byAge), byName)(people[i], people[j]) < 0 })
+	if people[0].name != "al" || people[1].name != "bob" || people[2].name != "cy" {
+		t.Errorf("expecting oldest first, then by name, got %v", people)
+	}
+
+	one, two := 1, 2
+	ptrs := []*int{&two, nil, &one}
+	sort.Slice(ptrs, func(i, j int) bool { return NilsFirst(Int)(ptrs[i], ptrs[j]) < 0 })
+	if ptrs[0] != nil || *ptrs[1] != 1 || *ptrs[2] != 2 {
+		t.Errorf("expecting nil first")
+	}
+	sort.Slice(ptrs, func(i, j int) bool { return NilsLast(Int)(ptrs[i], ptrs[j]) < 0 })
+	if *ptrs[0] != 1 || *ptrs[1] != 2 || ptrs[2] != nil {
+		t.Errorf("expecting nil last")
+	}
+
+	tree := splay.NewMap[person, bool](Then(byName, byAge))
+	tree.Put(person{"al", 1}, true)
+	if _, ok := tree.Get(person{"al", 1}); !ok {
+		t.Errorf("expecting composite key to be found")
+	}
+}
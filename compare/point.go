@@ -0,0 +1,39 @@
+package compare
+
+import (
+	"github.com/billryan/collections"
+)
+
+var (
+	// RowMajor orders points by row (Y), then by column (X).
+	RowMajor Func[collections.Point] = func(a, b collections.Point) int {
+		if r := Int(a.Y, b.Y); r != 0 {
+			return r
+		}
+		return Int(a.X, b.X)
+	}
+
+	// ZOrder orders points along the Z-order (Morton) curve, which keeps
+	// points that are close in the plane mostly close in the order. Bits of
+	// Y rank above the bits of X at the same position, and negative
+	// coordinates come before positive ones.
+	ZOrder Func[collections.Point] = func(a, b collections.Point) int {
+		ax, ay := flip(a.X), flip(a.Y)
+		bx, by := flip(b.X), flip(b.Y)
+		dx, dy := ax^bx, ay^by
+		if lessMSB(dy, dx) {
+			return Uint64(ax, bx)
+		}
+		return Uint64(ay, by)
+	}
+)
+
+// Map a signed coordinate onto an unsigned one with the same order
+func flip(v int) uint64 {
+	return uint64(v) ^ (1 << 63)
+}
+
+// Test whether the highest set bit of x is below the highest set bit of y
+func lessMSB(x, y uint64) bool {
+	return x < y && x < x^y
+}
@@ -0,0 +1,157 @@
+package compare
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// FoldString compares strings under Unicode simple case folding, so
+	// "Go", "GO" and "go" are equal.
+	FoldString Func[string] = foldString
+
+	// NaturalString compares runs of decimal digits by their numeric value,
+	// so "file2" sorts before "file10". Other runes compare byte-wise. Ties
+	// such as "a01" and "a1" are broken byte-wise to keep the order total.
+	NaturalString Func[string] = naturalString
+
+	// Collate compares strings the way people expect them in a dictionary:
+	// first by letters ignoring case and accents, then by accents, then by
+	// case, then byte-wise. Accents are recognised for the Latin-1 Supplement
+	// and Latin Extended-A blocks. This is a simplification of the Unicode
+	// Collation Algorithm without language specific tailoring.
+	Collate Func[string] = collate
+)
+
+func foldString(a, b string) int {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if r := cmp32(fold(ra), fold(rb)); r != 0 {
+			return r
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return cmpLen(a, b)
+}
+
+// Map a rune to the smallest rune in its case folding orbit
+func fold(r rune) rune {
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
+
+func naturalString(a, b string) int {
+	x, y := a, b
+	for x != "" && y != "" {
+		if isDigit(x[0]) && isDigit(y[0]) {
+			dx, dy := digits(x), digits(y)
+			if r := cmpNumber(dx, dy); r != 0 {
+				return r
+			}
+			x, y = x[len(dx):], y[len(dy):]
+			continue
+		}
+		if x[0] != y[0] {
+			return cmp32(rune(x[0]), rune(y[0]))
+		}
+		x, y = x[1:], y[1:]
+	}
+	if r := cmpLen(x, y); r != 0 {
+		return r
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// Get the leading run of digits of s
+func digits(s string) string {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i]
+}
+
+// Compare two runs of digits by their numeric value
+func cmpNumber(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return cmpInt(len(a), len(b))
+	}
+	return strings.Compare(a, b)
+}
+
+func collate(a, b string) int {
+	// Primary: base letters
+	if r := compareRunes(a, b, func(r rune) rune { return fold(unaccent(r)) }); r != 0 {
+		return r
+	}
+	// Secondary: accents
+	if r := compareRunes(a, b, fold); r != 0 {
+		return r
+	}
+	// Tertiary: case, with lower case first
+	if r := compareRunes(a, b, upper); r != 0 {
+		return r
+	}
+	return strings.Compare(a, b)
+}
+
+// Compare a and b rune by rune after mapping each rune with key
+func compareRunes(a, b string, key func(rune) rune) int {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if r := cmp32(key(ra), key(rb)); r != 0 {
+			return r
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return cmpLen(a, b)
+}
+
+// Rank lower case before upper case
+func upper(r rune) rune {
+	if unicode.IsUpper(r) {
+		return 1
+	}
+	return 0
+}
+
+// Strip the accent from a Latin letter
+func unaccent(r rune) rune {
+	if base, ok := accents[r]; ok {
+		return base
+	}
+	return r
+}
+
+func cmp32(a, b rune) int {
+	return cmpInt(int(a), int(b))
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Order an exhausted string before one with runes left
+func cmpLen(a, b string) int {
+	return cmpInt(len(a), len(b))
+}
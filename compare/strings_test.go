@@ -0,0 +1,46 @@
+package compare
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func sorted(c Func[string], s string) string {
+	words := strings.Fields(s)
+	sort.SliceStable(words, func(i, j int) bool { return c(words[i], words[j]) < 0 })
+	return strings.Join(words, " ")
+}
+
+func TestFoldString(t *testing.T) {
+	if FoldString("Go", "gO") != 0 || FoldString("straße", "STRASSE") == 0 {
+		t.Errorf("expecting simple case folding")
+	}
+	if got := sorted(FoldString, "b A a B"); got != "A a b B" {
+		t.Errorf("expecting case insensitive order, got %s", got)
+	}
+	if FoldString("K", "K") != 0 {
+		t.Errorf("expecting Kelvin sign to fold to K")
+	}
+}
+
+func TestNaturalString(t *testing.T) {
+	if got := sorted(NaturalString, "file10 file2 file1 file02 f"); got != "f file1 file02 file2 file10" {
+		t.Errorf("expecting numeric order, got %s", got)
+	}
+	if NaturalString("a1b", "a1b") != 0 || NaturalString("a01", "a1") == 0 {
+		t.Errorf("expecting only identical strings to be equal")
+	}
+}
+
+func TestCollate(t *testing.T) {
+	if got := sorted(Collate, "côté Côte côte cote coté Cote"); got != "cote Cote coté côte Côte côté" {
+		t.Errorf("expecting accents then case to break ties, got %s", got)
+	}
+	if got := sorted(Collate, "Zoe émile Eve zoe"); got != "émile Eve zoe Zoe" {
+		t.Errorf("expecting accents and case to be ignored first, got %s", got)
+	}
+	if Collate("Łódź", "Lodz") <= 0 || Collate("Łódź", "Lodzz") >= 0 {
+		t.Errorf("expecting stroked letters to sort with their base letter")
+	}
+}
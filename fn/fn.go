@@ -0,0 +1,267 @@
+// Package fn provides lazy, short-circuiting operators over
+// collections.Collection. Operators returning a Collection do no work until
+// the result is iterated, and stop pulling from their source as soon as the
+// consumer stops.
+package fn
+
+import (
+	"iter"
+
+	"github.com/billryan/collections"
+	"github.com/billryan/collections/queue"
+	"github.com/billryan/collections/set"
+	"github.com/billryan/collections/stack"
+)
+
+type (
+	// Pair holds the items produced side by side by Zip
+	Pair struct {
+		First, Second interface{}
+	}
+)
+
+// Keep the items for which pred returns true
+func Filter(c collections.Collection, pred func(interface{}) bool) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		c.Do(func(item interface{}) bool {
+			return !pred(item) || visit(item)
+		})
+	})
+}
+
+// Replace every item with f(item)
+func Map(c collections.Collection, f func(interface{}) interface{}) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		c.Do(func(item interface{}) bool {
+			return visit(f(item))
+		})
+	})
+}
+
+// Replace every item with the items of the collection f(item)
+func FlatMap(c collections.Collection, f func(interface{}) collections.Collection) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		c.Do(func(item interface{}) bool {
+			more := true
+			f(item).Do(func(inner interface{}) bool {
+				more = visit(inner)
+				return more
+			})
+			return more
+		})
+	})
+}
+
+// Fold the items into a single value, starting from initial
+func Reduce(c collections.Collection, initial interface{}, f func(acc, item interface{}) interface{}) interface{} {
+	acc := initial
+	c.Do(func(item interface{}) bool {
+		acc = f(acc, item)
+		return true
+	})
+	return acc
+}
+
+// Keep at most the first n items
+func Take(c collections.Collection, n int) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		c.Do(func(item interface{}) bool {
+			i++
+			return visit(item) && i < n
+		})
+	})
+}
+
+// Skip the first n items
+func Drop(c collections.Collection, n int) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		i := 0
+		c.Do(func(item interface{}) bool {
+			if i < n {
+				i++
+				return true
+			}
+			return visit(item)
+		})
+	})
+}
+
+// Keep items up to the first one for which pred returns false
+func TakeWhile(c collections.Collection, pred func(interface{}) bool) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		c.Do(func(item interface{}) bool {
+			return pred(item) && visit(item)
+		})
+	})
+}
+
+// Skip items up to the first one for which pred returns false
+func DropWhile(c collections.Collection, pred func(interface{}) bool) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		dropping := true
+		c.Do(func(item interface{}) bool {
+			if dropping && pred(item) {
+				return true
+			}
+			dropping = false
+			return visit(item)
+		})
+	})
+}
+
+// Group consecutive items into []interface{} chunks of the given size. The
+// last chunk may be shorter. A size below 1 panics.
+func Chunk(c collections.Collection, size int) collections.Collection {
+	if size < 1 {
+		panic("fn: chunk size must be positive")
+	}
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		chunk := make([]interface{}, 0, size)
+		more := true
+		c.Do(func(item interface{}) bool {
+			chunk = append(chunk, item)
+			if len(chunk) < size {
+				return true
+			}
+			more = visit(chunk)
+			chunk = make([]interface{}, 0, size)
+			return more
+		})
+		if more && len(chunk) > 0 {
+			visit(chunk)
+		}
+	})
+}
+
+// Pair up the items of a and b. The result is as long as the shorter of the
+// two.
+func Zip(a, b collections.Collection) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		next, stop := iter.Pull(collections.All(b))
+		defer stop()
+		a.Do(func(item interface{}) bool {
+			other, ok := next()
+			return ok && visit(Pair{item, other})
+		})
+	})
+}
+
+// Drop items equal to one seen before. Items must be comparable.
+func Distinct(c collections.Collection) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		seen := make(map[interface{}]struct{})
+		c.Do(func(item interface{}) bool {
+			if _, ok := seen[item]; ok {
+				return true
+			}
+			seen[item] = struct{}{}
+			return visit(item)
+		})
+	})
+}
+
+// Group the items by key(item), keeping their order within each group
+func GroupBy(c collections.Collection, key func(interface{}) interface{}) map[interface{}][]interface{} {
+	groups := make(map[interface{}][]interface{})
+	c.Do(func(item interface{}) bool {
+		k := key(item)
+		groups[k] = append(groups[k], item)
+		return true
+	})
+	return groups
+}
+
+// Get the smallest item according to less. The first of several equal items
+// wins. Returns false if the collection is empty.
+func Min(c collections.Collection, less func(a, b interface{}) bool) (interface{}, bool) {
+	var min interface{}
+	found := false
+	c.Do(func(item interface{}) bool {
+		if !found || less(item, min) {
+			min = item
+			found = true
+		}
+		return true
+	})
+	return min, found
+}
+
+// Get the largest item according to less. The first of several equal items
+// wins. Returns false if the collection is empty.
+func Max(c collections.Collection, less func(a, b interface{}) bool) (interface{}, bool) {
+	var max interface{}
+	found := false
+	c.Do(func(item interface{}) bool {
+		if !found || less(max, item) {
+			max = item
+			found = true
+		}
+		return true
+	})
+	return max, found
+}
+
+// Count the items
+func Count(c collections.Collection) int {
+	n := 0
+	c.Do(func(interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Wrap a slice as a collection
+func Of(items ...interface{}) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		for _, item := range items {
+			if !visit(item) {
+				return
+			}
+		}
+	})
+}
+
+// Collect the items into a slice
+func ToSlice(c collections.Collection) []interface{} {
+	items := make([]interface{}, 0)
+	c.Do(func(item interface{}) bool {
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// Collect the items into a new hash set
+func ToSet(c collections.Collection) set.Set {
+	s := set.NewHashSet()
+	c.Do(func(item interface{}) bool {
+		s.Add(item)
+		return true
+	})
+	return s
+}
+
+// Collect the items into a new queue, in order
+func ToQueue(c collections.Collection) *queue.Queue {
+	q := queue.New()
+	c.Do(func(item interface{}) bool {
+		q.Enqueue(item)
+		return true
+	})
+	return q
+}
+
+// Collect the items into a new stack, so the last item ends up on top
+func ToStack(c collections.Collection) *stack.Stack {
+	s := stack.New()
+	c.Do(func(item interface{}) bool {
+		s.Push(item)
+		return true
+	})
+	return s
+}
@@ -0,0 +1,151 @@
+package fn
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/billryan/collections"
+)
+
+func ints(n int) collections.Collection {
+	return collections.DoFunc(func(visit func(interface{}) bool) {
+		for i := 0; i < n; i++ {
+			if !visit(i) {
+				return
+			}
+		}
+	})
+}
+
+// Count how many items the source produced
+func counting(c collections.Collection, pulled *int) collections.Collection {
+	return Map(c, func(item interface{}) interface{} {
+		*pulled++
+		return item
+	})
+}
+
+func isEven(item interface{}) bool {
+	return item.(int)%2 == 0
+}
+
+func TestFilterMap(t *testing.T) {
+	c := Map(Filter(ints(10), isEven), func(item interface{}) interface{} {
+		return item.(int) * item.(int)
+	})
+	if got := fmt.Sprint(ToSlice(c)); got != "[0 4 16 36 64]" {
+		t.Errorf("expecting squares of even numbers, got %s", got)
+	}
+}
+
+func TestFlatMap(t *testing.T) {
+	c := FlatMap(Of(1, 2, 3), func(item interface{}) collections.Collection {
+		return ints(item.(int))
+	})
+	if got := fmt.Sprint(ToSlice(c)); got != "[0 0 1 0 1 2]" {
+		t.Errorf("expecting flattened ranges, got %s", got)
+	}
+	if got := fmt.Sprint(ToSlice(Take(c, 2))); got != "[0 0]" {
+		t.Errorf("expecting flat map to stop early, got %s", got)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	sum := Reduce(ints(5), 0, func(acc, item interface{}) interface{} {
+		return acc.(int) + item.(int)
+	})
+	if sum.(int) != 10 {
+		t.Errorf("expecting sum 10, got %v", sum)
+	}
+}
+
+func TestTakeDrop(t *testing.T) {
+	pulled := 0
+	c := Take(counting(ints(100), &pulled), 3)
+	if got := fmt.Sprint(ToSlice(c)); got != "[0 1 2]" {
+		t.Errorf("expecting first 3 items, got %s", got)
+	}
+	if pulled != 3 {
+		t.Errorf("expecting Take to pull 3 items, pulled %d", pulled)
+	}
+	if Count(Take(ints(5), 0)) != 0 {
+		t.Errorf("expecting Take(0) to be empty")
+	}
+
+	if got := fmt.Sprint(ToSlice(Drop(ints(5), 3))); got != "[3 4]" {
+		t.Errorf("expecting last 2 items, got %s", got)
+	}
+
+	lessThan3 := func(item interface{}) bool { return item.(int) < 3 }
+	if got := fmt.Sprint(ToSlice(TakeWhile(ints(5), lessThan3))); got != "[0 1 2]" {
+		t.Errorf("expecting items below 3, got %s", got)
+	}
+	if got := fmt.Sprint(ToSlice(DropWhile(Of(1, 5, 2), lessThan3))); got != "[5 2]" {
+		t.Errorf("expecting items from 5 on, got %s", got)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	if got := fmt.Sprint(ToSlice(Chunk(ints(5), 2))); got != "[[0 1] [2 3] [4]]" {
+		t.Errorf("expecting chunks of 2, got %s", got)
+	}
+	if got := fmt.Sprint(ToSlice(Take(Chunk(ints(6), 2), 1))); got != "[[0 1]]" {
+		t.Errorf("expecting a single chunk, got %s", got)
+	}
+}
+
+func TestZip(t *testing.T) {
+	got := ToSlice(Zip(ints(10), Of("a", "b")))
+	if len(got) != 2 || got[1] != (Pair{1, "b"}) {
+		t.Errorf("expecting zip to stop at the shorter side, got %v", got)
+	}
+	if Count(Take(Zip(ints(10), ints(10)), 3)) != 3 {
+		t.Errorf("expecting zip to stop early")
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	if got := fmt.Sprint(ToSlice(Distinct(Of(1, 2, 1, 3, 2)))); got != "[1 2 3]" {
+		t.Errorf("expecting distinct items in order, got %s", got)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	groups := GroupBy(ints(5), func(item interface{}) interface{} {
+		return isEven(item)
+	})
+	if fmt.Sprint(groups[true]) != "[0 2 4]" || fmt.Sprint(groups[false]) != "[1 3]" {
+		t.Errorf("expecting odd and even groups, got %v", groups)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	less := func(a, b interface{}) bool { return len(a.(string)) < len(b.(string)) }
+	c := Of("bb", "a", "ccc", "d", "eee")
+	if min, ok := Min(c, less); !ok || min != "a" {
+		t.Errorf("expecting min a, got %v", min)
+	}
+	if max, ok := Max(c, less); !ok || max != "ccc" {
+		t.Errorf("expecting max ccc, got %v", max)
+	}
+	if _, ok := Min(Of(), less); ok {
+		t.Errorf("expecting no min of an empty collection")
+	}
+}
+
+func TestCollectors(t *testing.T) {
+	s := ToSet(Of(1, 2, 2, 3))
+	if s.Len() != 3 || !s.ContainsAll(1, 2, 3) {
+		t.Errorf("expecting set of 1, 2, 3")
+	}
+
+	q := ToQueue(ints(3))
+	if q.Len() != 3 || q.Dequeue().(int) != 0 {
+		t.Errorf("expecting queue to keep the order")
+	}
+
+	st := ToStack(ints(3))
+	if st.Len() != 3 || st.Pop().(int) != 2 {
+		t.Errorf("expecting last item on top of the stack")
+	}
+}
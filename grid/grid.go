@@ -1,6 +1,8 @@
 package grid
 
 import (
+	"iter"
+
 	. "github.com/billryan/collections"
 )
 
@@ -9,6 +11,10 @@ type (
 		values     []interface{}
 		cols, rows int
 	}
+	iterator struct {
+		grid  *Grid
+		index int
+	}
 )
 
 func New(cols, rows int) *Grid {
@@ -22,16 +28,68 @@ func New(cols, rows int) *Grid {
 func (this *Grid) Do(f func(p Point, value interface{})) {
 	for x := 0; x < this.cols; x++ {
 		for y := 0; y < this.rows; y++ {
-			f(Point{x, y}, this.values[x*this.cols+y])
+			f(Point{X: x, Y: y}, this.values[this.index(x, y)])
+		}
+	}
+}
+
+// Get an iterator over the cells in the same order as Do. Keys are the
+// points of the cells.
+func (this *Grid) Iterator() Iterator {
+	return &iterator{this, -1}
+}
+
+// Get a sequence over the cells in the same order as Do
+func (this *Grid) All() iter.Seq2[Point, interface{}] {
+	return func(yield func(Point, interface{}) bool) {
+		for x := 0; x < this.cols; x++ {
+			for y := 0; y < this.rows; y++ {
+				if !yield(Point{X: x, Y: y}, this.values[this.index(x, y)]) {
+					return
+				}
+			}
 		}
 	}
 }
 
+// Get a view of the points of the cells, in the same order as Do
+func (this *Grid) Keys() Collection {
+	return DoFunc(func(f func(interface{}) bool) {
+		for p := range this.All() {
+			if !f(p) {
+				return
+			}
+		}
+	})
+}
+
+// Get a view of the values of the cells, in the same order as Do
+func (this *Grid) Values() Collection {
+	return DoFunc(func(f func(interface{}) bool) {
+		for _, v := range this.All() {
+			if !f(v) {
+				return
+			}
+		}
+	})
+}
+
+// Get a view of the cells as Entry items keyed by point, in the same order as Do
+func (this *Grid) Entries() Collection {
+	return DoFunc(func(f func(interface{}) bool) {
+		for p, v := range this.All() {
+			if !f(Entry{Key: p, Value: v}) {
+				return
+			}
+		}
+	})
+}
+
 func (this *Grid) Get(p Point) interface{} {
-	if p.X < 0 || p.Y < 0 || p.X >= this.cols || p.Y >= this.rows {
+	if !this.contains(p) {
 		return nil
 	}
-	v := this.values[p.X*this.cols+p.Y]
+	v := this.values[this.index(p.X, p.Y)]
 	return v
 }
 
@@ -48,5 +106,36 @@ func (this *Grid) Len() int {
 }
 
 func (this *Grid) Set(p Point, v interface{}) {
+	if !this.contains(p) {
+		return
+	}
+	this.values[this.index(p.X, p.Y)] = v
+}
+
+func (this *Grid) contains(p Point) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < this.cols && p.Y < this.rows
+}
+
+func (this *Grid) index(x, y int) int {
+	return x*this.rows + y
+}
+
+func (this *iterator) Next() bool {
+	if this.index+1 >= len(this.grid.values) {
+		return false
+	}
+	this.index++
+	return true
+}
+
+func (this *iterator) Key() interface{} {
+	return Point{X: this.index / this.grid.rows, Y: this.index % this.grid.rows}
+}
+
+func (this *iterator) Value() interface{} {
+	return this.grid.values[this.index]
+}
 
+func (this *iterator) Err() error {
+	return nil
 }
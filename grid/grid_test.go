@@ -0,0 +1,70 @@
+package grid
+
+import (
+	"testing"
+
+	. "github.com/billryan/collections"
+)
+
+func Test(t *testing.T) {
+	g := New(2, 3)
+	if g.Len() != 6 {
+		t.Errorf("expecting len 6")
+	}
+	g.Set(Point{X: 1, Y: 2}, "x")
+	g.Set(Point{X: 2, Y: 0}, "out of bounds")
+	if g.Get(Point{X: 1, Y: 2}) != "x" {
+		t.Errorf("expecting (1, 2) to be x")
+	}
+	if g.Get(Point{X: 2, Y: 0}) != nil {
+		t.Errorf("expecting nothing out of bounds")
+	}
+}
+
+func TestDo(t *testing.T) {
+	g := New(2, 3)
+	for x := 0; x < 2; x++ {
+		for y := 0; y < 3; y++ {
+			g.Set(Point{X: x, Y: y}, x*10+y)
+		}
+	}
+	n := 0
+	g.Do(func(p Point, v interface{}) {
+		if v != p.X*10+p.Y {
+			t.Errorf("unexpected value %v at %v", v, p)
+		}
+		n++
+	})
+	if n != 6 {
+		t.Errorf("expecting 6 cells, got %d", n)
+	}
+}
+
+func TestIterator(t *testing.T) {
+	g := New(2, 3)
+	g.Set(Point{X: 1, Y: 2}, "x")
+
+	it := g.Iterator()
+	n := 0
+	for it.Next() {
+		p := it.Key().(Point)
+		if (p == Point{X: 1, Y: 2}) != (it.Value() == "x") {
+			t.Errorf("unexpected value %v at %v", it.Value(), p)
+		}
+		n++
+	}
+	if n != 6 || it.Err() != nil {
+		t.Errorf("expecting 6 cells")
+	}
+
+	n = 0
+	for p, v := range g.All() {
+		if v == "x" && (p != Point{X: 1, Y: 2}) {
+			t.Errorf("unexpected value at %v", p)
+		}
+		n++
+	}
+	if n != 6 {
+		t.Errorf("expecting 6 cells")
+	}
+}
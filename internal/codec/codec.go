@@ -0,0 +1,331 @@
+// Package codec writes and reads set elements in the compact binary form
+// shared by the set packages, and orders elements of any type so that
+// encodings can be deterministic. Errors are not prefixed; callers wrap
+// them with the name of their package.
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// Tags giving the type of each element
+const (
+	tagNil = iota
+	tagBool
+	tagInt
+	tagInt8
+	tagInt16
+	tagInt32
+	tagInt64
+	tagUint
+	tagUint8
+	tagUint16
+	tagUint32
+	tagUint64
+	tagFloat32
+	tagFloat64
+	tagString
+)
+
+// ErrTruncated is reported when the data ends in the middle of a value.
+var ErrTruncated = errors.New("truncated data")
+
+// AppendElement appends e as a type tag and its value. Integers are
+// varints, floats little-endian and strings prefixed by their length. Only
+// nil and the basic types but complex numbers and uintptr can be encoded.
+func AppendElement(b []byte, e interface{}) ([]byte, error) {
+	switch v := e.(type) {
+	case nil:
+		b = append(b, tagNil)
+	case bool:
+		x := byte(0)
+		if v {
+			x = 1
+		}
+		b = append(b, tagBool, x)
+	case int:
+		b = binary.AppendVarint(append(b, tagInt), int64(v))
+	case int8:
+		b = binary.AppendVarint(append(b, tagInt8), int64(v))
+	case int16:
+		b = binary.AppendVarint(append(b, tagInt16), int64(v))
+	case int32:
+		b = binary.AppendVarint(append(b, tagInt32), int64(v))
+	case int64:
+		b = binary.AppendVarint(append(b, tagInt64), v)
+	case uint:
+		b = binary.AppendUvarint(append(b, tagUint), uint64(v))
+	case uint8:
+		b = binary.AppendUvarint(append(b, tagUint8), uint64(v))
+	case uint16:
+		b = binary.AppendUvarint(append(b, tagUint16), uint64(v))
+	case uint32:
+		b = binary.AppendUvarint(append(b, tagUint32), uint64(v))
+	case uint64:
+		b = binary.AppendUvarint(append(b, tagUint64), v)
+	case float32:
+		b = binary.LittleEndian.AppendUint32(append(b, tagFloat32), math.Float32bits(v))
+	case float64:
+		b = binary.LittleEndian.AppendUint64(append(b, tagFloat64), math.Float64bits(v))
+	case string:
+		b = AppendString(append(b, tagString), v)
+	default:
+		return nil, fmt.Errorf("cannot encode %T in binary", e)
+	}
+	return b, nil
+}
+
+// AppendString appends s prefixed by its length.
+func AppendString(b []byte, s string) []byte {
+	return append(binary.AppendUvarint(b, uint64(len(s))), s...)
+}
+
+// Reader reads values from data. After the first error every read returns
+// a zero value, so callers can check Err once at the end.
+type Reader struct {
+	data []byte
+	err  error
+}
+
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+// Err returns the first error met.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// Len returns the number of bytes left.
+func (r *Reader) Len() int {
+	return len(r.data)
+}
+
+// Next returns the next n bytes, or zeros if there are not enough left.
+func (r *Reader) Next(n int) []byte {
+	if r.err != nil || len(r.data) < n {
+		r.fail(ErrTruncated)
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *Reader) Byte() byte {
+	return r.Next(1)[0]
+}
+
+func (r *Reader) Uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	x, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.fail(ErrTruncated)
+		return 0
+	}
+	r.data = r.data[n:]
+	return x
+}
+
+func (r *Reader) Varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	x, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.fail(ErrTruncated)
+		return 0
+	}
+	r.data = r.data[n:]
+	return x
+}
+
+// Count reads a number of items each taking at least min bytes, failing if
+// there are not enough bytes left for them. It keeps corrupt data from
+// making callers allocate huge slices.
+func (r *Reader) Count(min int) int {
+	n := r.Uvarint()
+	if r.err == nil && n > uint64(len(r.data)/min) {
+		r.fail(ErrTruncated)
+		return 0
+	}
+	return int(n)
+}
+
+// String reads a string written by AppendString.
+func (r *Reader) String() string {
+	return string(r.Next(r.Count(1)))
+}
+
+// Element reads an element written by AppendElement.
+func (r *Reader) Element() interface{} {
+	switch tag := r.Byte(); tag {
+	case tagNil:
+		return nil
+	case tagBool:
+		return r.Byte() != 0
+	case tagInt:
+		return int(r.Varint())
+	case tagInt8:
+		return int8(r.Varint())
+	case tagInt16:
+		return int16(r.Varint())
+	case tagInt32:
+		return int32(r.Varint())
+	case tagInt64:
+		return r.Varint()
+	case tagUint:
+		return uint(r.Uvarint())
+	case tagUint8:
+		return uint8(r.Uvarint())
+	case tagUint16:
+		return uint16(r.Uvarint())
+	case tagUint32:
+		return uint32(r.Uvarint())
+	case tagUint64:
+		return r.Uvarint()
+	case tagFloat32:
+		return math.Float32frombits(binary.LittleEndian.Uint32(r.Next(4)))
+	case tagFloat64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(r.Next(8)))
+	case tagString:
+		return r.String()
+	default:
+		r.fail(fmt.Errorf("unknown element tag %d", tag))
+		return nil
+	}
+}
+
+// Close fails unless all data was read, and returns the first error.
+func (r *Reader) Close() error {
+	if r.err == nil && len(r.data) != 0 {
+		r.err = fmt.Errorf("%d bytes after the data", len(r.data))
+	}
+	return r.err
+}
+
+func (r *Reader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Compare orders elements of any type: nil first, then bools, numbers,
+// strings and anything else. Values of named types are ordered with those
+// of their kind. Bools, numbers and strings of different types are ordered
+// by value, then by type name, and other values by their type and Go
+// syntax representation.
+func Compare(a, b interface{}) int {
+	ra, rb := kindRank(a), kindRank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	if ra == rankNil {
+		return 0
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	same := va.Type() == vb.Type()
+	switch ra {
+	case rankBool:
+		if c := compareBools(va.Bool(), vb.Bool()); c != 0 || same {
+			return c
+		}
+	case rankNumber:
+		if same {
+			return compareSameNumbers(va, vb)
+		}
+		if c := compareFloats(toFloat(a), toFloat(b)); c != 0 {
+			return c
+		}
+	case rankString:
+		if c := strings.Compare(va.String(), vb.String()); c != 0 || same {
+			return c
+		}
+	}
+	return strings.Compare(fmt.Sprintf("%T %#v", a, a), fmt.Sprintf("%T %#v", b, b))
+}
+
+const (
+	rankNil = iota
+	rankBool
+	rankNumber
+	rankString
+	rankOther
+)
+
+func kindRank(e interface{}) int {
+	if e == nil {
+		return rankNil
+	}
+	switch reflect.TypeOf(e).Kind() {
+	case reflect.Bool:
+		return rankBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return rankNumber
+	case reflect.String:
+		return rankString
+	}
+	return rankOther
+}
+
+// Compare two numbers of the same type exactly
+func compareSameNumbers(a, b reflect.Value) int {
+	switch {
+	case a.CanInt():
+		return compareInts(a.Int(), b.Int())
+	case a.CanUint():
+		return compareInts(a.Uint(), b.Uint())
+	}
+	return compareFloats(a.Float(), b.Float())
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	}
+	return 1
+}
+
+func compareInts[T int64 | uint64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Compare floats, ordering NaN first
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b, math.IsNaN(a) && !math.IsNaN(b):
+		return -1
+	case a > b, math.IsNaN(b) && !math.IsNaN(a):
+		return 1
+	}
+	return 0
+}
+
+func toFloat(e interface{}) float64 {
+	v := reflect.ValueOf(e)
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
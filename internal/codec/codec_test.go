@@ -0,0 +1,64 @@
+package codec
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestElementRoundTrip(t *testing.T) {
+	es := []interface{}{nil, true, -7, int8(-1), int16(2), int32(3), int64(-4),
+		uint(5), uint8(6), uint16(7), uint32(8), uint64(1 << 63), float32(1.5), 2.5, "x", ""}
+	var b []byte
+	for _, e := range es {
+		var err error
+		if b, err = AppendElement(b, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := NewReader(b)
+	for _, e := range es {
+		if got := r.Element(); got != e {
+			t.Errorf("Got %#v, want %#v", got, e)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := AppendElement(nil, []int{1}); err == nil {
+		t.Error("Encoding a slice should fail")
+	}
+	r = NewReader(b[:len(b)-1])
+	for range es {
+		r.Element()
+	}
+	if r.Err() != ErrTruncated {
+		t.Errorf("Got %v, want %v", r.Err(), ErrTruncated)
+	}
+	r = NewReader([]byte{200})
+	if r.Element(); r.Err() == nil {
+		t.Error("An unknown tag should fail")
+	}
+	if NewReader([]byte{0}).Close() == nil {
+		t.Error("Unread data should fail")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	es := []interface{}{"b", 2, 1.5, true, nil, "a", int64(2), false, uint8(1), struct{}{}}
+	slices.SortFunc(es, Compare)
+	want := []interface{}{nil, false, true, uint8(1), 1.5, 2, int64(2), "a", "b", struct{}{}}
+	if !slices.Equal(es, want) {
+		t.Errorf("Got %v, want %v", es, want)
+	}
+
+	type id string
+	type flag bool
+	es = []interface{}{id("b"), "b", "a", flag(true), false, id("a"), true, flag(false)}
+	slices.SortFunc(es, Compare)
+	// Equal values are ordered by type name, codec.id before string
+	want = []interface{}{false, flag(false), true, flag(true), id("a"), "a", id("b"), "b"}
+	if !slices.Equal(es, want) {
+		t.Errorf("Got %#v, want %#v", es, want)
+	}
+}
@@ -0,0 +1,143 @@
+// Package hashing turns set elements into 64-bit hashes for the
+// probabilistic sets. Hashes are stable across processes and platforms, so
+// filters and sketches built from them can be serialized and exchanged.
+package hashing
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// FNV-1a parameters
+const (
+	offset64 = 14695981039346656037
+	prime64  = 1099511628211
+)
+
+// Tags keeping values of different types apart, as they are in a set
+const (
+	tagNil = iota
+	tagString
+	tagBool
+	tagInt
+	tagInt8
+	tagInt16
+	tagInt32
+	tagInt64
+	tagUint
+	tagUint8
+	tagUint16
+	tagUint32
+	tagUint64
+	tagUintptr
+	tagFloat32
+	tagFloat64
+	tagComplex64
+	tagComplex128
+	tagOther
+)
+
+type fnv uint64
+
+// Sum64 hashes e. Values of the basic types are hashed from their type and
+// value, so that values that are == hash alike; a []byte hashes like the
+// string holding the same bytes. Other values are hashed from their type and
+// Go syntax representation, which is only stable across processes for values
+// without pointers.
+func Sum64(e interface{}) uint64 {
+	h := fnv(offset64)
+	switch v := e.(type) {
+	case nil:
+		h.tag(tagNil)
+	case string:
+		h.tag(tagString)
+		h.writeString(v)
+	case []byte:
+		h.tag(tagString)
+		h.write(v)
+	case bool:
+		x := uint64(0)
+		if v {
+			x = 1
+		}
+		h.number(tagBool, x)
+	case int:
+		h.number(tagInt, uint64(v))
+	case int8:
+		h.number(tagInt8, uint64(v))
+	case int16:
+		h.number(tagInt16, uint64(v))
+	case int32:
+		h.number(tagInt32, uint64(v))
+	case int64:
+		h.number(tagInt64, uint64(v))
+	case uint:
+		h.number(tagUint, uint64(v))
+	case uint8:
+		h.number(tagUint8, uint64(v))
+	case uint16:
+		h.number(tagUint16, uint64(v))
+	case uint32:
+		h.number(tagUint32, uint64(v))
+	case uint64:
+		h.number(tagUint64, v)
+	case uintptr:
+		h.number(tagUintptr, uint64(v))
+	case float32:
+		if v == 0 {
+			// -0 == 0
+			v = 0
+		}
+		h.number(tagFloat32, uint64(math.Float32bits(v)))
+	case float64:
+		if v == 0 {
+			v = 0
+		}
+		h.number(tagFloat64, math.Float64bits(v))
+	case complex64:
+		h.number(tagComplex64, uint64(math.Float32bits(real(v)))<<32|uint64(math.Float32bits(imag(v))))
+	case complex128:
+		h.number(tagComplex128, math.Float64bits(real(v)))
+		h.number(tagComplex128, math.Float64bits(imag(v)))
+	default:
+		h.tag(tagOther)
+		h.write(fmt.Appendf(nil, "%T %#v", e, e))
+	}
+	return Mix(uint64(h))
+}
+
+// Mix spreads the bits of h so that every input bit affects every output
+// bit, using the finalizer of SplitMix64. It turns one hash into another,
+// independent looking one.
+func Mix(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+func (h *fnv) tag(t byte) {
+	*h = (*h ^ fnv(t)) * prime64
+}
+
+func (h *fnv) write(b []byte) {
+	for _, c := range b {
+		*h = (*h ^ fnv(c)) * prime64
+	}
+}
+
+func (h *fnv) writeString(s string) {
+	for i := 0; i < len(s); i++ {
+		*h = (*h ^ fnv(s[i])) * prime64
+	}
+}
+
+func (h *fnv) number(t byte, x uint64) {
+	h.tag(t)
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], x)
+	h.write(b[:])
+}
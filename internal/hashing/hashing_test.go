@@ -0,0 +1,44 @@
+package hashing
+
+import (
+	"math"
+	"math/bits"
+	"testing"
+)
+
+func TestSum64(t *testing.T) {
+	if Sum64("a") != Sum64([]byte("a")) {
+		t.Errorf("A string and its bytes should hash alike")
+	}
+	if Sum64(0.0) != Sum64(math.Copysign(0, -1)) {
+		t.Errorf("0 and -0 should hash alike")
+	}
+	distinct := []interface{}{nil, "", "1", 1, int64(1), uint32(1), 1.0, true, [2]int{1, 2}, [2]int{2, 1}}
+	seen := map[uint64]interface{}{}
+	for _, e := range distinct {
+		h := Sum64(e)
+		if other, ok := seen[h]; ok {
+			t.Errorf("%#v and %#v should hash differently", e, other)
+		}
+		seen[h] = e
+		if Sum64(e) != h {
+			t.Errorf("Hashing %#v should be deterministic", e)
+		}
+	}
+	// Known value, so that a change to the hash, which would break
+	// serialized filters, does not go unnoticed.
+	if h := Sum64("hello"); h != 0xeaea28605b2a588b {
+		t.Errorf("Sum64(\"hello\") should be 0xeaea28605b2a588b, got %#x", h)
+	}
+}
+
+func TestMix(t *testing.T) {
+	// Flipping one input bit should flip about half of the output bits
+	total := 0
+	for i := 0; i < 64; i++ {
+		total += bits.OnesCount64(Mix(42) ^ Mix(42^1<<i))
+	}
+	if avg := float64(total) / 64; avg < 28 || avg > 36 {
+		t.Errorf("Mix should flip about 32 bits per input bit, got %.1f", avg)
+	}
+}
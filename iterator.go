@@ -0,0 +1,48 @@
+package collections
+
+import (
+	"iter"
+)
+
+type (
+	// Iterator is a pull-style cursor over the items of a container.
+	// A new iterator is positioned before the first item, so Next must be
+	// called before the first Key or Value.
+	//
+	// Keyed containers report their keys through Key. Sequences such as
+	// queues and stacks report the position of the item instead, and sets
+	// report the element as both its key and its value.
+	Iterator interface {
+		// Advance to the next item. Returns false once the items are
+		// exhausted or an error occurred.
+		Next() bool
+
+		// Get the key of the current item.
+		Key() interface{}
+
+		// Get the value of the current item.
+		Value() interface{}
+
+		// Get the error that stopped the iteration, if any.
+		Err() error
+	}
+)
+
+// Get a push-style sequence over the items of a collection.
+func All(c Collection) iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		c.Do(yield)
+	}
+}
+
+// Get a push-style sequence over the remaining key/value pairs of an
+// iterator.
+func Pairs(it Iterator) iter.Seq2[interface{}, interface{}] {
+	return func(yield func(interface{}, interface{}) bool) {
+		for it.Next() {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
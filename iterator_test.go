@@ -0,0 +1,61 @@
+package collections
+
+import (
+	"testing"
+)
+
+type sliceCollection []interface{}
+
+func (c sliceCollection) Do(f func(interface{}) bool) {
+	for _, v := range c {
+		if !f(v) {
+			return
+		}
+	}
+}
+
+type sliceIterator struct {
+	items []interface{}
+	index int
+}
+
+func (it *sliceIterator) Next() bool {
+	it.index++
+	return it.index < len(it.items)
+}
+
+func (it *sliceIterator) Key() interface{}   { return it.index }
+func (it *sliceIterator) Value() interface{} { return it.items[it.index] }
+func (it *sliceIterator) Err() error         { return nil }
+
+func TestAll(t *testing.T) {
+	n := 0
+	for v := range All(sliceCollection{1, 2, 3}) {
+		n += v.(int)
+		if v.(int) == 2 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("expecting iteration to stop after 2, got sum %d", n)
+	}
+}
+
+func TestPairs(t *testing.T) {
+	it := &sliceIterator{[]interface{}{"a", "b", "c"}, -1}
+	keys := 0
+	for k, v := range Pairs(it) {
+		keys += k.(int)
+		if v == "b" {
+			break
+		}
+	}
+	if keys != 1 {
+		t.Errorf("expecting keys 0 and 1, got sum %d", keys)
+	}
+	for k := range Pairs(it) {
+		if k.(int) != 2 {
+			t.Errorf("expecting to resume at key 2, got %v", k)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package collections
+
+type (
+	// OrderedMap is a map whose entries are kept sorted by key. The order is
+	// defined by a three-way comparator in the style of cmp.Compare, which
+	// returns a negative number, zero or a positive number when a is less
+	// than, equal to or greater than b.
+	OrderedMap[K, V any] interface {
+		// Get the value stored under key, and whether it was found.
+		Get(key K) (V, bool)
+
+		// Store value under key, replacing any previous value.
+		Put(key K, value V)
+
+		// Delete key from the map. Returns true if it was present.
+		Delete(key K) bool
+
+		// Get the number of entries in the map.
+		Len() int
+
+		// Get the entry with the smallest key, if any.
+		Min() (K, V, bool)
+
+		// Get the entry with the largest key, if any.
+		Max() (K, V, bool)
+
+		// Call f for each entry in ascending key order until f returns false.
+		Ascend(f func(K, V) bool)
+
+		// Call f for each entry in descending key order until f returns false.
+		Descend(f func(K, V) bool)
+	}
+)
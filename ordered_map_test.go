@@ -0,0 +1,96 @@
+package collections_test
+
+import (
+	"cmp"
+	"testing"
+
+	. "github.com/billryan/collections"
+	"github.com/billryan/collections/skip"
+	"github.com/billryan/collections/splay"
+)
+
+func TestOrderedMap(t *testing.T) {
+	impls := map[string]func() OrderedMap[int, string]{
+		"skip":  func() OrderedMap[int, string] { return skip.NewMap[int, string](cmp.Compare[int]) },
+		"splay": func() OrderedMap[int, string] { return splay.NewMap[int, string](cmp.Compare[int]) },
+	}
+	for name, newMap := range impls {
+		t.Run(name, func(t *testing.T) {
+			m := newMap()
+			if _, _, ok := m.Min(); ok {
+				t.Error("empty map should have no min")
+			}
+			if _, ok := m.Get(1); ok {
+				t.Error("empty map should not contain 1")
+			}
+
+			for _, k := range []int{5, 3, 8, 1, 4, 7, 9, 2, 6} {
+				m.Put(k, string(rune('a'+k)))
+			}
+			m.Put(5, "five")
+			if m.Len() != 9 {
+				t.Errorf("expecting len 9, got %d", m.Len())
+			}
+			if v, ok := m.Get(5); !ok || v != "five" {
+				t.Errorf("expecting m[5] == five, got %q", v)
+			}
+			if _, ok := m.Get(10); ok {
+				t.Error("map should not contain 10")
+			}
+
+			if k, _, _ := m.Min(); k != 1 {
+				t.Errorf("expecting min 1, got %d", k)
+			}
+			if k, _, _ := m.Max(); k != 9 {
+				t.Errorf("expecting max 9, got %d", k)
+			}
+
+			if !m.Delete(1) || !m.Delete(9) || !m.Delete(5) || m.Delete(5) {
+				t.Error("expecting 1, 9 and 5 to be deleted exactly once")
+			}
+			if m.Len() != 6 {
+				t.Errorf("expecting len 6, got %d", m.Len())
+			}
+
+			var asc []int
+			m.Ascend(func(k int, v string) bool {
+				asc = append(asc, k)
+				return true
+			})
+			if !equalInts(asc, []int{2, 3, 4, 6, 7, 8}) {
+				t.Errorf("expecting ascending keys, got %v", asc)
+			}
+
+			var desc []int
+			m.Descend(func(k int, v string) bool {
+				desc = append(desc, k)
+				return len(desc) < 3
+			})
+			if !equalInts(desc, []int{8, 7, 6}) {
+				t.Errorf("expecting descending keys to stop after 3, got %v", desc)
+			}
+
+			for _, k := range asc {
+				m.Delete(k)
+			}
+			if m.Len() != 0 {
+				t.Errorf("expecting len 0, got %d", m.Len())
+			}
+			if _, _, ok := m.Max(); ok {
+				t.Error("empty map should have no max")
+			}
+		})
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
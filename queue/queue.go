@@ -1,5 +1,11 @@
 package queue
 
+import (
+	"iter"
+
+	"github.com/billryan/collections"
+)
+
 type (
 	Queue struct {
 		start, end *node
@@ -9,6 +15,11 @@ type (
 		value interface{}
 		next *node
 	}
+	iterator struct {
+		next  *node
+		index int
+		value interface{}
+	}
 )
 
 // Create a new queue
@@ -53,3 +64,42 @@ func (this *Queue) Peek() interface{} {
 	}
 	return this.start.value
 }
+// Call f for each item from the front to the end of the queue until it returns false
+func (this *Queue) Do(f func(interface{}) bool) {
+	for n := this.start; n != nil; n = n.next {
+		if !f(n.value) {
+			return
+		}
+	}
+}
+// Get an iterator over the items from the front to the end of the queue.
+// Keys are the positions of the items.
+func (this *Queue) Iterator() collections.Iterator {
+	return &iterator{this.start, -1, nil}
+}
+// Get a sequence over the items from the front to the end of the queue
+func (this *Queue) All() iter.Seq[interface{}] {
+	return this.Do
+}
+
+func (this *iterator) Next() bool {
+	if this.next == nil {
+		return false
+	}
+	this.value = this.next.value
+	this.next = this.next.next
+	this.index++
+	return true
+}
+
+func (this *iterator) Key() interface{} {
+	return this.index
+}
+
+func (this *iterator) Value() interface{} {
+	return this.value
+}
+
+func (this *iterator) Err() error {
+	return nil
+}
@@ -47,4 +47,34 @@ func Test(t *testing.T) {
 	if q.Peek().(int) != 2 {
 		t.Errorf("Next value should be 2")
 	}
-}
\ No newline at end of file
+}
+
+func TestIterator(t *testing.T) {
+	q := New()
+	for i := 0; i < 3; i++ {
+		q.Enqueue(i * 10)
+	}
+
+	it := q.Iterator()
+	n := 0
+	for it.Next() {
+		if it.Key().(int) != n || it.Value().(int) != n*10 {
+			t.Errorf("expecting item %d to be %d", n, n*10)
+		}
+		n++
+	}
+	if n != 3 || it.Err() != nil {
+		t.Errorf("expecting 3 items without error")
+	}
+
+	vs := make([]int, 0)
+	for v := range q.All() {
+		vs = append(vs, v.(int))
+		if len(vs) == 2 {
+			break
+		}
+	}
+	if len(vs) != 2 || vs[0] != 0 || vs[1] != 10 {
+		t.Errorf("expecting the first two items in order")
+	}
+}
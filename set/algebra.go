@@ -0,0 +1,110 @@
+package set
+
+// The algebra below works on any Set through its interface, so sets of
+// different implementations can be combined freely. Each operation fills the
+// empty set n, which decides the implementation of the result.
+
+// Add the elements of s and all others to n.
+func union(n Set, s Set, others []Set) Set {
+	Do(s, add(n))
+	for _, set := range others {
+		Do(set, add(n))
+	}
+	return n
+}
+
+// Add the elements common to s and all others to n. Only the smallest of
+// the sets is iterated.
+func intersection(n Set, s Set, others []Set) Set {
+	smallest := s
+	for _, set := range others {
+		if set.Len() < smallest.Len() {
+			smallest = set
+		}
+	}
+
+	Do(smallest, func(e interface{}) bool {
+		if smallest != s && !s.Contains(e) {
+			return true
+		}
+		for _, set := range others {
+			if set != smallest && !set.Contains(e) {
+				return true
+			}
+		}
+		n.Add(e)
+		return true
+	})
+	return n
+}
+
+// Add the elements of s that are in none of the others to n.
+func difference(n Set, s Set, others []Set) Set {
+	Do(s, func(e interface{}) bool {
+		for _, set := range others {
+			if set.Contains(e) {
+				return true
+			}
+		}
+		n.Add(e)
+		return true
+	})
+	return n
+}
+
+// Add the elements in exactly one of s and other to n.
+func symmetricDifference(n Set, s Set, other Set) Set {
+	Do(s, func(e interface{}) bool {
+		if !other.Contains(e) {
+			n.Add(e)
+		}
+		return true
+	})
+	Do(other, func(e interface{}) bool {
+		if !s.Contains(e) {
+			n.Add(e)
+		}
+		return true
+	})
+	return n
+}
+
+// Test whether every element of s is in other.
+func isSubset(s Set, other Set) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	subset := true
+	Do(s, func(e interface{}) bool {
+		subset = other.Contains(e)
+		return subset
+	})
+	return subset
+}
+
+// Test whether s and other hold the same elements.
+func equal(s Set, other Set) bool {
+	return s.Len() == other.Len() && isSubset(s, other)
+}
+
+// Test whether s and other have no element in common, iterating the
+// smaller of the two.
+func isDisjoint(s Set, other Set) bool {
+	if other.Len() < s.Len() {
+		s, other = other, s
+	}
+	disjoint := true
+	Do(s, func(e interface{}) bool {
+		disjoint = !other.Contains(e)
+		return disjoint
+	})
+	return disjoint
+}
+
+// Get a visitor adding every element to n.
+func add(n Set) func(interface{}) bool {
+	return func(e interface{}) bool {
+		n.Add(e)
+		return true
+	}
+}
@@ -0,0 +1,138 @@
+package set
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Constructors of every Set implementation, used by the cross
+// implementation tests.
+var implementations = []struct {
+	name string
+	new  func(initial ...interface{}) Set
+}{
+	{"HashSet", NewHashSet},
+	{"ConcurrentSet", NewConcurrentSet},
+	{"SortedSet", newIntSortedSet},
+	{"BitSet", newIntBitSet},
+	{"LinkedHashSet", newLinkedHashSet},
+	{"HasherSet", newDeepHasherSet},
+	{"ShardedSet", newShardedSet},
+	{"ExpiringSet", newExpiringSet},
+}
+
+// Run f for every ordered pair of implementations.
+func forEachPair(t *testing.T, f func(t *testing.T, newA, newB func(...interface{}) Set)) {
+	for _, a := range implementations {
+		for _, b := range implementations {
+			t.Run(fmt.Sprintf("%s/%s", a.name, b.name), func(t *testing.T) {
+				f(t, a.new, b.new)
+			})
+		}
+	}
+}
+
+func sameType(a, b Set) bool {
+	return fmt.Sprintf("%T", a) == fmt.Sprintf("%T", b)
+}
+
+func TestAlgebra_Union(t *testing.T) {
+	forEachPair(t, func(t *testing.T, newA, newB func(...interface{}) Set) {
+		s1 := newA(1, 2, 4)
+		s := s1.Union(newB(1, 2, 8), newB(2, 3))
+		if s.Len() != 5 || !s.ContainsAll(1, 2, 3, 4, 8) {
+			t.Errorf("Set should be 1, 2, 3, 4, 8, got %v", s.ToSlice())
+		}
+		if !sameType(s, s1) {
+			t.Errorf("Union should keep the implementation of the receiver, got %T", s)
+		}
+	})
+}
+
+func TestAlgebra_Intersection(t *testing.T) {
+	forEachPair(t, func(t *testing.T, newA, newB func(...interface{}) Set) {
+		s1 := newA(1, 2, 4, 5, 6, 7)
+		s := s1.Intersection(newB(1, 2, 8), newB(1, 2, 9, 12))
+		if s.Len() != 2 || !s.ContainsAll(1, 2) {
+			t.Errorf("Set should be 1, 2, got %v", s.ToSlice())
+		}
+		if !sameType(s, s1) {
+			t.Errorf("Intersection should keep the implementation of the receiver, got %T", s)
+		}
+		if s := newA(1, 2).Intersection(newB()); !s.IsEmpty() {
+			t.Errorf("Intersection with an empty set should be empty")
+		}
+	})
+}
+
+func TestAlgebra_Difference(t *testing.T) {
+	forEachPair(t, func(t *testing.T, newA, newB func(...interface{}) Set) {
+		s := newA(1, 2, 4).Difference(newB(4, 8), newB(2))
+		if s.Len() != 1 || !s.Contains(1) {
+			t.Errorf("Set should be 1, got %v", s.ToSlice())
+		}
+	})
+}
+
+func TestAlgebra_SymmetricDifference(t *testing.T) {
+	forEachPair(t, func(t *testing.T, newA, newB func(...interface{}) Set) {
+		s1 := newA(1, 2, 4)
+		s := SymmetricDifference(s1, newB(2, 4, 8))
+		if s.Len() != 2 || !s.ContainsAll(1, 8) {
+			t.Errorf("Set should be 1, 8, got %v", s.ToSlice())
+		}
+		if !sameType(s, s1) {
+			t.Errorf("SymmetricDifference should keep the implementation of the receiver, got %T", s)
+		}
+	})
+}
+
+func TestAlgebra_Equal(t *testing.T) {
+	forEachPair(t, func(t *testing.T, newA, newB func(...interface{}) Set) {
+		if !Equal(newA(1, 2), newB(2, 1)) || !Equal(newA(), newB()) {
+			t.Error("Sets with the same elements should be equal")
+		}
+		if Equal(newA(1, 2), newB(1, 3)) || Equal(newA(1), newB(1, 2)) {
+			t.Error("Sets with different elements should not be equal")
+		}
+	})
+}
+
+func TestAlgebra_IsDisjoint(t *testing.T) {
+	forEachPair(t, func(t *testing.T, newA, newB func(...interface{}) Set) {
+		if !IsDisjoint(newA(1, 2), newB(3, 4, 5)) || !IsDisjoint(newA(), newB(1)) {
+			t.Error("Sets should be disjoint")
+		}
+		if IsDisjoint(newA(1, 2, 3), newB(3)) {
+			t.Error("Sets sharing 3 should not be disjoint")
+		}
+	})
+}
+
+func TestAlgebra_Subset(t *testing.T) {
+	forEachPair(t, func(t *testing.T, newA, newB func(...interface{}) Set) {
+		s1, s2 := newA(1, 2, 4), newB(2, 4)
+		if !s2.IsSubset(s1) || s1.IsSubset(s2) {
+			t.Error("Set s2 should be subset of s1 and not the other way round")
+		}
+		if !s1.IsProperSuperset(s2) || !s2.IsProperSubset(s1) {
+			t.Error("Set s1 should be proper superset of s2")
+		}
+	})
+}
+
+func TestAlgebra_PlainSet(t *testing.T) {
+	s, other := plainSet{NewHashSet(1, 2, 4)}, plainSet{NewHashSet(2, 4, 8)}
+	if d := SymmetricDifference(s, other); d.Len() != 2 || !d.ContainsAll(1, 8) {
+		t.Errorf("Set should be 1, 8, got %v", d.ToSlice())
+	}
+	if Equal(s, other) || !Equal(s, plainSet{NewHashSet(4, 2, 1)}) {
+		t.Error("Equal should compare the elements")
+	}
+	if IsDisjoint(s, other) || !IsDisjoint(s, plainSet{NewHashSet(3)}) {
+		t.Error("IsDisjoint should look for common elements")
+	}
+	if text, err := marshalText(s); err != nil || string(text) != "[1,2,4]" {
+		t.Errorf("Got %s, want [1,2,4]", text)
+	}
+}
@@ -0,0 +1,500 @@
+package set
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"math/bits"
+
+	"github.com/billryan/collections"
+	"github.com/billryan/collections/internal/codec"
+)
+
+type (
+	// BitSet is a Set of non-negative ints stored as one bit per possible
+	// element. It suits dense sets of small integers, where the word-level
+	// operations And, Or, AndNot and Xor are much faster than the generic set
+	// algebra. Adding anything but a non-negative int panics.
+	BitSet struct {
+		words []uint64
+	}
+)
+
+const (
+	wordSize = 64
+	// Largest element UnmarshalText accepts, so that a short text cannot
+	// make the set allocate without bound: 8 MiB of words.
+	maxTextElement = 1<<26 - 1
+)
+
+// Create a new bit set
+func NewBitSet(initial ...int) *BitSet {
+	s := &BitSet{}
+
+	for _, v := range initial {
+		s.Set(v)
+	}
+
+	return s
+}
+
+// Set bit i, adding i to the set. Panics if i is negative.
+func (s *BitSet) Set(i int) {
+	if i < 0 {
+		panic(fmt.Sprintf("set: BitSet holds non-negative ints, got %d", i))
+	}
+	w := i / wordSize
+	if w >= len(s.words) {
+		s.grow(w + 1)
+	}
+	s.words[w] |= 1 << (uint(i) % wordSize)
+}
+
+// Clear bit i, removing i from the set. Returns true if it was set.
+func (s *BitSet) Unset(i int) bool {
+	if !s.Test(i) {
+		return false
+	}
+	s.words[i/wordSize] &^= 1 << (uint(i) % wordSize)
+	return true
+}
+
+// Test whether bit i is set.
+func (s *BitSet) Test(i int) bool {
+	if i < 0 || i/wordSize >= len(s.words) {
+		return false
+	}
+	return s.words[i/wordSize]&(1<<(uint(i)%wordSize)) != 0
+}
+
+// Adds the specified element to this set if it is not already present (optional operation).
+// Panics unless e is a non-negative int.
+func (s *BitSet) Add(e interface{}) {
+	i, ok := e.(int)
+	if !ok {
+		panic(fmt.Sprintf("set: BitSet holds non-negative ints, got %T", e))
+	}
+	s.Set(i)
+}
+
+// Adds all of the elements to this set if they're not already present (optional operation).
+func (s *BitSet) AddAll(es ...interface{}) {
+	for _, e := range es {
+		s.Add(e)
+	}
+}
+
+// Removes all of the elements from this set (optional operation).
+func (s *BitSet) Clear() {
+	s.words = nil
+}
+
+// Returns true if this set contains the specified element.
+func (s *BitSet) Contains(e interface{}) bool {
+	i, ok := e.(int)
+	return ok && s.Test(i)
+}
+
+// Returns true if this set contains all of the elements of the specified collection.
+func (s *BitSet) ContainsAll(es ...interface{}) bool {
+	for _, e := range es {
+		if !s.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Call f for each item in the set, in ascending order
+func (s *BitSet) Foreach(f func(interface{})) {
+	for i, ok := s.NextSet(0); ok; i, ok = s.NextSet(i + 1) {
+		f(i)
+	}
+}
+
+// Call f for each item in the set in ascending order until it returns false
+func (s *BitSet) Do(f func(interface{}) bool) {
+	for i, ok := s.NextSet(0); ok; i, ok = s.NextSet(i + 1) {
+		if !f(i) {
+			return
+		}
+	}
+}
+
+// Returns a sequence over the elements in this set, in ascending order.
+func (s *BitSet) All() iter.Seq[interface{}] {
+	return s.Do
+}
+
+// Returns an iterator over a snapshot of the elements in this set, in ascending order.
+func (s *BitSet) Iterator() collections.Iterator {
+	return newIterator(s.ToSlice())
+}
+
+// Call f for each item in the set, set result as new key. The results need
+// not be ints, so they are collected into a HashSet.
+func (s *BitSet) Map(f func(interface{}) interface{}) Set {
+	n := NewHashSet()
+	s.Foreach(func(e interface{}) {
+		n.Add(f(e))
+	})
+	return n
+}
+
+// Returns true if this set contains no elements.
+func (s *BitSet) IsEmpty() bool {
+	for _, w := range s.words {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Removes the specified element from this set if it is present (optional operation).
+func (s *BitSet) Remove(e interface{}) bool {
+	i, ok := e.(int)
+	return ok && s.Unset(i)
+}
+
+// Removes the specified elements from this set if it is present (optional operation).
+// Return true if all element exist.
+func (s *BitSet) RemoveAll(es ...interface{}) bool {
+	existAll := true
+	for _, e := range es {
+		if !s.Remove(e) {
+			existAll = false
+		}
+	}
+	return existAll
+}
+
+// Return the number of elements in set s (cardinality of s).
+func (s *BitSet) Len() uint32 {
+	return uint32(s.PopCount())
+}
+
+// Returns an slice containing all of the elements in this set, in ascending order.
+func (s *BitSet) ToSlice() []interface{} {
+	slice := make([]interface{}, 0, s.PopCount())
+	s.Foreach(func(e interface{}) {
+		slice = append(slice, e)
+	})
+	return slice
+}
+
+// Returns the elements of this set as ints, in ascending order.
+func (s *BitSet) Ints() []int {
+	ints := make([]int, 0, s.PopCount())
+	for i, ok := s.NextSet(0); ok; i, ok = s.NextSet(i + 1) {
+		ints = append(ints, i)
+	}
+	return ints
+}
+
+// Returns a deep clone of set
+func (s *BitSet) Clone() Set {
+	return s.clone()
+}
+
+// Return a new set with elements common to the set and all others.
+// Other bit sets are combined word by word.
+func (s *BitSet) Intersection(others ...Set) Set {
+	if bitsets, ok := allBitSets(others); ok {
+		n := s.clone()
+		for _, o := range bitsets {
+			n.AndInPlace(o)
+		}
+		return n
+	}
+	return intersection(NewBitSet(), s, others)
+}
+
+// Return a new set with elements from the set and all others.
+// Other bit sets are combined word by word.
+func (s *BitSet) Union(others ...Set) Set {
+	if bitsets, ok := allBitSets(others); ok {
+		n := s.clone()
+		for _, o := range bitsets {
+			n.OrInPlace(o)
+		}
+		return n
+	}
+	return union(NewBitSet(), s, others)
+}
+
+// Return a new set with elements in the set that are not in the others.
+// Other bit sets are combined word by word.
+func (s *BitSet) Difference(others ...Set) Set {
+	if bitsets, ok := allBitSets(others); ok {
+		n := s.clone()
+		for _, o := range bitsets {
+			n.AndNotInPlace(o)
+		}
+		return n
+	}
+	return difference(NewBitSet(), s, others)
+}
+
+// Return a new set with elements in either the set or other but not both.
+func (s *BitSet) SymmetricDifference(other Set) Set {
+	if o, ok := other.(*BitSet); ok {
+		return s.Xor(o)
+	}
+	return symmetricDifference(NewBitSet(), s, other)
+}
+
+// Test whether the set and other contain the same elements.
+func (s *BitSet) Equal(other Set) bool {
+	if o, ok := other.(*BitSet); ok {
+		return s.Xor(o).IsEmpty()
+	}
+	return equal(s, other)
+}
+
+// Test whether the set has no elements in common with other.
+func (s *BitSet) IsDisjoint(other Set) bool {
+	if o, ok := other.(*BitSet); ok {
+		return s.And(o).IsEmpty()
+	}
+	return isDisjoint(s, other)
+}
+
+// Test whether every element in the set is in other. set <= other
+func (s *BitSet) IsSubset(other Set) bool {
+	if o, ok := other.(*BitSet); ok {
+		return s.AndNot(o).IsEmpty()
+	}
+	return isSubset(s, other)
+}
+
+// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+func (s *BitSet) IsProperSubset(other Set) bool {
+	return s.Len() < other.Len() && s.IsSubset(other)
+}
+
+// Test whether every element in other is in the set. set >= other
+func (s *BitSet) IsSuperset(other Set) bool {
+	return other.IsSubset(s)
+}
+
+// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+func (s *BitSet) IsProperSuperset(other Set) bool {
+	return s.Len() > other.Len() && s.IsSuperset(other)
+}
+
+// Replaces the elements of the set with those of a JSON array of
+// non-negative integers up to 1<<26 - 1. Larger elements, which would take
+// more than 8 MiB, can only be added with Set.
+func (s *BitSet) UnmarshalText(text []byte) error {
+	var v []int
+	if err := json.Unmarshal(text, &v); err != nil {
+		return err
+	}
+	for _, i := range v {
+		if i < 0 || i > maxTextElement {
+			return fmt.Errorf("set: BitSet text holds %d, outside [0, %d]", i, maxTextElement)
+		}
+	}
+	s.Clear()
+	for _, i := range v {
+		s.Set(i)
+	}
+	return nil
+}
+
+// Encode the set as a JSON array, in ascending order.
+func (s *BitSet) MarshalText() ([]byte, error) {
+	return json.Marshal(s.Ints())
+}
+
+// Encode the set as a JSON array, as MarshalText does.
+func (s *BitSet) MarshalJSON() ([]byte, error) {
+	return s.MarshalText()
+}
+
+// Replaces the elements of the set with those of a JSON array of
+// non-negative integers.
+func (s *BitSet) UnmarshalJSON(data []byte) error {
+	return s.UnmarshalText(data)
+}
+
+// Encode the set as a version byte and the number of words, a varint,
+// followed by the words in little-endian order. Trailing zero words are
+// left out.
+func (s *BitSet) MarshalBinary() ([]byte, error) {
+	words := s.words
+	for len(words) > 0 && words[len(words)-1] == 0 {
+		words = words[:len(words)-1]
+	}
+	b := make([]byte, 0, 1+binary.MaxVarintLen64+8*len(words))
+	b = binary.AppendUvarint(append(b, binaryVersion), uint64(len(words)))
+	for _, w := range words {
+		b = binary.LittleEndian.AppendUint64(b, w)
+	}
+	return b, nil
+}
+
+// Replaces the elements of the set with those encoded by MarshalBinary.
+func (s *BitSet) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errBinaryTruncated
+	}
+	if data[0] != binaryVersion {
+		return fmt.Errorf("set: unknown binary version %d", data[0])
+	}
+	r := codec.NewReader(data[1:])
+	words := make([]uint64, r.Count(8))
+	for i := range words {
+		words[i] = binary.LittleEndian.Uint64(r.Next(8))
+	}
+	if err := r.Close(); err != nil {
+		return fmt.Errorf("set: %w", err)
+	}
+	s.words = words
+	return nil
+}
+
+// Returns the number of set bits, that is the cardinality of the set.
+func (s *BitSet) PopCount() int {
+	n := 0
+	for _, w := range s.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// Returns the first set bit at or after i, and false if there is none.
+func (s *BitSet) NextSet(i int) (int, bool) {
+	if i < 0 {
+		i = 0
+	}
+	w := i / wordSize
+	if w >= len(s.words) {
+		return 0, false
+	}
+	word := s.words[w] >> (uint(i) % wordSize)
+	if word != 0 {
+		return i + bits.TrailingZeros64(word), true
+	}
+	for w++; w < len(s.words); w++ {
+		if s.words[w] != 0 {
+			return w*wordSize + bits.TrailingZeros64(s.words[w]), true
+		}
+	}
+	return 0, false
+}
+
+// Returns the first clear bit at or after i.
+func (s *BitSet) NextClear(i int) int {
+	if i < 0 {
+		i = 0
+	}
+	w := i / wordSize
+	if w >= len(s.words) {
+		return i
+	}
+	word := ^s.words[w] >> (uint(i) % wordSize)
+	if word != 0 {
+		return i + bits.TrailingZeros64(word)
+	}
+	for w++; w < len(s.words); w++ {
+		if ^s.words[w] != 0 {
+			return w*wordSize + bits.TrailingZeros64(^s.words[w])
+		}
+	}
+	return len(s.words) * wordSize
+}
+
+// Returns a new bit set with the bits set in both s and other.
+func (s *BitSet) And(other *BitSet) *BitSet {
+	return s.clone().AndInPlace(other)
+}
+
+// Returns a new bit set with the bits set in either s or other.
+func (s *BitSet) Or(other *BitSet) *BitSet {
+	return s.clone().OrInPlace(other)
+}
+
+// Returns a new bit set with the bits set in s but not in other.
+func (s *BitSet) AndNot(other *BitSet) *BitSet {
+	return s.clone().AndNotInPlace(other)
+}
+
+// Returns a new bit set with the bits set in exactly one of s and other.
+func (s *BitSet) Xor(other *BitSet) *BitSet {
+	return s.clone().XorInPlace(other)
+}
+
+// Keeps only the bits also set in other. Returns s.
+func (s *BitSet) AndInPlace(other *BitSet) *BitSet {
+	if len(other.words) < len(s.words) {
+		s.words = s.words[:len(other.words)]
+	}
+	for i := range s.words {
+		s.words[i] &= other.words[i]
+	}
+	return s
+}
+
+// Sets the bits set in other. Returns s.
+func (s *BitSet) OrInPlace(other *BitSet) *BitSet {
+	s.grow(len(other.words))
+	for i, w := range other.words {
+		s.words[i] |= w
+	}
+	return s
+}
+
+// Clears the bits set in other. Returns s.
+func (s *BitSet) AndNotInPlace(other *BitSet) *BitSet {
+	for i := 0; i < len(s.words) && i < len(other.words); i++ {
+		s.words[i] &^= other.words[i]
+	}
+	return s
+}
+
+// Flips the bits set in other. Returns s.
+func (s *BitSet) XorInPlace(other *BitSet) *BitSet {
+	s.grow(len(other.words))
+	for i, w := range other.words {
+		s.words[i] ^= w
+	}
+	return s
+}
+
+func (s *BitSet) clone() *BitSet {
+	words := make([]uint64, len(s.words))
+	copy(words, s.words)
+	return &BitSet{words}
+}
+
+// Make room for at least n words
+func (s *BitSet) grow(n int) {
+	if n <= len(s.words) {
+		return
+	}
+	if n <= cap(s.words) {
+		// Words past the length may hold bits left by a shrink
+		clear(s.words[len(s.words):n])
+		s.words = s.words[:n]
+		return
+	}
+	words := make([]uint64, n, max(n, 2*cap(s.words)))
+	copy(words, s.words)
+	s.words = words
+}
+
+// Get the sets as bit sets if they all are
+func allBitSets(sets []Set) ([]*BitSet, bool) {
+	bitsets := make([]*BitSet, len(sets))
+	for i, set := range sets {
+		b, ok := set.(*BitSet)
+		if !ok {
+			return nil, false
+		}
+		bitsets[i] = b
+	}
+	return bitsets, true
+}
@@ -0,0 +1,142 @@
+package set
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newIntBitSet(initial ...interface{}) Set {
+	s := NewBitSet()
+	s.AddAll(initial...)
+	return s
+}
+
+func TestBitSet_Basics(t *testing.T) {
+	s := NewBitSet(3, 0, 64, 200, 3)
+	if s.Len() != 4 {
+		t.Errorf("Length should be 4, got %d", s.Len())
+	}
+	if !s.ContainsAll(0, 3, 64, 200) || s.Contains(1) || s.Contains(-1) || s.Contains("3") {
+		t.Errorf("Set should hold exactly 0, 3, 64, 200, got %v", s.Ints())
+	}
+	if !reflect.DeepEqual(s.ToSlice(), []interface{}{0, 3, 64, 200}) {
+		t.Errorf("Elements should be ascending, got %v", s.ToSlice())
+	}
+	if !s.Remove(64) || s.Remove(64) || s.Remove("x") || s.Len() != 3 {
+		t.Errorf("Remove should report whether the element was present")
+	}
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Errorf("Cleared set should be empty")
+	}
+}
+
+func TestBitSet_AddPanics(t *testing.T) {
+	for _, e := range []interface{}{-1, "1", 1.0} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Adding %#v should panic", e)
+				}
+			}()
+			NewBitSet().Add(e)
+		}()
+	}
+}
+
+func TestBitSet_Next(t *testing.T) {
+	s := NewBitSet(1, 2, 3, 70, 130)
+	var set []int
+	for i, ok := s.NextSet(0); ok; i, ok = s.NextSet(i + 1) {
+		set = append(set, i)
+	}
+	if !reflect.DeepEqual(set, []int{1, 2, 3, 70, 130}) {
+		t.Errorf("NextSet should walk 1, 2, 3, 70, 130, got %v", set)
+	}
+	if _, ok := s.NextSet(131); ok {
+		t.Errorf("NextSet past the last bit should fail")
+	}
+	for _, c := range [][2]int{{0, 0}, {1, 4}, {70, 71}, {130, 131}, {500, 500}} {
+		if got := s.NextClear(c[0]); got != c[1] {
+			t.Errorf("NextClear(%d) should be %d, got %d", c[0], c[1], got)
+		}
+	}
+	full := NewBitSet()
+	for i := 0; i < 128; i++ {
+		full.Set(i)
+	}
+	if got := full.NextClear(5); got != 128 {
+		t.Errorf("NextClear of a full set should be 128, got %d", got)
+	}
+}
+
+func TestBitSet_Words(t *testing.T) {
+	a := NewBitSet(1, 2, 100, 300)
+	b := NewBitSet(2, 3, 300)
+	cases := []struct {
+		name string
+		got  *BitSet
+		want []int
+	}{
+		{"And", a.And(b), []int{2, 300}},
+		{"Or", a.Or(b), []int{1, 2, 3, 100, 300}},
+		{"AndNot", a.AndNot(b), []int{1, 100}},
+		{"Xor", a.Xor(b), []int{1, 3, 100}},
+		{"AndNot short", b.AndNot(NewBitSet(2)), []int{3, 300}},
+		{"And short", a.And(NewBitSet(1)), []int{1}},
+	}
+	for _, c := range cases {
+		if !reflect.DeepEqual(c.got.Ints(), c.want) {
+			t.Errorf("%s should be %v, got %v", c.name, c.want, c.got.Ints())
+		}
+	}
+	if !reflect.DeepEqual(a.Ints(), []int{1, 2, 100, 300}) {
+		t.Errorf("And, Or, AndNot and Xor should leave the receiver alone, got %v", a.Ints())
+	}
+	if a.PopCount() != 4 {
+		t.Errorf("PopCount should be 4, got %d", a.PopCount())
+	}
+
+	if c := a.Clone().(*BitSet).OrInPlace(b); c.PopCount() != 5 {
+		t.Errorf("OrInPlace should give 5 bits, got %v", c.Ints())
+	}
+	c := NewBitSet(1, 2, 100, 300)
+	if c.AndInPlace(b) != c || !reflect.DeepEqual(c.Ints(), []int{2, 300}) {
+		t.Errorf("AndInPlace should change and return the receiver, got %v", c.Ints())
+	}
+	c.XorInPlace(NewBitSet(2, 5)).AndNotInPlace(NewBitSet(300))
+	if !reflect.DeepEqual(c.Ints(), []int{5}) {
+		t.Errorf("XorInPlace then AndNotInPlace should give 5, got %v", c.Ints())
+	}
+	c.Set(1000)
+	if !c.AndInPlace(NewBitSet(1000)).Test(1000) {
+		t.Errorf("AndInPlace should keep 1000")
+	}
+
+	d := NewBitSet(1, 100)
+	d.AndInPlace(NewBitSet(1)).Set(70)
+	if !reflect.DeepEqual(d.Ints(), []int{1, 70}) {
+		t.Errorf("Bits removed by AndInPlace should stay removed, got %v", d.Ints())
+	}
+}
+
+func TestBitSet_UnmarshalText(t *testing.T) {
+	s := NewBitSet(7)
+	if err := s.UnmarshalText([]byte("[3, 1, 65]")); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s.Ints(), []int{1, 3, 65}) {
+		t.Errorf("Set should be 1, 3, 65, got %v", s.Ints())
+	}
+	for _, text := range []string{"[-1]", "[18446744073709551615]", "[1000000000000]", "[67108864]"} {
+		if err := s.UnmarshalText([]byte(text)); err == nil {
+			t.Errorf("%s should fail to decode", text)
+		}
+	}
+	if !reflect.DeepEqual(s.Ints(), []int{1, 3, 65}) {
+		t.Errorf("Failed decoding should leave the set alone, got %v", s.Ints())
+	}
+	if err := s.UnmarshalText([]byte("[67108863]")); err != nil || !s.Test(1<<26-1) {
+		t.Errorf("1<<26 - 1 should decode, got %v", err)
+	}
+}
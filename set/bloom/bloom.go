@@ -0,0 +1,261 @@
+// Package bloom provides Bloom filters: compact, probabilistic sets that can
+// tell for sure that an element was never added, but may wrongly report an
+// element as present with a small, configurable probability. Elements cannot
+// be removed.
+//
+// Filter has a fixed size chosen from the expected number of elements and
+// the target false positive rate. ScalableFilter grows as elements are
+// added, keeping the false positive rate under its target however many
+// elements it ends up holding.
+//
+// Elements are hashed with a stable hash, so filters can be serialized with
+// MarshalBinary and read back by another process.
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/billryan/collections/internal/hashing"
+)
+
+type (
+	// Filter is a standard Bloom filter of m bits probed by k hash functions.
+	Filter struct {
+		words []uint64
+		m     uint64
+		k     uint32
+		// Number of adds that set at least one bit, that is, of elements
+		// that were not already reported as present. Union sums the counts
+		// of the filters, so elements in several of them are counted more
+		// than once and the count is only an upper bound.
+		added uint64
+	}
+)
+
+// Version of the serialized form
+const version = 1
+
+var (
+	// ErrIncompatible is returned when combining filters of different
+	// shapes.
+	ErrIncompatible = errors.New("bloom: filters differ in size or number of hash functions")
+
+	errTruncated = errors.New("bloom: truncated data")
+)
+
+// Create a filter sized to hold n elements with a false positive rate of p.
+// Panics unless 0 < p < 1.
+func New(n uint, p float64) *Filter {
+	m, k := EstimateParameters(n, p)
+	return NewWithSize(m, k)
+}
+
+// Create a filter of m bits probed by k hash functions. Both are raised to
+// at least 1.
+func NewWithSize(m uint64, k uint) *Filter {
+	m = max(m, 1)
+	k = max(k, 1)
+	return &Filter{words: make([]uint64, (m+63)/64), m: m, k: uint32(k)}
+}
+
+// Get the number of bits and of hash functions a filter needs to hold n
+// elements with a false positive rate of p.
+func EstimateParameters(n uint, p float64) (m uint64, k uint) {
+	if p <= 0 || p >= 1 {
+		panic(fmt.Sprintf("bloom: false positive rate %v out of (0, 1)", p))
+	}
+	n = max(n, 1)
+	m = uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k = uint(math.Round(float64(m) / float64(n) * math.Ln2))
+	return max(m, 1), max(k, 1)
+}
+
+// Adds the specified element to this filter.
+func (f *Filter) Add(e interface{}) {
+	f.add(hashing.Sum64(e))
+}
+
+// Adds all of the elements to this filter.
+func (f *Filter) AddAll(es ...interface{}) {
+	for _, e := range es {
+		f.Add(e)
+	}
+}
+
+// Returns true if the element may have been added to this filter, and
+// false if it certainly was not.
+func (f *Filter) Contains(e interface{}) bool {
+	return f.contains(hashing.Sum64(e))
+}
+
+// Returns true if all of the elements may have been added to this filter.
+func (f *Filter) ContainsAll(es ...interface{}) bool {
+	for _, e := range es {
+		if !f.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Removes all of the elements from this filter.
+func (f *Filter) Clear() {
+	clear(f.words)
+	f.added = 0
+}
+
+// Returns true if nothing was added to this filter.
+func (f *Filter) IsEmpty() bool {
+	return f.added == 0
+}
+
+// Estimate the number of distinct elements added to this filter from the
+// share of bits set. Once every bit is set the share tells nothing, and the
+// number of adds is returned instead. After a Union that number counts the
+// elements common to the filters once per filter, so it is an upper bound.
+func (f *Filter) EstimatedLen() uint32 {
+	set := float64(f.popCount())
+	m := float64(f.m)
+	if set >= m {
+		return uint32(min(f.added, math.MaxUint32))
+	}
+	return uint32(math.Round(-m / float64(f.k) * math.Log(1-set/m)))
+}
+
+// Estimate the probability that Contains wrongly reports an element as
+// present, given the bits set so far.
+func (f *Filter) FalsePositiveRate() float64 {
+	return math.Pow(float64(f.popCount())/float64(f.m), float64(f.k))
+}
+
+// Get the number of bits of the filter.
+func (f *Filter) Bits() uint64 {
+	return f.m
+}
+
+// Get the number of hash functions of the filter.
+func (f *Filter) Hashes() uint {
+	return uint(f.k)
+}
+
+// Return a new filter holding the elements of the filter and all others.
+// The filters must have the same number of bits and hash functions. The
+// result cannot tell the elements the filters share, so the count of adds it
+// keeps for EstimatedLen overcounts them.
+func (f *Filter) Union(others ...*Filter) (*Filter, error) {
+	n := f.Clone()
+	for _, o := range others {
+		if o.m != f.m || o.k != f.k {
+			return nil, ErrIncompatible
+		}
+		for i, w := range o.words {
+			n.words[i] |= w
+		}
+		n.added += o.added
+	}
+	return n, nil
+}
+
+// Returns a deep clone of the filter.
+func (f *Filter) Clone() *Filter {
+	n := *f
+	n.words = make([]uint64, len(f.words))
+	copy(n.words, f.words)
+	return &n
+}
+
+// Encode the filter. All numbers are little-endian.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	le := binary.LittleEndian
+	b := make([]byte, 0, 21+8*len(f.words))
+	b = append(b, version)
+	b = le.AppendUint64(b, f.m)
+	b = le.AppendUint32(b, f.k)
+	b = le.AppendUint64(b, f.added)
+	for _, w := range f.words {
+		b = le.AppendUint64(b, w)
+	}
+	return b, nil
+}
+
+// Replace the filter with one decoded from the form written by
+// MarshalBinary.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	le := binary.LittleEndian
+	if len(data) < 21 {
+		return errTruncated
+	}
+	if data[0] != version {
+		return fmt.Errorf("bloom: unknown version %d", data[0])
+	}
+	m, k, added := le.Uint64(data[1:]), le.Uint32(data[9:]), le.Uint64(data[13:])
+	data = data[21:]
+	if m == 0 || k == 0 {
+		return errors.New("bloom: filter without bits or hash functions")
+	}
+	// Round up without overflowing for m near the largest uint64
+	n := m / 64
+	if m%64 != 0 {
+		n++
+	}
+	if uint64(len(data)) != n*8 {
+		return fmt.Errorf("bloom: %d bytes of bits for a filter of %d bits", len(data), m)
+	}
+	words := make([]uint64, n)
+	for i := range words {
+		words[i] = le.Uint64(data[8*i:])
+	}
+	f.words, f.m, f.k, f.added = words, m, k, added
+	return nil
+}
+
+// Set the bits for hash h, counting the element if any was clear
+func (f *Filter) add(h uint64) {
+	fresh := false
+	f.probe(h, func(word int, bit uint64) bool {
+		if f.words[word]&bit == 0 {
+			f.words[word] |= bit
+			fresh = true
+		}
+		return true
+	})
+	if fresh {
+		f.added++
+	}
+}
+
+// Test whether all bits for hash h are set
+func (f *Filter) contains(h uint64) bool {
+	found := true
+	f.probe(h, func(word int, bit uint64) bool {
+		found = f.words[word]&bit != 0
+		return found
+	})
+	return found
+}
+
+// Call visit with the position of each of the k bits for hash h until it
+// returns false. The positions come from double hashing, deriving all k
+// from two hashes.
+func (f *Filter) probe(h uint64, visit func(word int, bit uint64) bool) {
+	h2 := hashing.Mix(h) | 1
+	for i := uint32(0); i < f.k; i++ {
+		pos := h % f.m
+		if !visit(int(pos/64), 1<<(pos%64)) {
+			return
+		}
+		h += h2
+	}
+}
+
+func (f *Filter) popCount() int {
+	n := 0
+	for _, w := range f.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
@@ -0,0 +1,137 @@
+package bloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Count the elements of a range never added that the filter reports as
+// present.
+func falsePositives(contains func(interface{}) bool, from, to int) int {
+	n := 0
+	for i := from; i < to; i++ {
+		if contains(fmt.Sprint("absent-", i)) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestEstimateParameters(t *testing.T) {
+	m, k := EstimateParameters(1000, 0.01)
+	if m != 9586 || k != 7 {
+		t.Errorf("1000 elements at 1%% should take 9586 bits and 7 hashes, got %d and %d", m, k)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("A false positive rate of 1 should panic")
+		}
+	}()
+	New(10, 1)
+}
+
+func TestFilter(t *testing.T) {
+	f := New(10000, 0.01)
+	if !f.IsEmpty() || f.Contains("a") {
+		t.Errorf("New filter should be empty")
+	}
+	for i := 0; i < 10000; i++ {
+		f.Add(i)
+	}
+	f.AddAll("a", []byte("b"))
+	for i := 0; i < 10000; i++ {
+		if !f.Contains(i) {
+			t.Fatalf("Filter should contain %d", i)
+		}
+	}
+	if !f.ContainsAll("a", "b") {
+		t.Errorf("Filter should contain a and b")
+	}
+	if fp := falsePositives(f.Contains, 0, 100000); fp > 1500 {
+		t.Errorf("False positive rate should be about 1%%, got %d in 100000", fp)
+	}
+	if r := f.FalsePositiveRate(); r < 0.005 || r > 0.02 {
+		t.Errorf("Estimated false positive rate should be about 1%%, got %v", r)
+	}
+	if n := f.EstimatedLen(); n < 9700 || n > 10300 {
+		t.Errorf("Estimated length should be about 10002, got %d", n)
+	}
+	f.Clear()
+	if !f.IsEmpty() || f.Contains(1) {
+		t.Errorf("Cleared filter should be empty")
+	}
+}
+
+func TestFilter_Union(t *testing.T) {
+	a, b := New(100, 0.01), New(100, 0.01)
+	a.AddAll(1, 2)
+	b.AddAll(3, 4)
+	u, err := a.Union(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !u.ContainsAll(1, 2, 3, 4) || a.Contains(3) {
+		t.Errorf("Union should hold 1, 2, 3, 4 and leave the filter alone")
+	}
+	if n := u.EstimatedLen(); n != 4 {
+		t.Errorf("Union should hold about 4 elements, got %d", n)
+	}
+	// A saturated filter falls back on the count of adds, which overcounts
+	// the elements common to the filters
+	c, d := NewWithSize(64, 1), NewWithSize(64, 1)
+	for i := 0; i < 1000; i++ {
+		c.Add(i)
+		d.Add(i)
+	}
+	if u, _ := c.Union(d); u.EstimatedLen() < c.EstimatedLen() {
+		t.Errorf("Union of saturated filters should estimate at least %d elements, got %d", c.EstimatedLen(), u.EstimatedLen())
+	}
+	if _, err := a.Union(New(1000, 0.01)); err != ErrIncompatible {
+		t.Errorf("Union of filters of different sizes should fail, got %v", err)
+	}
+}
+
+func TestFilter_MarshalBinary(t *testing.T) {
+	f := New(500, 0.02)
+	for i := 0; i < 300; i++ {
+		f.Add(fmt.Sprint("item-", i))
+	}
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Filter{}
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if g.Bits() != f.Bits() || g.Hashes() != f.Hashes() || g.EstimatedLen() != f.EstimatedLen() {
+		t.Errorf("Decoded filter should have the same shape")
+	}
+	for i := 0; i < 300; i++ {
+		if !g.Contains(fmt.Sprint("item-", i)) {
+			t.Fatalf("Decoded filter should contain item-%d", i)
+		}
+	}
+	if again, _ := g.MarshalBinary(); !bytes.Equal(again, data) {
+		t.Errorf("Encoding should be stable")
+	}
+	for _, bad := range [][]byte{nil, data[:len(data)-1], append([]byte{9}, data[1:]...)} {
+		if err := g.UnmarshalBinary(bad); err == nil {
+			t.Errorf("Decoding %d bad bytes should fail", len(bad))
+		}
+	}
+	// A header whose bit count overflows when rounded up to whole words
+	header := []byte{version}
+	header = binary.LittleEndian.AppendUint64(header, math.MaxUint64)
+	header = binary.LittleEndian.AppendUint32(header, 3)
+	header = binary.LittleEndian.AppendUint64(header, 0)
+	if err := g.UnmarshalBinary(header); err == nil {
+		t.Errorf("Decoding a filter of %d bits without words should fail", uint64(math.MaxUint64))
+	}
+	if g.Bits() != f.Bits() || !g.Contains("item-0") {
+		t.Errorf("Failed decoding should leave the filter unchanged")
+	}
+}
@@ -0,0 +1,217 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/billryan/collections/internal/hashing"
+)
+
+type (
+	// ScalableFilter is a Bloom filter that grows as elements are added. It
+	// is a series of filters, each twice as large as the previous one and
+	// with a tighter false positive rate, so that the rate of the whole
+	// stays under its target. Only the last filter takes new elements; a new
+	// one is started when it holds as many as it was sized for.
+	ScalableFilter struct {
+		filters []*Filter
+		n       uint
+		p       float64
+	}
+)
+
+const (
+	// Each filter holds growth times as many elements as the previous one
+	growth = 2
+	// and has a false positive rate tightening times as high.
+	tightening = 0.85
+)
+
+// Create a scalable filter whose first stage holds n elements, keeping the
+// false positive rate under p. Panics unless 0 < p < 1.
+func NewScalable(n uint, p float64) *ScalableFilter {
+	if p <= 0 || p >= 1 {
+		panic(fmt.Sprintf("bloom: false positive rate %v out of (0, 1)", p))
+	}
+	return &ScalableFilter{n: max(n, 1), p: p}
+}
+
+// Adds the specified element to this filter.
+func (f *ScalableFilter) Add(e interface{}) {
+	f.add(e)
+}
+
+// Adds all of the elements to this filter.
+func (f *ScalableFilter) AddAll(es ...interface{}) {
+	for _, e := range es {
+		f.add(e)
+	}
+}
+
+// Returns true if the element may have been added to this filter, and
+// false if it certainly was not.
+func (f *ScalableFilter) Contains(e interface{}) bool {
+	return f.contains(hashing.Sum64(e))
+}
+
+// Returns true if all of the elements may have been added to this filter.
+func (f *ScalableFilter) ContainsAll(es ...interface{}) bool {
+	for _, e := range es {
+		if !f.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Removes all of the elements from this filter, shrinking it back to its
+// first stage.
+func (f *ScalableFilter) Clear() {
+	f.filters = nil
+}
+
+// Returns true if nothing was added to this filter.
+func (f *ScalableFilter) IsEmpty() bool {
+	return len(f.filters) == 0
+}
+
+// Estimate the number of distinct elements added to this filter.
+func (f *ScalableFilter) EstimatedLen() uint32 {
+	var n uint64
+	for _, s := range f.filters {
+		n += uint64(s.EstimatedLen())
+	}
+	return uint32(min(n, math.MaxUint32))
+}
+
+// Estimate the probability that Contains wrongly reports an element as
+// present, given the bits set so far.
+func (f *ScalableFilter) FalsePositiveRate() float64 {
+	none := 1.0
+	for _, s := range f.filters {
+		none *= 1 - s.FalsePositiveRate()
+	}
+	return 1 - none
+}
+
+// Return a new filter holding the elements of the filter and all others.
+// The filters must have been created with the same parameters.
+func (f *ScalableFilter) Union(others ...*ScalableFilter) (*ScalableFilter, error) {
+	n := f.Clone()
+	for _, o := range others {
+		if o.n != f.n || o.p != f.p {
+			return nil, ErrIncompatible
+		}
+		for i, s := range o.filters {
+			if i == len(n.filters) {
+				n.filters = append(n.filters, s.Clone())
+				continue
+			}
+			u, err := n.filters[i].Union(s)
+			if err != nil {
+				return nil, err
+			}
+			n.filters[i] = u
+		}
+	}
+	return n, nil
+}
+
+// Returns a deep clone of the filter.
+func (f *ScalableFilter) Clone() *ScalableFilter {
+	n := &ScalableFilter{filters: make([]*Filter, len(f.filters)), n: f.n, p: f.p}
+	for i, s := range f.filters {
+		n.filters[i] = s.Clone()
+	}
+	return n
+}
+
+// Encode the filter, its parameters followed by each of its stages. All
+// numbers are little-endian.
+func (f *ScalableFilter) MarshalBinary() ([]byte, error) {
+	le := binary.LittleEndian
+	b := []byte{version}
+	b = le.AppendUint64(b, uint64(f.n))
+	b = le.AppendUint64(b, math.Float64bits(f.p))
+	b = le.AppendUint32(b, uint32(len(f.filters)))
+	for _, s := range f.filters {
+		data, err := s.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		b = le.AppendUint64(b, uint64(len(data)))
+		b = append(b, data...)
+	}
+	return b, nil
+}
+
+// Replace the filter with one decoded from the form written by
+// MarshalBinary.
+func (f *ScalableFilter) UnmarshalBinary(data []byte) error {
+	le := binary.LittleEndian
+	if len(data) < 21 {
+		return errTruncated
+	}
+	if data[0] != version {
+		return fmt.Errorf("bloom: unknown version %d", data[0])
+	}
+	n, p, stages := le.Uint64(data[1:]), math.Float64frombits(le.Uint64(data[9:])), le.Uint32(data[17:])
+	data = data[21:]
+	if n == 0 || !(p > 0 && p < 1) {
+		return errors.New("bloom: invalid scalable filter parameters")
+	}
+	var filters []*Filter
+	for i := uint32(0); i < stages; i++ {
+		if len(data) < 8 || uint64(len(data)-8) < le.Uint64(data) {
+			return errTruncated
+		}
+		size := le.Uint64(data)
+		s := &Filter{}
+		if err := s.UnmarshalBinary(data[8 : 8+size]); err != nil {
+			return err
+		}
+		filters = append(filters, s)
+		data = data[8+size:]
+	}
+	f.filters, f.n, f.p = filters, uint(n), p
+	return nil
+}
+
+// Add the element to the last stage unless it may already be present,
+// starting a new stage when the last one is full
+func (f *ScalableFilter) add(e interface{}) {
+	h := hashing.Sum64(e)
+	if f.contains(h) {
+		return
+	}
+	last := len(f.filters) - 1
+	if last < 0 || f.filters[last].added >= f.capacity(last) {
+		f.filters = append(f.filters, f.stage(last+1))
+		last++
+	}
+	f.filters[last].add(h)
+}
+
+// Test whether any stage may hold hash h
+func (f *ScalableFilter) contains(h uint64) bool {
+	for _, s := range f.filters {
+		if s.contains(h) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the number of elements stage i is sized for
+func (f *ScalableFilter) capacity(i int) uint64 {
+	return uint64(float64(f.n) * math.Pow(growth, float64(i)))
+}
+
+// Create stage i. The false positive rates of the stages form a geometric
+// series adding up to p.
+func (f *ScalableFilter) stage(i int) *Filter {
+	p := f.p * (1 - tightening) * math.Pow(tightening, float64(i))
+	return New(uint(f.capacity(i)), p)
+}
@@ -0,0 +1,99 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestScalableFilter(t *testing.T) {
+	f := NewScalable(1000, 0.01)
+	if !f.IsEmpty() || f.Contains(1) {
+		t.Errorf("New filter should be empty")
+	}
+	for i := 0; i < 50000; i++ {
+		f.Add(i)
+		f.Add(i)
+	}
+	for i := 0; i < 50000; i++ {
+		if !f.Contains(i) {
+			t.Fatalf("Filter should contain %d", i)
+		}
+	}
+	if len(f.filters) < 5 {
+		t.Errorf("Filter should have grown to several stages, got %d", len(f.filters))
+	}
+	if fp := falsePositives(f.Contains, 0, 100000); fp > 1000 {
+		t.Errorf("False positive rate should stay under 1%%, got %d in 100000", fp)
+	}
+	if r := f.FalsePositiveRate(); r > 0.01 {
+		t.Errorf("Estimated false positive rate should stay under 1%%, got %v", r)
+	}
+	if n := f.EstimatedLen(); n < 48000 || n > 52000 {
+		t.Errorf("Estimated length should be about 50000, got %d", n)
+	}
+	f.Clear()
+	if !f.IsEmpty() || f.Contains(1) {
+		t.Errorf("Cleared filter should be empty")
+	}
+}
+
+func TestScalableFilter_Union(t *testing.T) {
+	a, b := NewScalable(10, 0.01), NewScalable(10, 0.01)
+	for i := 0; i < 100; i++ {
+		a.Add(i)
+	}
+	b.AddAll("x", "y")
+	u, err := a.Union(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !u.ContainsAll(0, 99, "x", "y") || a.Contains("x") {
+		t.Errorf("Union should hold both filters and leave the filter alone")
+	}
+	if u, err = b.Union(a); err != nil || !u.ContainsAll(0, 99, "x", "y") {
+		t.Errorf("Union with a longer filter should take its extra stages")
+	}
+	if _, err := a.Union(NewScalable(10, 0.1)); err != ErrIncompatible {
+		t.Errorf("Union of filters with different parameters should fail, got %v", err)
+	}
+}
+
+func TestScalableFilter_MarshalBinary(t *testing.T) {
+	f := NewScalable(100, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add(i)
+	}
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewScalable(1, 0.5)
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.filters) != len(f.filters) || g.n != 100 || g.p != 0.01 {
+		t.Errorf("Decoded filter should have the same parameters and stages")
+	}
+	for i := 0; i < 1000; i++ {
+		if !g.Contains(i) {
+			t.Fatalf("Decoded filter should contain %d", i)
+		}
+	}
+	if err := g.UnmarshalBinary(data[:len(data)-3]); err == nil {
+		t.Errorf("Decoding truncated data should fail")
+	}
+	stage := []byte{version}
+	stage = binary.LittleEndian.AppendUint64(stage, math.MaxUint64)
+	stage = binary.LittleEndian.AppendUint32(stage, 3)
+	stage = binary.LittleEndian.AppendUint64(stage, 0)
+	bad := []byte{version}
+	bad = binary.LittleEndian.AppendUint64(bad, 100)
+	bad = binary.LittleEndian.AppendUint64(bad, math.Float64bits(0.01))
+	bad = binary.LittleEndian.AppendUint32(bad, 1)
+	bad = binary.LittleEndian.AppendUint64(bad, uint64(len(stage)))
+	bad = append(bad, stage...)
+	if err := g.UnmarshalBinary(bad); err == nil {
+		t.Errorf("Decoding a stage of %d bits without words should fail", uint64(math.MaxUint64))
+	}
+}
@@ -2,21 +2,27 @@ package set
 
 import (
 	"encoding/json"
+	"iter"
+	"math"
 	"sync"
 	"sync/atomic"
+
+	"github.com/billryan/collections"
 )
 
 type (
 	ConcurrentSet struct {
 		hash sync.Map
-		size uint32
+		// Signed, as a Remove may count down before the Add of the same
+		// element counts up
+		size int64
 	}
 )
 
 func (s *ConcurrentSet) Add(e interface{}) {
 	_, exists := s.hash.LoadOrStore(e, nothing{})
 	if !exists {
-		atomic.AddUint32(&s.size, 1)
+		atomic.AddInt64(&s.size, 1)
 	}
 }
 
@@ -24,17 +30,16 @@ func (s *ConcurrentSet) AddAll(es ...interface{}) {
 	for _, e := range es {
 		_, exists := s.hash.LoadOrStore(e, nothing{})
 		if !exists {
-			atomic.AddUint32(&s.size, 1)
+			atomic.AddInt64(&s.size, 1)
 		}
 	}
 }
 
 func (s *ConcurrentSet) Clear() {
 	s.hash.Range(func(k, v interface{}) bool {
-		s.hash.Delete(k)
+		s.Remove(k)
 		return true
 	})
-	atomic.StoreUint32(&s.size, 0)
 }
 
 func (s *ConcurrentSet) Contains(e interface{}) bool {
@@ -59,30 +64,43 @@ func (s *ConcurrentSet) Foreach(f func(interface{})) {
 	})
 }
 
+// Call f for each item in the set until it returns false
+func (s *ConcurrentSet) Do(f func(interface{}) bool) {
+	s.hash.Range(func(k, v interface{}) bool {
+		return f(k)
+	})
+}
+
+// Returns a sequence over the elements in this set.
+func (s *ConcurrentSet) All() iter.Seq[interface{}] {
+	return s.Do
+}
+
+// Returns an iterator over a snapshot of the elements in this set.
+func (s *ConcurrentSet) Iterator() collections.Iterator {
+	return newIterator(s.ToSlice())
+}
+
 func (s *ConcurrentSet) Map(f func(interface{}) interface{}) Set {
-	sizeMap := make(map[interface{}]nothing)
-	var n sync.Map
+	n := &ConcurrentSet{}
 	s.hash.Range(func(k, v interface{}) bool {
-		newK := f(k)
-		n.Store(newK, nothing{})
-		sizeMap[newK] = nothing{}
+		n.Add(f(k))
 		return true
 	})
 
-	return &ConcurrentSet{n, uint32(len(sizeMap))}
+	return n
 }
 
 // Returns true if this set contains no elements.
 func (s *ConcurrentSet) IsEmpty() bool {
-	return s.size == 0
+	return s.Len() == 0
 }
 
 // Removes the specified element from this set if it is present (optional operation).
 func (s *ConcurrentSet) Remove(e interface{}) bool {
-	_, exists := s.hash.Load(e)
-	s.hash.Delete(e)
+	_, exists := s.hash.LoadAndDelete(e)
 	if exists {
-		atomic.AddUint32(&s.size, ^uint32(0))
+		atomic.AddInt64(&s.size, -1)
 	}
 	return exists
 }
@@ -92,20 +110,18 @@ func (s *ConcurrentSet) Remove(e interface{}) bool {
 func (s *ConcurrentSet) RemoveAll(es ...interface{}) bool {
 	existAll := true
 	for _, e := range es {
-		_, exists := s.hash.Load(e)
-		if exists {
-			s.hash.Delete(e)
-			atomic.AddUint32(&s.size, ^uint32(0))
-		} else {
+		if !s.Remove(e) {
 			existAll = false
 		}
 	}
 	return existAll
 }
 
-// Return the number of elements in set s (cardinality of s).
+// Return the number of elements in set s (cardinality of s). While adds and
+// removes race, the count is approximate, though never negative; use a
+// ShardedSet for an exact count.
 func (s *ConcurrentSet) Len() uint32 {
-	return atomic.LoadUint32(&s.size)
+	return uint32(min(max(atomic.LoadInt64(&s.size), 0), math.MaxUint32))
 }
 
 // Returns an slice containing all of the elements in this set.
@@ -120,98 +136,42 @@ func (s *ConcurrentSet) ToSlice() []interface{} {
 }
 
 func (s *ConcurrentSet) Clone() Set {
-	var n sync.Map
-	s.hash.Range(func(k, v interface{}) bool {
-		n.Store(k, nothing{})
-		return true
-	})
-
-	return &ConcurrentSet{n, s.size}
+	return union(&ConcurrentSet{}, s, nil)
 }
 
 // Return a new set with elements common to the set and all others.
 func (s *ConcurrentSet) Intersection(others ...Set) Set {
-	var n sync.Map
-
-	size := uint32(0)
-	s.hash.Range(func(k, v interface{}) bool {
-		existAll := true
-		for _, set := range others {
-			if !set.Contains(k) {
-				existAll = false
-				break
-			}
-		}
-		if existAll {
-			n.Store(k, nothing{})
-			size++
-		}
-		return true
-	})
-
-	return &ConcurrentSet{n, size}
+	return intersection(&ConcurrentSet{}, s, others)
 }
 
 // Return a new set with elements from the set and all others.
 func (s *ConcurrentSet) Union(others ...Set) Set {
-	var n sync.Map
-	size := s.size
-	s.hash.Range(func(k, v interface{}) bool {
-		n.Store(k, nothing{})
-		return true
-	})
-
-	for _, set := range others {
-		cset := set.(*ConcurrentSet)
-		cset.hash.Range(func(k, v interface{}) bool {
-			_, exists := n.LoadOrStore(k, nothing{})
-			if exists {
-				size++
-			}
-			return true
-		})
-	}
-
-	return &ConcurrentSet{n, size}
+	return union(&ConcurrentSet{}, s, others)
 }
 
 // Return a new set with elements in the set that are not in the others.
 func (s *ConcurrentSet) Difference(others ...Set) Set {
-	var n sync.Map
-	size := uint32(0)
+	return difference(&ConcurrentSet{}, s, others)
+}
 
-	s.hash.Range(func(k, v interface{}) bool {
-		existAny := false
-		for _, set := range others {
-			if set.Contains(k) {
-				existAny = true
-			}
-		}
-		if !existAny {
-			n.Store(k, nothing{})
-			size++
-		}
-		return true
-	})
+// Return a new set with elements in either the set or other but not both.
+func (s *ConcurrentSet) SymmetricDifference(other Set) Set {
+	return symmetricDifference(&ConcurrentSet{}, s, other)
+}
+
+// Test whether the set and other contain the same elements.
+func (s *ConcurrentSet) Equal(other Set) bool {
+	return equal(s, other)
+}
 
-	return &ConcurrentSet{n, size}
+// Test whether the set has no elements in common with other.
+func (s *ConcurrentSet) IsDisjoint(other Set) bool {
+	return isDisjoint(s, other)
 }
 
 // Test whether every element in the set is in other. set <= other
 func (s *ConcurrentSet) IsSubset(other Set) bool {
-	if s.Len() > other.Len() {
-		return false
-	}
-
-	isSubset := true
-	s.hash.Range(func(k, v interface{}) bool {
-		if !other.Contains(k) {
-			isSubset = false
-			return false
-		}
-		return true
-	})
-	return isSubset
+	return isSubset(s, other)
 }
 
 // Test whether the set is a proper subset of other, that is, set <= other and set != other.
@@ -233,14 +193,49 @@ func (s *ConcurrentSet) UnmarshalText(text []byte) error {
 	var v []interface{}
 	err := json.Unmarshal(text, &v)
 	if err == nil {
-		for _, k := range v {
-			s.hash.Store(k, nothing{})
-		}
-		s.size = uint32(len(v))
+		s.Clear()
+		s.AddAll(v...)
 	}
 	return err
 }
 
+// Encode the set as a JSON array, sorted so that equal sets encode alike.
+func (s *ConcurrentSet) MarshalText() ([]byte, error) {
+	return json.Marshal(sortedElements(s))
+}
+
+// Encode the set as a JSON array, as MarshalText does.
+func (s *ConcurrentSet) MarshalJSON() ([]byte, error) {
+	return s.MarshalText()
+}
+
+// Replaces the elements of the set with those of a JSON array.
+func (s *ConcurrentSet) UnmarshalJSON(data []byte) error {
+	return s.UnmarshalText(data)
+}
+
+// Encode the set with gob, which keeps the types of the elements. Types
+// other than the basic ones must be registered with gob.Register.
+func (s *ConcurrentSet) GobEncode() ([]byte, error) {
+	return gobEncode(sortedElements(s))
+}
+
+// Replaces the elements of the set with those encoded by GobEncode.
+func (s *ConcurrentSet) GobDecode(data []byte) error {
+	return decodeInto(s, data, gobDecode)
+}
+
+// Encode the set in a compact binary form. Only nil and basic types other
+// than complex numbers and uintptr can be encoded.
+func (s *ConcurrentSet) MarshalBinary() ([]byte, error) {
+	return marshalBinary(sortedElements(s))
+}
+
+// Replaces the elements of the set with those encoded by MarshalBinary.
+func (s *ConcurrentSet) UnmarshalBinary(data []byte) error {
+	return decodeInto(s, data, unmarshalBinary)
+}
+
 func (s *ConcurrentSet) ToSet() *HashSet {
 	n := make(map[interface{}]nothing)
 
@@ -0,0 +1,212 @@
+package set
+
+import (
+	"encoding/json"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	ConcurrentSetOf[T comparable] struct {
+		hash sync.Map
+		size uint32
+	}
+)
+
+// Adds the specified element to this set if it is not already present (optional operation).
+func (s *ConcurrentSetOf[T]) Add(e T) {
+	_, exists := s.hash.LoadOrStore(e, nothing{})
+	if !exists {
+		atomic.AddUint32(&s.size, 1)
+	}
+}
+
+// Adds all of the elements to this set if they're not already present (optional operation).
+func (s *ConcurrentSetOf[T]) AddAll(es ...T) {
+	for _, e := range es {
+		s.Add(e)
+	}
+}
+
+// Removes all of the elements from this set (optional operation).
+func (s *ConcurrentSetOf[T]) Clear() {
+	s.hash.Range(func(k, v interface{}) bool {
+		s.Remove(k.(T))
+		return true
+	})
+}
+
+// Returns true if this set contains the specified element.
+func (s *ConcurrentSetOf[T]) Contains(e T) bool {
+	_, exists := s.hash.Load(e)
+	return exists
+}
+
+// Returns true if this set contains all of the elements of the specified collection.
+func (s *ConcurrentSetOf[T]) ContainsAll(es ...T) bool {
+	for _, e := range es {
+		_, exists := s.hash.Load(e)
+		if !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// Call f for each item in the set
+func (s *ConcurrentSetOf[T]) Foreach(f func(T)) {
+	s.hash.Range(func(k, v interface{}) bool {
+		f(k.(T))
+		return true
+	})
+}
+
+// Returns a sequence over the elements in this set.
+func (s *ConcurrentSetOf[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s.hash.Range(func(k, v interface{}) bool {
+			return yield(k.(T))
+		})
+	}
+}
+
+// Returns true if this set contains no elements.
+func (s *ConcurrentSetOf[T]) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+// Removes the specified element from this set if it is present (optional operation).
+func (s *ConcurrentSetOf[T]) Remove(e T) bool {
+	_, exists := s.hash.LoadAndDelete(e)
+	if exists {
+		atomic.AddUint32(&s.size, ^uint32(0))
+	}
+	return exists
+}
+
+// Removes the specified elements from this set if it is present (optional operation).
+// Return true if all element exist.
+func (s *ConcurrentSetOf[T]) RemoveAll(es ...T) bool {
+	existAll := true
+	for _, e := range es {
+		if !s.Remove(e) {
+			existAll = false
+		}
+	}
+	return existAll
+}
+
+// Return the number of elements in set s (cardinality of s).
+func (s *ConcurrentSetOf[T]) Len() uint32 {
+	return atomic.LoadUint32(&s.size)
+}
+
+// Returns an slice containing all of the elements in this set.
+func (s *ConcurrentSetOf[T]) ToSlice() []T {
+	slice := make([]T, 0, s.Len())
+	s.Foreach(func(e T) {
+		slice = append(slice, e)
+	})
+	return slice
+}
+
+// Returns a deep clone of set
+func (s *ConcurrentSetOf[T]) Clone() Of[T] {
+	n := &ConcurrentSetOf[T]{}
+	s.Foreach(n.Add)
+	return n
+}
+
+// Return a new set with elements common to the set and all others.
+func (s *ConcurrentSetOf[T]) Intersection(others ...Of[T]) Of[T] {
+	n := &ConcurrentSetOf[T]{}
+
+	s.Foreach(func(k T) {
+		for _, set := range others {
+			if !set.Contains(k) {
+				return
+			}
+		}
+		n.Add(k)
+	})
+
+	return n
+}
+
+// Return a new set with elements from the set and all others.
+func (s *ConcurrentSetOf[T]) Union(others ...Of[T]) Of[T] {
+	n := &ConcurrentSetOf[T]{}
+
+	s.Foreach(n.Add)
+	for _, set := range others {
+		set.Foreach(n.Add)
+	}
+
+	return n
+}
+
+// Return a new set with elements in the set that are not in the others.
+func (s *ConcurrentSetOf[T]) Difference(others ...Of[T]) Of[T] {
+	n := &ConcurrentSetOf[T]{}
+
+	s.Foreach(func(k T) {
+		for _, set := range others {
+			if set.Contains(k) {
+				return
+			}
+		}
+		n.Add(k)
+	})
+
+	return n
+}
+
+// Test whether every element in the set is in other. set <= other
+func (s *ConcurrentSetOf[T]) IsSubset(other Of[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+
+	isSubset := true
+	s.hash.Range(func(k, v interface{}) bool {
+		if !other.Contains(k.(T)) {
+			isSubset = false
+			return false
+		}
+		return true
+	})
+	return isSubset
+}
+
+// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+func (s *ConcurrentSetOf[T]) IsProperSubset(other Of[T]) bool {
+	return s.Len() < other.Len() && s.IsSubset(other)
+}
+
+// Test whether every element in other is in the set. set >= other
+func (s *ConcurrentSetOf[T]) IsSuperset(other Of[T]) bool {
+	return other.IsSubset(s)
+}
+
+// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+func (s *ConcurrentSetOf[T]) IsProperSuperset(other Of[T]) bool {
+	return s.Len() > other.Len() && s.IsSuperset(other)
+}
+
+// Encode the set as a JSON array, sorted so that equal sets encode alike.
+func (s *ConcurrentSetOf[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sortElementsOf(s.ToSlice()))
+}
+
+// Replaces the elements of the set with those of a JSON array, decoded as
+// Ts.
+func (s *ConcurrentSetOf[T]) UnmarshalJSON(data []byte) error {
+	var v []T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	s.Clear()
+	s.AddAll(v...)
+	return nil
+}
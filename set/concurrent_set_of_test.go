@@ -0,0 +1,83 @@
+package set
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestNewConcurrentSetOf(t *testing.T) {
+	s := NewConcurrentSetOf[int]()
+	if s.Len() != 0 {
+		t.Error("Length of empty init set should be 0")
+	}
+
+	s = NewConcurrentSetOf(1, 4, 8, 8)
+	if s.Len() != 3 {
+		t.Error("Length should be 3")
+	}
+}
+
+func TestConcurrentSetOf_Len(t *testing.T) {
+	s := NewConcurrentSetOf[int]()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 1000; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Add(i)
+			s.Add(i)
+		}(i)
+	}
+	wg.Wait()
+
+	if s.Len() != 1000 {
+		t.Errorf("Length %d should be 1000", s.Len())
+	}
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.Remove(i)
+			s.Remove(i)
+		}(i)
+	}
+	wg.Wait()
+
+	if s.Len() != 900 {
+		t.Errorf("Length %d should be 900", s.Len())
+	}
+
+	s.Clear()
+	if !s.IsEmpty() {
+		t.Error("Set should be empty after Clear()")
+	}
+}
+
+func TestConcurrentSetOf_Algebra(t *testing.T) {
+	s1 := NewConcurrentSetOf(1, 2, 4)
+	s2 := NewHashSetOf(1, 2, 8)
+
+	u := s1.Union(s2)
+	if u.Len() != 4 || !u.ContainsAll(1, 2, 4, 8) {
+		t.Error("Union should be 1, 2, 4, 8")
+	}
+	if _, ok := u.(*ConcurrentSetOf[int]); !ok {
+		t.Error("Union of a concurrent set should be concurrent")
+	}
+
+	i := s1.Intersection(s2)
+	if i.Len() != 2 || !i.ContainsAll(1, 2) {
+		t.Error("Intersection should be 1, 2")
+	}
+
+	d := s1.Difference(s2)
+	if d.Len() != 1 || !d.Contains(4) {
+		t.Error("Difference should be 4")
+	}
+
+	if !d.IsProperSubset(s1) || !s1.IsSuperset(d) {
+		t.Error("Difference should be a proper subset of s1")
+	}
+}
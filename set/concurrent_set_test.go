@@ -141,6 +141,66 @@ func TestConcurrentSet_Len(t *testing.T) {
 	}
 }
 
+func TestConcurrentSet_LenUnderRace(t *testing.T) {
+	s := NewConcurrentSet()
+	wg := &sync.WaitGroup{}
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				s.Add(i % 100)
+				s.Remove(i % 100)
+				s.IsEmpty()
+			}
+		}()
+	}
+	wg.Wait()
+	if s.Len() != 0 || !s.IsEmpty() || len(s.ToSlice()) != 0 {
+		t.Errorf("Length %d should be 0", s.Len())
+	}
+}
+
+func TestConcurrentSet_LenBounded(t *testing.T) {
+	s := NewConcurrentSet()
+	done := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	// Removers delete elements as soon as adders store them, often before
+	// the adders count them
+	for w := 0; w < 8; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20000; i++ {
+				s.Add(i % 10)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20000; i++ {
+				s.Remove(i % 10)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for {
+		select {
+		case <-done:
+			if n := s.Len(); n > 10 || int(n) != len(s.ToSlice()) {
+				t.Errorf("Length %d should be that of %v", n, s.ToSlice())
+			}
+			return
+		default:
+			if n := s.Len(); n > 10 {
+				t.Fatalf("Length %d should not exceed the 10 elements added", n)
+			}
+		}
+	}
+}
+
 func TestConcurrentSet_Clone(t *testing.T) {
 	s := NewConcurrentSet(1, 2, 4)
 	s2 := s.Clone()
@@ -339,4 +399,24 @@ func TestConcurrentSet_ToSet(t *testing.T) {
 	if !hs.ContainsAll(1, 2, 4) {
 		t.Error("set should contain 1, 2, 4")
 	}
-}
\ No newline at end of file
+}
+
+func TestConcurrentSet_Iterator(t *testing.T) {
+	s := NewConcurrentSet(1, 2, 4)
+	it := IteratorOf(s)
+	sum := 0
+	for it.Next() {
+		sum += it.Value().(int)
+	}
+	if sum != 7 || it.Err() != nil {
+		t.Error("Iterator should visit 1, 2, 4")
+	}
+
+	sum = 0
+	for e := range All(s) {
+		sum += e.(int)
+	}
+	if sum != 7 {
+		t.Error("All should visit 1, 2, 4")
+	}
+}
@@ -0,0 +1,213 @@
+// Package crdt provides conflict-free replicated sets. Each replica changes
+// its own copy, and replicas converge to the same elements by merging each
+// other's states in any order, any number of times.
+//
+// GSet only grows. TwoPSet also removes elements, but an element removed
+// once can never be added again. ORSet, the observed-remove set, allows
+// adding and removing elements at will: a remove only cancels the adds it
+// has seen, so an add concurrent with a remove wins. It tracks adds with
+// dots, pairs of a replica and a counter, and what each replica has seen
+// with a dotted version vector.
+//
+// Besides the whole state, every type keeps the changes made locally since
+// the last call to Delta. Shipping and merging deltas rather than states
+// saves bandwidth; replicas still converge as long as every delta is
+// eventually merged everywhere.
+//
+// All types encode to JSON and to a compact binary form. JSON elements are
+// decoded as set.DecodeNumbers does, so integers come back as ints.
+package crdt
+
+import (
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/billryan/collections/internal/codec"
+	"github.com/billryan/collections/set"
+)
+
+type (
+	// Dot identifies an add: the replica that made it and how many events
+	// that replica had made by then.
+	Dot struct {
+		Replica string `json:"replica"`
+		Counter uint64 `json:"counter"`
+	}
+
+	// A causal context records the dots a replica has seen: a version vector
+	// for each replica's dots seen without gaps, and the dots seen past a
+	// gap
+	causalContext struct {
+		vv    map[string]uint64
+		cloud map[Dot]struct{}
+	}
+
+	// Wire form of a causal context
+	contextJSON struct {
+		VV    map[string]uint64 `json:"vv"`
+		Cloud []Dot             `json:"cloud,omitempty"`
+	}
+)
+
+// Version of the binary forms
+const version = 1
+
+func newContext() causalContext {
+	return causalContext{vv: make(map[string]uint64), cloud: make(map[Dot]struct{})}
+}
+
+func (c *causalContext) contains(d Dot) bool {
+	if d.Counter <= c.vv[d.Replica] {
+		return true
+	}
+	_, ok := c.cloud[d]
+	return ok
+}
+
+func (c *causalContext) add(d Dot) {
+	if d.Counter == c.vv[d.Replica]+1 {
+		c.vv[d.Replica] = d.Counter
+		c.compact()
+	} else if !c.contains(d) {
+		c.cloud[d] = struct{}{}
+	}
+}
+
+// Get a dot for a new event of the replica
+func (c *causalContext) next(replica string) Dot {
+	d := Dot{replica, c.vv[replica] + 1}
+	c.add(d)
+	return d
+}
+
+func (c *causalContext) merge(o causalContext) {
+	for r, n := range o.vv {
+		c.vv[r] = max(c.vv[r], n)
+	}
+	for d := range o.cloud {
+		if !c.contains(d) {
+			c.cloud[d] = struct{}{}
+		}
+	}
+	c.compact()
+}
+
+// Move into the version vector the dots of the cloud that no longer follow
+// a gap
+func (c *causalContext) compact() {
+	for moved := true; moved; {
+		moved = false
+		for d := range c.cloud {
+			switch n := c.vv[d.Replica]; {
+			case d.Counter == n+1:
+				c.vv[d.Replica] = d.Counter
+				moved = true
+				fallthrough
+			case d.Counter <= n:
+				delete(c.cloud, d)
+			}
+		}
+	}
+}
+
+func (c *causalContext) clone() causalContext {
+	return causalContext{vv: maps.Clone(c.vv), cloud: maps.Clone(c.cloud)}
+}
+
+func (c *causalContext) equal(o causalContext) bool {
+	return maps.Equal(c.vv, o.vv) && maps.Equal(c.cloud, o.cloud)
+}
+
+func (c *causalContext) toJSON() contextJSON {
+	return contextJSON{VV: c.vv, Cloud: sortDots(slices.Collect(maps.Keys(c.cloud)))}
+}
+
+func (j contextJSON) context() causalContext {
+	c := newContext()
+	for r, n := range j.VV {
+		c.vv[r] = n
+	}
+	for _, d := range j.Cloud {
+		c.add(d)
+	}
+	return c
+}
+
+// Append the version vector, sorted by replica, and the cloud
+func (c *causalContext) appendBinary(b []byte) []byte {
+	replicas := slices.Sorted(maps.Keys(c.vv))
+	b = binary.AppendUvarint(b, uint64(len(replicas)))
+	for _, r := range replicas {
+		b = binary.AppendUvarint(codec.AppendString(b, r), c.vv[r])
+	}
+	return appendDots(b, slices.Collect(maps.Keys(c.cloud)))
+}
+
+func readContext(r *codec.Reader) causalContext {
+	c := newContext()
+	// Each replica takes at least two bytes
+	for n := r.Count(2); n > 0; n-- {
+		replica := r.String()
+		c.vv[replica] = r.Uvarint()
+	}
+	for _, d := range readDots(r) {
+		c.add(d)
+	}
+	return c
+}
+
+func appendDots(b []byte, dots []Dot) []byte {
+	b = binary.AppendUvarint(b, uint64(len(dots)))
+	for _, d := range sortDots(dots) {
+		b = binary.AppendUvarint(codec.AppendString(b, d.Replica), d.Counter)
+	}
+	return b
+}
+
+func readDots(r *codec.Reader) []Dot {
+	dots := make([]Dot, r.Count(2))
+	for i := range dots {
+		dots[i].Replica = r.String()
+		dots[i].Counter = r.Uvarint()
+	}
+	return dots
+}
+
+func sortDots(dots []Dot) []Dot {
+	slices.SortFunc(dots, func(a, b Dot) int {
+		if c := strings.Compare(a.Replica, b.Replica); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Counter, b.Counter)
+	})
+	return dots
+}
+
+// Decode the elements of a JSON array as set.DecodeNumbers does
+func decodeElements(raw []json.RawMessage) ([]interface{}, error) {
+	es := make([]interface{}, len(raw))
+	for i, r := range raw {
+		e, err := set.DecodeNumbers(r)
+		if err != nil {
+			return nil, err
+		}
+		es[i] = e
+	}
+	return es, nil
+}
+
+// Check the version byte of a binary form, returning a reader of the rest
+func readVersion(data []byte) (*codec.Reader, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("crdt: %w", codec.ErrTruncated)
+	}
+	if data[0] != version {
+		return nil, fmt.Errorf("crdt: unknown version %d", data[0])
+	}
+	return codec.NewReader(data[1:]), nil
+}
@@ -0,0 +1,66 @@
+package crdt
+
+import (
+	"math/rand/v2"
+)
+
+// A replicated set merging states and deltas of its own type
+type replica[S any] interface {
+	Merge(others ...S)
+	Delta() S
+	Clone() S
+}
+
+// Run a random schedule on the replicas: updates made by update, deltas and
+// whole states sent to the other replicas, delivered late, out of order and
+// sometimes twice. All messages are delivered by the time it returns, so the
+// replicas should have converged.
+func simulate[S replica[S]](seed uint64, replicas []S, update func(r *rand.Rand, s S)) {
+	r := rand.New(rand.NewPCG(seed, 0))
+	type message struct {
+		to    int
+		state S
+	}
+	var inbox []message
+	send := func(from int, state S) {
+		for to := range replicas {
+			if to != from {
+				inbox = append(inbox, message{to, state})
+			}
+		}
+	}
+	deliver := func() {
+		i := r.IntN(len(inbox))
+		m := inbox[i]
+		replicas[m.to].Merge(m.state)
+		if r.IntN(10) == 0 {
+			// Deliver it again later
+			return
+		}
+		inbox[i] = inbox[len(inbox)-1]
+		inbox = inbox[:len(inbox)-1]
+	}
+
+	for step := 0; step < 500; step++ {
+		i := r.IntN(len(replicas))
+		s := replicas[i]
+		switch r.IntN(6) {
+		case 0, 1, 2, 3:
+			update(r, s)
+		case 4:
+			send(i, s.Delta())
+		case 5:
+			if len(inbox) > 0 {
+				deliver()
+			} else if r.IntN(2) == 0 {
+				send(i, s.Clone())
+			}
+		}
+	}
+	for i, s := range replicas {
+		send(i, s.Delta())
+	}
+	for len(inbox) > 0 {
+		deliver()
+	}
+}
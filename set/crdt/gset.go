@@ -0,0 +1,110 @@
+package crdt
+
+import (
+	"encoding/json"
+
+	"github.com/billryan/collections/set"
+)
+
+type (
+	// GSet is a grow-only set: elements can be added but never removed. The
+	// merge of two GSets is their union.
+	GSet struct {
+		elements set.Set
+		// Elements added since the last call to Delta
+		delta set.Set
+	}
+)
+
+// Create a new grow-only set
+func NewGSet(initial ...interface{}) *GSet {
+	g := &GSet{elements: set.NewHashSet(), delta: set.NewHashSet()}
+	g.AddAll(initial...)
+	return g
+}
+
+// Adds the specified element to this set if it is not already present.
+func (g *GSet) Add(e interface{}) {
+	if !g.elements.Contains(e) {
+		g.elements.Add(e)
+		g.delta.Add(e)
+	}
+}
+
+// Adds all of the elements to this set if they're not already present.
+func (g *GSet) AddAll(es ...interface{}) {
+	for _, e := range es {
+		g.Add(e)
+	}
+}
+
+// Returns true if this set contains the specified element.
+func (g *GSet) Contains(e interface{}) bool {
+	return g.elements.Contains(e)
+}
+
+// Return the number of elements in the set.
+func (g *GSet) Len() uint32 {
+	return g.elements.Len()
+}
+
+// Returns a copy of the elements of the set.
+func (g *GSet) Elements() set.Set {
+	return g.elements.Clone()
+}
+
+// Merge the states or deltas of other replicas into this one.
+func (g *GSet) Merge(others ...*GSet) {
+	for _, o := range others {
+		g.elements.AddAll(o.elements.ToSlice()...)
+	}
+}
+
+// Returns the elements added to this replica since the last call, as a
+// GSet to merge into other replicas, and starts a new delta.
+func (g *GSet) Delta() *GSet {
+	d := &GSet{elements: g.delta, delta: set.NewHashSet()}
+	g.delta = set.NewHashSet()
+	return d
+}
+
+// Returns a deep clone of the set, sharing no state with it.
+func (g *GSet) Clone() *GSet {
+	return &GSet{elements: g.elements.Clone(), delta: g.delta.Clone()}
+}
+
+// Test whether the set and other contain the same elements.
+func (g *GSet) Equal(other *GSet) bool {
+	return set.Equal(g.elements, other.elements)
+}
+
+// Encode the set as a sorted JSON array.
+func (g *GSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.elements)
+}
+
+// Replaces the set with one decoded from a JSON array. The delta is reset.
+func (g *GSet) UnmarshalJSON(data []byte) error {
+	elements := set.NewHashSet()
+	if err := set.UnmarshalJSONWith(elements, data, set.DecodeNumbers); err != nil {
+		return err
+	}
+	g.elements, g.delta = elements, set.NewHashSet()
+	return nil
+}
+
+// Encode the set in the binary form of set.HashSet.
+func (g *GSet) MarshalBinary() ([]byte, error) {
+	return g.elements.(*set.HashSet).MarshalBinary()
+}
+
+// Replaces the set with one decoded from the form written by
+// MarshalBinary. The delta is reset.
+func (g *GSet) UnmarshalBinary(data []byte) error {
+	elements := set.NewHashSet()
+	if err := elements.(*set.HashSet).UnmarshalBinary(data); err != nil {
+		return err
+	}
+	g.elements, g.delta = elements, set.NewHashSet()
+	return nil
+}
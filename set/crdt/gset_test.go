@@ -0,0 +1,94 @@
+package crdt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/billryan/collections/set"
+)
+
+func TestGSet_Merge(t *testing.T) {
+	a, b := NewGSet(1, 2), NewGSet(2, 3)
+	a.Merge(b)
+	b.Merge(a)
+	if !a.Equal(b) || a.Len() != 3 {
+		t.Errorf("Replicas should converge to {1, 2, 3}, got %v and %v", a.Elements(), b.Elements())
+	}
+	a.Merge(a.Clone(), b)
+	if a.Len() != 3 {
+		t.Errorf("Merging again should change nothing, got %v", a.Elements())
+	}
+}
+
+func TestGSet_Delta(t *testing.T) {
+	a, b := NewGSet(), NewGSet()
+	a.AddAll(1, 2)
+	a.Add(1)
+	d := a.Delta()
+	if d.Len() != 2 || !d.Contains(1) || !d.Contains(2) {
+		t.Errorf("Delta should hold {1, 2}, got %v", d.Elements())
+	}
+	if a.Delta().Len() != 0 {
+		t.Errorf("Delta should be reset")
+	}
+	a.Add(3)
+	b.Merge(d, a.Delta())
+	if !b.Equal(a) {
+		t.Errorf("Merging deltas should converge to %v, got %v", a.Elements(), b.Elements())
+	}
+}
+
+func TestGSet_Encoding(t *testing.T) {
+	g := NewGSet(3, "a", 1)
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `[1,3,"a"]` {
+		t.Errorf("Got %s", data)
+	}
+	j := NewGSet()
+	if err := json.Unmarshal(data, j); err != nil || !j.Equal(g) || !j.Contains(1) {
+		t.Errorf("JSON round trip got %v, %v", j.Elements(), err)
+	}
+
+	data, err = g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewGSet()
+	if err := b.UnmarshalBinary(data); err != nil || !b.Equal(g) {
+		t.Errorf("Binary round trip got %v, %v", b.Elements(), err)
+	}
+	if b.Delta().Len() != 0 {
+		t.Errorf("Decoding should reset the delta")
+	}
+	if err := b.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Errorf("Truncated data should fail")
+	}
+}
+
+// Replicas adding random elements and exchanging states and deltas in random
+// order, some of them twice, should all hold every element once every
+// message is delivered.
+func TestGSet_Convergence(t *testing.T) {
+	for seed := uint64(0); seed < 20; seed++ {
+		t.Run(fmt.Sprint("seed ", seed), func(t *testing.T) {
+			replicas := []*GSet{NewGSet(), NewGSet(), NewGSet(), NewGSet()}
+			added := set.NewHashSet()
+			simulate(seed, replicas, func(r *rand.Rand, g *GSet) {
+				e := r.IntN(50)
+				g.Add(e)
+				added.Add(e)
+			})
+
+			for i, g := range replicas {
+				if !set.Equal(g.Elements(), added) {
+					t.Fatalf("Replica %d has %v, want %v", i, g.Elements().ToSlice(), added.ToSlice())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package crdt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/billryan/collections/internal/codec"
+	"github.com/billryan/collections/set"
+)
+
+type (
+	// ORSet is an observed-remove set. Every add is tagged with a new dot,
+	// and an element is present while any of its dots is. A remove drops
+	// the dots the replica has seen for the element, so an add made
+	// concurrently elsewhere survives the merge: adds win. The causal
+	// context, the dots seen so far, tells a dot that was removed from one
+	// the replica has not heard of yet.
+	//
+	// Each replica must have a distinct name.
+	ORSet struct {
+		replica string
+		entries map[interface{}]map[Dot]struct{}
+		context causalContext
+		// Changes made since the last call to Delta, as an ORSet without a
+		// replica
+		delta *ORSet
+	}
+
+	orSetJSON struct {
+		Replica string       `json:"replica,omitempty"`
+		Entries []entryJSON  `json:"entries"`
+		Context *contextJSON `json:"context"`
+	}
+
+	entryJSON struct {
+		Element json.RawMessage `json:"element"`
+		Dots    []Dot           `json:"dots"`
+	}
+)
+
+// Create a new observed-remove set for the named replica
+func NewORSet(replica string) *ORSet {
+	s := newORSet(replica)
+	s.delta = newORSet("")
+	return s
+}
+
+func newORSet(replica string) *ORSet {
+	return &ORSet{
+		replica: replica,
+		entries: make(map[interface{}]map[Dot]struct{}),
+		context: newContext(),
+	}
+}
+
+// Returns the name of the replica.
+func (s *ORSet) Replica() string {
+	return s.replica
+}
+
+// Adds the specified element to this set, tagged with a new dot that
+// replaces the dots seen for it so far.
+func (s *ORSet) Add(e interface{}) {
+	d := s.context.next(s.replica)
+	old := s.entries[e]
+	s.entries[e] = map[Dot]struct{}{d: {}}
+
+	delta := newORSet("")
+	delta.entries[e] = map[Dot]struct{}{d: {}}
+	delta.context.add(d)
+	for o := range old {
+		delta.context.add(o)
+	}
+	s.delta.Merge(delta)
+}
+
+// Adds all of the elements to this set.
+func (s *ORSet) AddAll(es ...interface{}) {
+	for _, e := range es {
+		s.Add(e)
+	}
+}
+
+// Removes the specified element from this set if it is present, cancelling
+// the adds this replica has seen. Returns true if it was present.
+func (s *ORSet) Remove(e interface{}) bool {
+	old, exist := s.entries[e]
+	if !exist {
+		return false
+	}
+	delete(s.entries, e)
+
+	delta := newORSet("")
+	for o := range old {
+		delta.context.add(o)
+	}
+	s.delta.Merge(delta)
+	return true
+}
+
+// Returns true if this set contains the specified element.
+func (s *ORSet) Contains(e interface{}) bool {
+	_, exist := s.entries[e]
+	return exist
+}
+
+// Return the number of elements in the set.
+func (s *ORSet) Len() uint32 {
+	return uint32(len(s.entries))
+}
+
+// Returns a copy of the elements of the set.
+func (s *ORSet) Elements() set.Set {
+	n := set.NewHashSet()
+	for e := range s.entries {
+		n.Add(e)
+	}
+	return n
+}
+
+// Merge the states or deltas of other replicas into this one. A dot is kept
+// if both sides have it, or if one side has it and the other has not seen
+// it; a dot one side has seen but dropped was removed.
+func (s *ORSet) Merge(others ...*ORSet) {
+	for _, o := range others {
+		s.merge(o)
+	}
+}
+
+func (s *ORSet) merge(o *ORSet) {
+	for e, dots := range s.entries {
+		theirs := o.entries[e]
+		for d := range dots {
+			if _, both := theirs[d]; !both && o.context.contains(d) {
+				delete(dots, d)
+			}
+		}
+		if len(dots) == 0 {
+			delete(s.entries, e)
+		}
+	}
+	for e, theirs := range o.entries {
+		for d := range theirs {
+			if s.context.contains(d) {
+				continue
+			}
+			dots := s.entries[e]
+			if dots == nil {
+				dots = make(map[Dot]struct{})
+				s.entries[e] = dots
+			}
+			dots[d] = struct{}{}
+		}
+	}
+	s.context.merge(o.context)
+}
+
+// Returns the changes made on this replica since the last call, as an
+// ORSet to merge into other replicas, and starts a new delta. The delta
+// carries the dots of the adds and removes, not the whole causal context.
+func (s *ORSet) Delta() *ORSet {
+	d := s.delta
+	s.delta = newORSet("")
+	return d
+}
+
+// Returns a deep clone of the set, sharing no state with it.
+func (s *ORSet) Clone() *ORSet {
+	n := &ORSet{
+		replica: s.replica,
+		entries: make(map[interface{}]map[Dot]struct{}, len(s.entries)),
+		context: s.context.clone(),
+	}
+	for e, dots := range s.entries {
+		n.entries[e] = maps.Clone(dots)
+	}
+	if s.delta != nil {
+		n.delta = s.delta.Clone()
+	}
+	return n
+}
+
+// Test whether the set and other contain the same elements.
+func (s *ORSet) Equal(other *ORSet) bool {
+	if len(s.entries) != len(other.entries) {
+		return false
+	}
+	for e := range s.entries {
+		if !other.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Get the elements in a deterministic order
+func (s *ORSet) sortedElements() []interface{} {
+	es := slices.Collect(maps.Keys(s.entries))
+	slices.SortFunc(es, codec.Compare)
+	return es
+}
+
+// Encode the set as a JSON object holding the replica, each element with
+// its dots, and the causal context.
+func (s *ORSet) MarshalJSON() ([]byte, error) {
+	v := orSetJSON{Replica: s.replica, Entries: []entryJSON{}}
+	for _, e := range s.sortedElements() {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		v.Entries = append(v.Entries, entryJSON{data, sortDots(slices.Collect(maps.Keys(s.entries[e])))})
+	}
+	c := s.context.toJSON()
+	v.Context = &c
+	return json.Marshal(v)
+}
+
+// Replaces the set with one decoded from the form written by MarshalJSON.
+// The delta is reset.
+func (s *ORSet) UnmarshalJSON(data []byte) error {
+	var v orSetJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	n := newORSet(v.Replica)
+	raw := make([]json.RawMessage, len(v.Entries))
+	for i, entry := range v.Entries {
+		raw[i] = entry.Element
+	}
+	es, err := decodeElements(raw)
+	if err != nil {
+		return err
+	}
+	for i, e := range es {
+		n.addDots(e, v.Entries[i].Dots)
+	}
+	if v.Context != nil {
+		n.context = v.Context.context()
+	}
+	if err := n.check(); err != nil {
+		return err
+	}
+	n.delta = newORSet("")
+	*s = *n
+	return nil
+}
+
+// Encode the set as a version byte, the replica, the number of elements,
+// each element followed by its dots, and the causal context. Strings are
+// prefixed by their length and numbers are varints.
+func (s *ORSet) MarshalBinary() ([]byte, error) {
+	b := codec.AppendString([]byte{version}, s.replica)
+	b = binary.AppendUvarint(b, uint64(len(s.entries)))
+	for _, e := range s.sortedElements() {
+		var err error
+		if b, err = codec.AppendElement(b, e); err != nil {
+			return nil, fmt.Errorf("crdt: %w", err)
+		}
+		b = appendDots(b, slices.Collect(maps.Keys(s.entries[e])))
+	}
+	return s.context.appendBinary(b), nil
+}
+
+// Replaces the set with one decoded from the form written by
+// MarshalBinary. The delta is reset.
+func (s *ORSet) UnmarshalBinary(data []byte) error {
+	r, err := readVersion(data)
+	if err != nil {
+		return err
+	}
+	n := newORSet(r.String())
+	// Each entry takes at least an element tag and a count of dots
+	for i := r.Count(2); i > 0 && r.Err() == nil; i-- {
+		e := r.Element()
+		n.addDots(e, readDots(r))
+	}
+	n.context = readContext(r)
+	if err := r.Close(); err != nil {
+		return fmt.Errorf("crdt: %w", err)
+	}
+	if err := n.check(); err != nil {
+		return err
+	}
+	n.delta = newORSet("")
+	*s = *n
+	return nil
+}
+
+func (s *ORSet) addDots(e interface{}, dots []Dot) {
+	if len(dots) == 0 {
+		return
+	}
+	m := s.entries[e]
+	if m == nil {
+		m = make(map[Dot]struct{}, len(dots))
+		s.entries[e] = m
+	}
+	for _, d := range dots {
+		m[d] = struct{}{}
+	}
+}
+
+// Check that the causal context covers every dot, as it does for any
+// state built by this package
+func (s *ORSet) check() error {
+	for e, dots := range s.entries {
+		for d := range dots {
+			if !s.context.contains(d) {
+				return fmt.Errorf("crdt: dot %v of %v missing from the causal context", d, e)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package crdt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestORSet_AddRemove(t *testing.T) {
+	s := NewORSet("a")
+	s.AddAll(1, 2, 1)
+	if s.Len() != 2 || !s.Contains(1) || !s.Contains(2) {
+		t.Errorf("Set should be {1, 2}, got %v", s.Elements())
+	}
+	if len(s.entries[1]) != 1 {
+		t.Errorf("Re-adding should replace the dots of 1, got %v", s.entries[1])
+	}
+	if !s.Remove(1) || s.Remove(1) || s.Contains(1) {
+		t.Errorf("1 should be removed once")
+	}
+	s.Add(1)
+	if !s.Contains(1) {
+		t.Errorf("A removed element can be added back")
+	}
+	if s.Replica() != "a" {
+		t.Errorf("Replica should be a, got %q", s.Replica())
+	}
+}
+
+func TestORSet_AddWins(t *testing.T) {
+	a := NewORSet("a")
+	a.Add("x")
+	b := NewORSet("b")
+	b.Merge(a)
+
+	// b removes the add it has seen while a adds x again
+	b.Remove("x")
+	a.Add("x")
+	a.Merge(b)
+	b.Merge(a)
+	if !a.Contains("x") || !b.Contains("x") {
+		t.Errorf("A concurrent add should win over a remove, got %v and %v", a.Elements(), b.Elements())
+	}
+
+	// A remove that has seen every add wins
+	b.Remove("x")
+	a.Merge(b)
+	if a.Contains("x") {
+		t.Errorf("An observed remove should remove x, got %v", a.Elements())
+	}
+}
+
+func TestORSet_Delta(t *testing.T) {
+	a, b := NewORSet("a"), NewORSet("b")
+	a.AddAll(1, 2, 3)
+	a.Remove(2)
+	d := a.Delta()
+	if d.Len() != 2 || d.Contains(2) {
+		t.Errorf("Delta should hold {1, 3}, got %v", d.Elements())
+	}
+	if a.Delta().Len() != 0 {
+		t.Errorf("Delta should be reset")
+	}
+	b.Merge(d)
+	if !b.Equal(a) {
+		t.Errorf("Merging the delta should converge to %v, got %v", a.Elements(), b.Elements())
+	}
+
+	// The delta of a remove carries no element, only the removed dots
+	a.Remove(1)
+	b.Merge(a.Delta())
+	if b.Contains(1) {
+		t.Errorf("Merging the delta of a remove should remove 1, got %v", b.Elements())
+	}
+}
+
+func TestORSet_Clone(t *testing.T) {
+	a := NewORSet("a")
+	a.Add(1)
+	c := a.Clone()
+	a.Remove(1)
+	a.Add(2)
+	if !c.Contains(1) || c.Contains(2) || c.Delta().Len() != 1 {
+		t.Errorf("Clone should not share state, got %v", c.Elements())
+	}
+}
+
+func TestORSet_Encoding(t *testing.T) {
+	a := NewORSet("a")
+	a.AddAll(2, "x", 1.5)
+	b := NewORSet("b")
+	b.Add(2)
+	// Receiving only the second of a's changes leaves a gap in b's context
+	a.Delta()
+	a.Remove(2)
+	a.Add(7)
+	b.Merge(a.Delta())
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"replica":"b","entries":[{"element":2,"dots":[{"replica":"b","counter":1}]},` +
+		`{"element":7,"dots":[{"replica":"a","counter":4}]}],` +
+		`"context":{"vv":{"a":1,"b":1},"cloud":[{"replica":"a","counter":4}]}}`
+	if string(data) != want {
+		t.Errorf("Got %s, want %s", data, want)
+	}
+	j := NewORSet("")
+	if err := json.Unmarshal(data, j); err != nil {
+		t.Fatal(err)
+	}
+	if !j.Equal(b) || !j.context.equal(b.context) || j.Replica() != "b" || !j.Contains(7) {
+		t.Errorf("JSON round trip got %s", data)
+	}
+
+	for _, s := range []*ORSet{a, b} {
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := NewORSet("")
+		if err := d.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !d.Equal(s) || !d.context.equal(s.context) || d.Replica() != s.Replica() {
+			t.Errorf("Binary round trip of %v got %v", s.Elements(), d.Elements())
+		}
+		for i := range data {
+			if err := d.UnmarshalBinary(data[:i]); err == nil {
+				t.Errorf("Data truncated to %d bytes should fail", i)
+			}
+		}
+	}
+
+	if err := j.UnmarshalJSON([]byte(`{"entries":[{"element":1,"dots":[{"replica":"a","counter":1}]}]}`)); err == nil {
+		t.Errorf("A dot missing from the context should fail")
+	}
+	if _, err := NewORSet("a").MarshalBinary(); err != nil {
+		t.Error(err)
+	}
+	s := NewORSet("a")
+	s.Add(struct{}{})
+	if _, err := s.MarshalBinary(); err == nil {
+		t.Errorf("Encoding a struct should fail")
+	}
+}
+
+// Replicas making random changes and exchanging states and deltas in random
+// order, some of them twice, should all converge once every message is
+// delivered.
+func TestORSet_Convergence(t *testing.T) {
+	for seed := uint64(0); seed < 20; seed++ {
+		t.Run(fmt.Sprint("seed ", seed), func(t *testing.T) {
+			replicas := make([]*ORSet, 4)
+			for i := range replicas {
+				replicas[i] = NewORSet(fmt.Sprint("r", i))
+			}
+			simulate(seed, replicas, func(r *rand.Rand, s *ORSet) {
+				if e := r.IntN(10); r.IntN(4) == 0 {
+					s.Remove(e)
+				} else {
+					s.Add(e)
+				}
+			})
+
+			for _, s := range replicas[1:] {
+				if !s.Equal(replicas[0]) || !s.context.equal(replicas[0].context) {
+					t.Fatalf("Replica %s has %v, replica r0 has %v", s.Replica(), s.Elements(), replicas[0].Elements())
+				}
+			}
+			// Merging whole states gives the same result
+			merged := NewORSet("m")
+			for _, s := range replicas {
+				merged.Merge(s.Clone())
+			}
+			if !merged.Equal(replicas[0]) {
+				t.Errorf("Merged states have %v, replicas have %v", merged.Elements(), replicas[0].Elements())
+			}
+		})
+	}
+}
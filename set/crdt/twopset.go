@@ -0,0 +1,154 @@
+package crdt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/billryan/collections/set"
+)
+
+type (
+	// TwoPSet is a two-phase set: a grow-only set of added elements and one
+	// of removed elements, the tombstones. An element is present if it was
+	// added and not removed, so once removed it can never be added back.
+	TwoPSet struct {
+		added, removed *GSet
+	}
+
+	twoPSetJSON struct {
+		Added   *GSet `json:"added"`
+		Removed *GSet `json:"removed"`
+	}
+)
+
+// Create a new two-phase set
+func NewTwoPSet(initial ...interface{}) *TwoPSet {
+	return &TwoPSet{added: NewGSet(initial...), removed: NewGSet()}
+}
+
+// Adds the specified element to this set. Returns false if it was removed
+// before, in which case it stays removed.
+func (t *TwoPSet) Add(e interface{}) bool {
+	if t.removed.Contains(e) {
+		return false
+	}
+	t.added.Add(e)
+	return true
+}
+
+// Adds all of the elements to this set. Returns false if any was removed
+// before.
+func (t *TwoPSet) AddAll(es ...interface{}) bool {
+	all := true
+	for _, e := range es {
+		if !t.Add(e) {
+			all = false
+		}
+	}
+	return all
+}
+
+// Removes the specified element from this set for good if it is present.
+// Returns true if it was.
+func (t *TwoPSet) Remove(e interface{}) bool {
+	if !t.Contains(e) {
+		return false
+	}
+	t.removed.Add(e)
+	return true
+}
+
+// Returns true if this set contains the specified element.
+func (t *TwoPSet) Contains(e interface{}) bool {
+	return t.added.Contains(e) && !t.removed.Contains(e)
+}
+
+// Return the number of elements in the set.
+func (t *TwoPSet) Len() uint32 {
+	return uint32(t.Elements().Len())
+}
+
+// Returns a copy of the elements of the set.
+func (t *TwoPSet) Elements() set.Set {
+	return t.added.elements.Difference(t.removed.elements)
+}
+
+// Merge the states or deltas of other replicas into this one.
+func (t *TwoPSet) Merge(others ...*TwoPSet) {
+	for _, o := range others {
+		t.added.Merge(o.added)
+		t.removed.Merge(o.removed)
+	}
+}
+
+// Returns the elements added and removed on this replica since the last
+// call, as a TwoPSet to merge into other replicas, and starts a new delta.
+func (t *TwoPSet) Delta() *TwoPSet {
+	return &TwoPSet{added: t.added.Delta(), removed: t.removed.Delta()}
+}
+
+// Returns a deep clone of the set, sharing no state with it.
+func (t *TwoPSet) Clone() *TwoPSet {
+	return &TwoPSet{added: t.added.Clone(), removed: t.removed.Clone()}
+}
+
+// Test whether the set and other have the same state, tombstones included.
+func (t *TwoPSet) Equal(other *TwoPSet) bool {
+	return t.added.Equal(other.added) && t.removed.Equal(other.removed)
+}
+
+// Encode the set as a JSON object holding the added and the removed
+// elements.
+func (t *TwoPSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(twoPSetJSON{t.added, t.removed})
+}
+
+// Replaces the set with one decoded from the form written by MarshalJSON.
+// The delta is reset.
+func (t *TwoPSet) UnmarshalJSON(data []byte) error {
+	v := twoPSetJSON{NewGSet(), NewGSet()}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	t.added, t.removed = v.Added, v.Removed
+	return nil
+}
+
+// Encode the set as a version byte followed by the added and the removed
+// elements, each in the binary form of set.HashSet prefixed by its length.
+func (t *TwoPSet) MarshalBinary() ([]byte, error) {
+	b := []byte{version}
+	for _, g := range []*GSet{t.added, t.removed} {
+		data, err := g.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		b = append(binary.AppendUvarint(b, uint64(len(data))), data...)
+	}
+	return b, nil
+}
+
+// Replaces the set with one decoded from the form written by
+// MarshalBinary. The delta is reset.
+func (t *TwoPSet) UnmarshalBinary(data []byte) error {
+	r, err := readVersion(data)
+	if err != nil {
+		return err
+	}
+	added, removed := NewGSet(), NewGSet()
+	for _, g := range []*GSet{added, removed} {
+		part := r.Next(r.Count(1))
+		if r.Err() != nil {
+			break
+		}
+		if err := g.UnmarshalBinary(part); err != nil {
+			return err
+		}
+	}
+	if err := r.Close(); err != nil {
+		return fmt.Errorf("crdt: %w", err)
+	}
+	t.added, t.removed = added, removed
+	return nil
+}
@@ -0,0 +1,111 @@
+package crdt
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/billryan/collections/set"
+)
+
+func TestTwoPSet_Remove(t *testing.T) {
+	s := NewTwoPSet(1, 2)
+	if s.Remove(3) {
+		t.Errorf("Removing an absent element should return false")
+	}
+	if !s.Remove(1) || s.Contains(1) || s.Len() != 1 {
+		t.Errorf("1 should be removed, got %v", s.Elements())
+	}
+	if s.Add(1) || s.Contains(1) {
+		t.Errorf("A removed element should not come back")
+	}
+	if !s.AddAll(3, 4) || s.AddAll(5, 1) {
+		t.Errorf("AddAll should report tombstoned elements")
+	}
+	if s.Len() != 4 {
+		t.Errorf("Set should be {2, 3, 4, 5}, got %v", s.Elements())
+	}
+}
+
+func TestTwoPSet_Merge(t *testing.T) {
+	a := NewTwoPSet(1, 2)
+	b := a.Clone()
+	a.Delta()
+	b.Delta()
+
+	// A remove wins over a concurrent add
+	a.Remove(1)
+	b.Add(3)
+	b.Remove(2)
+	da, db := a.Delta(), b.Delta()
+	a.Merge(db)
+	b.Merge(da)
+	if !a.Equal(b) || a.Len() != 1 || !a.Contains(3) {
+		t.Errorf("Replicas should converge to {3}, got %v and %v", a.Elements(), b.Elements())
+	}
+}
+
+func TestTwoPSet_Encoding(t *testing.T) {
+	s := NewTwoPSet(1, "a", 2)
+	s.Remove(2)
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"added":[1,2,"a"],"removed":[2]}` {
+		t.Errorf("Got %s", data)
+	}
+	j := NewTwoPSet()
+	if err := json.Unmarshal(data, j); err != nil || !j.Equal(s) {
+		t.Errorf("JSON round trip got %v, %v", j.Elements(), err)
+	}
+
+	data, err = s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewTwoPSet()
+	if err := b.UnmarshalBinary(data); err != nil || !b.Equal(s) {
+		t.Errorf("Binary round trip got %v, %v", b.Elements(), err)
+	}
+	for i := range data {
+		if err := b.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("Data truncated to %d bytes should fail", i)
+		}
+	}
+	if err := b.UnmarshalBinary(append(data, 0)); err == nil {
+		t.Errorf("Trailing data should fail")
+	}
+	if !b.Equal(s) {
+		t.Errorf("Failed decoding should leave the set alone")
+	}
+}
+
+// Replicas adding and removing random elements and exchanging states and
+// deltas in random order, some of them twice, should all converge to the
+// elements added and never removed once every message is delivered.
+func TestTwoPSet_Convergence(t *testing.T) {
+	for seed := uint64(0); seed < 20; seed++ {
+		t.Run(fmt.Sprint("seed ", seed), func(t *testing.T) {
+			replicas := []*TwoPSet{NewTwoPSet(), NewTwoPSet(), NewTwoPSet(), NewTwoPSet()}
+			added, removed := set.NewHashSet(), set.NewHashSet()
+			simulate(seed, replicas, func(r *rand.Rand, s *TwoPSet) {
+				if e := r.IntN(20); r.IntN(4) == 0 {
+					if s.Remove(e) {
+						removed.Add(e)
+					}
+				} else if s.Add(e) {
+					added.Add(e)
+				}
+			})
+
+			want := added.Difference(removed)
+			for i, s := range replicas {
+				if !s.Equal(replicas[0]) || !set.Equal(s.Elements(), want) {
+					t.Fatalf("Replica %d has %v, want %v", i, s.Elements().ToSlice(), want.ToSlice())
+				}
+			}
+		})
+	}
+}
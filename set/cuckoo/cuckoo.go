@@ -0,0 +1,287 @@
+// Package cuckoo provides a cuckoo filter: a compact, probabilistic set
+// that, unlike a Bloom filter, supports removing elements. It stores a short
+// fingerprint of each element in one of two candidate buckets, moving
+// fingerprints between their buckets to make room. Contains never misses an
+// element that was added, but may report one that was not with a
+// probability of about 2 × bucket size / 2^fingerprint bits.
+package cuckoo
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+
+	"github.com/billryan/collections/internal/hashing"
+)
+
+type (
+	// Filter is a cuckoo filter. Adding an element twice stores it twice,
+	// and it then takes two calls to Remove to forget it.
+	Filter struct {
+		slots       []uint64
+		buckets     uint64
+		bucketSize  uint
+		fingerprint uint
+		count       uint
+		// Fingerprint evicted when the filter filled up, kept so that no
+		// element is lost
+		victim *entry
+	}
+
+	entry struct {
+		bucket uint64
+		fp     uint32
+	}
+)
+
+const (
+	// Default number of bits in a fingerprint and of fingerprints in a bucket
+	DefaultFingerprintBits = 16
+	DefaultBucketSize      = 4
+	// Most fingerprints moved while adding an element before giving up
+	maxKicks = 500
+)
+
+// ErrFull is returned when an element cannot be added because the filter
+// has no room left for it.
+var ErrFull = errors.New("cuckoo: filter is full")
+
+// Create a filter for capacity elements using fingerprints of
+// DefaultFingerprintBits and buckets of DefaultBucketSize.
+func New(capacity uint) *Filter {
+	return NewWithSize(capacity, DefaultFingerprintBits, DefaultBucketSize)
+}
+
+// Create a filter for capacity elements using fingerprints of
+// fingerprintBits, from 1 to 32, and buckets of bucketSize fingerprints.
+// Panics if either is out of range.
+func NewWithSize(capacity, fingerprintBits, bucketSize uint) *Filter {
+	if fingerprintBits < 1 || fingerprintBits > 32 {
+		panic(fmt.Sprintf("cuckoo: fingerprint of %d bits out of [1, 32]", fingerprintBits))
+	}
+	if bucketSize < 1 {
+		panic("cuckoo: buckets must hold at least 1 fingerprint")
+	}
+	// The other bucket of a fingerprint is found by XOR, which needs a
+	// power of two number of buckets.
+	capacity = max(capacity, 1)
+	want := (uint64(capacity) + uint64(bucketSize) - 1) / uint64(bucketSize)
+	buckets := uint64(1) << bits.Len64(want-1)
+	if float64(capacity) > maxLoad(bucketSize)*float64(buckets*uint64(bucketSize)) {
+		buckets *= 2
+	}
+	slots := buckets * uint64(bucketSize)
+	return &Filter{
+		slots:       make([]uint64, (slots*uint64(fingerprintBits)+63)/64),
+		buckets:     buckets,
+		bucketSize:  bucketSize,
+		fingerprint: fingerprintBits,
+	}
+}
+
+// Adds the specified element to this filter. Returns ErrFull, leaving the
+// filter unchanged, if there is no room for it.
+func (f *Filter) Add(e interface{}) error {
+	if f.victim != nil {
+		return ErrFull
+	}
+	f.place(f.locate(e))
+	f.count++
+	return nil
+}
+
+// Adds all of the elements to this filter, stopping at the first one that
+// does not fit.
+func (f *Filter) AddAll(es ...interface{}) error {
+	for _, e := range es {
+		if err := f.Add(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns true if the element may have been added to this filter, and
+// false if it certainly was not.
+func (f *Filter) Contains(e interface{}) bool {
+	i, fp := f.locate(e)
+	j := f.alternate(i, fp)
+	if f.victim != nil && f.victim.fp == fp && (f.victim.bucket == i || f.victim.bucket == j) {
+		return true
+	}
+	return f.find(i, fp) >= 0 || f.find(j, fp) >= 0
+}
+
+// Returns true if all of the elements may have been added to this filter.
+func (f *Filter) ContainsAll(es ...interface{}) bool {
+	for _, e := range es {
+		if !f.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Removes the specified element from this filter if it may be present.
+// Only remove elements that were added: removing one that was not may
+// remove another element sharing its fingerprint.
+func (f *Filter) Remove(e interface{}) bool {
+	i, fp := f.locate(e)
+	j := f.alternate(i, fp)
+	if f.victim != nil && f.victim.fp == fp && (f.victim.bucket == i || f.victim.bucket == j) {
+		f.victim = nil
+		f.count--
+		return true
+	}
+	for _, b := range [2]uint64{i, j} {
+		if slot := f.find(b, fp); slot >= 0 {
+			f.set(uint64(slot), 0)
+			f.count--
+			f.reinsertVictim()
+			return true
+		}
+	}
+	return false
+}
+
+// Removes all of the elements from this filter.
+func (f *Filter) Clear() {
+	clear(f.slots)
+	f.count = 0
+	f.victim = nil
+}
+
+// Return the number of elements in the filter.
+func (f *Filter) Len() uint32 {
+	return uint32(f.count)
+}
+
+// Returns true if this filter contains no elements.
+func (f *Filter) IsEmpty() bool {
+	return f.count == 0
+}
+
+// Return the number of fingerprints the filter has slots for.
+func (f *Filter) Cap() uint {
+	return uint(f.buckets) * f.bucketSize
+}
+
+// Return the share of slots in use. With buckets of 4 fingerprints,
+// filters usually fill up past 95%.
+func (f *Filter) LoadFactor() float64 {
+	used := f.count
+	if f.victim != nil {
+		used--
+	}
+	return float64(used) / float64(f.Cap())
+}
+
+// Get the share of slots a filter with buckets of the given size can
+// usually fill before an element finds no room
+func maxLoad(bucketSize uint) float64 {
+	switch bucketSize {
+	case 1:
+		return 0.5
+	case 2:
+		return 0.84
+	case 3:
+		return 0.9
+	case 4:
+		return 0.95
+	default:
+		return 0.98
+	}
+}
+
+// Get the first bucket and the fingerprint of an element
+func (f *Filter) locate(e interface{}) (uint64, uint32) {
+	h := hashing.Sum64(e)
+	fp := uint32(h>>32) & (1<<f.fingerprint - 1)
+	if fp == 0 {
+		// Zero marks empty slots
+		fp = 1
+	}
+	return h & (f.buckets - 1), fp
+}
+
+// Get the other bucket a fingerprint in bucket i may go to. Applied twice,
+// it gives back i.
+func (f *Filter) alternate(i uint64, fp uint32) uint64 {
+	return (i ^ hashing.Mix(uint64(fp))) & (f.buckets - 1)
+}
+
+// Put fp in a free slot of bucket i, if there is one
+func (f *Filter) insert(i uint64, fp uint32) bool {
+	slot := f.find(i, 0)
+	if slot < 0 {
+		return false
+	}
+	f.set(uint64(slot), fp)
+	return true
+}
+
+// Get the slot holding fp in bucket i, or -1
+func (f *Filter) find(i uint64, fp uint32) int64 {
+	first := i * uint64(f.bucketSize)
+	for slot := first; slot < first+uint64(f.bucketSize); slot++ {
+		if f.get(slot) == fp {
+			return int64(slot)
+		}
+	}
+	return -1
+}
+
+// Put fp in bucket i or its alternate, kicking other fingerprints out to
+// their alternate bucket until one lands in a free slot. If none does, the
+// last one kicked out is kept aside as the victim.
+func (f *Filter) place(i uint64, fp uint32) {
+	if f.insert(i, fp) || f.insert(f.alternate(i, fp), fp) {
+		return
+	}
+	if rand.IntN(2) == 0 {
+		i = f.alternate(i, fp)
+	}
+	for kick := 0; kick < maxKicks; kick++ {
+		slot := i*uint64(f.bucketSize) + uint64(rand.IntN(int(f.bucketSize)))
+		evicted := f.get(slot)
+		f.set(slot, fp)
+		fp = evicted
+		i = f.alternate(i, fp)
+		if f.insert(i, fp) {
+			return
+		}
+	}
+	f.victim = &entry{i, fp}
+}
+
+// Try to find room for the victim again after a removal
+func (f *Filter) reinsertVictim() {
+	if v := f.victim; v != nil {
+		f.victim = nil
+		f.place(v.bucket, v.fp)
+	}
+}
+
+// Read the fingerprint in a slot. Fingerprints are packed, so one may
+// straddle two words.
+func (f *Filter) get(slot uint64) uint32 {
+	bit := slot * uint64(f.fingerprint)
+	w, off := bit/64, bit%64
+	v := f.slots[w] >> off
+	if off+uint64(f.fingerprint) > 64 {
+		v |= f.slots[w+1] << (64 - off)
+	}
+	return uint32(v) & (1<<f.fingerprint - 1)
+}
+
+// Write the fingerprint of a slot
+func (f *Filter) set(slot uint64, fp uint32) {
+	bit := slot * uint64(f.fingerprint)
+	w, off := bit/64, bit%64
+	mask := uint64(1)<<f.fingerprint - 1
+	f.slots[w] = f.slots[w]&^(mask<<off) | uint64(fp)<<off
+	if off+uint64(f.fingerprint) > 64 {
+		f.slots[w+1] = f.slots[w+1]&^(mask>>(64-off)) | uint64(fp)>>(64-off)
+	}
+}
@@ -0,0 +1,129 @@
+package cuckoo
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNewWithSize(t *testing.T) {
+	if f := NewWithSize(900, 12, 4); f.Cap() != 1024 {
+		t.Errorf("900 elements in buckets of 4 should take 256 buckets, got %d slots", f.Cap())
+	}
+	if f := NewWithSize(1000, 12, 4); f.Cap() != 2048 {
+		t.Errorf("1000 elements would fill 256 buckets of 4 past 95%%, got %d slots", f.Cap())
+	}
+	for _, c := range [][2]uint{{0, 4}, {33, 4}, {8, 0}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Fingerprint of %d bits in buckets of %d should panic", c[0], c[1])
+				}
+			}()
+			NewWithSize(10, c[0], c[1])
+		}()
+	}
+}
+
+func TestFilter(t *testing.T) {
+	for _, size := range [][2]uint{{16, 4}, {8, 4}, {12, 2}, {7, 8}, {32, 1}} {
+		t.Run(fmt.Sprint(size[0], "x", size[1]), func(t *testing.T) {
+			f := NewWithSize(10000, size[0], size[1])
+			for i := 0; i < 10000; i++ {
+				if err := f.Add(i); err != nil {
+					t.Fatalf("Adding %d: %v", i, err)
+				}
+			}
+			if f.Len() != 10000 {
+				t.Errorf("Length should be 10000, got %d", f.Len())
+			}
+			for i := 0; i < 10000; i++ {
+				if !f.Contains(i) {
+					t.Fatalf("Filter should contain %d", i)
+				}
+			}
+
+			fp := 0
+			for i := 0; i < 100000; i++ {
+				if f.Contains(fmt.Sprint("absent-", i)) {
+					fp++
+				}
+			}
+			bound := 2 * float64(size[1]) / float64(uint64(1)<<size[0]) * 100000
+			if float64(fp) > 2*bound+10 {
+				t.Errorf("False positives should be about %.0f in 100000, got %d", bound, fp)
+			}
+
+			for i := 0; i < 10000; i += 2 {
+				if !f.Remove(i) {
+					t.Fatalf("Removing %d should succeed", i)
+				}
+			}
+			if f.Len() != 5000 {
+				t.Errorf("Length should be 5000, got %d", f.Len())
+			}
+			for i := 1; i < 10000; i += 2 {
+				if !f.Contains(i) {
+					t.Fatalf("Filter should still contain %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestFilter_Duplicates(t *testing.T) {
+	f := New(100)
+	f.AddAll("a", "a", "b")
+	if f.Len() != 3 {
+		t.Errorf("Duplicates should be stored twice, got length %d", f.Len())
+	}
+	f.Remove("a")
+	if !f.Contains("a") {
+		t.Errorf("One removal should leave the other copy of a")
+	}
+	f.Remove("a")
+	if f.Contains("a") || f.Remove("a") {
+		t.Errorf("Two removals should forget a")
+	}
+	f.Clear()
+	if !f.IsEmpty() || f.Contains("b") {
+		t.Errorf("Cleared filter should be empty")
+	}
+}
+
+func TestFilter_Full(t *testing.T) {
+	f := NewWithSize(64, 16, 4)
+	var err error
+	added := 0
+	for ; err == nil; added++ {
+		err = f.Add(added)
+	}
+	added--
+	if err != ErrFull {
+		t.Fatalf("Overfilling should fail with ErrFull, got %v", err)
+	}
+	if f.LoadFactor() < 0.8 || f.LoadFactor() > 1 {
+		t.Errorf("Filter should fill up past 80%%, got %v", f.LoadFactor())
+	}
+	if int(f.Len()) != added {
+		t.Errorf("Length should be %d, got %d", added, f.Len())
+	}
+	for i := 0; i < added; i++ {
+		if !f.Contains(i) {
+			t.Fatalf("A full filter should keep every element, missing %d", i)
+		}
+	}
+	if err := f.AddAll(added); err != ErrFull {
+		t.Errorf("A full filter should keep refusing elements, got %v", err)
+	}
+	for i := 0; i < 8; i++ {
+		f.Remove(i)
+	}
+	if err := f.Add("new"); err != nil {
+		t.Errorf("Removing elements should make room again, got %v", err)
+	}
+	for i := 8; i < added; i++ {
+		if !f.Contains(i) {
+			t.Fatalf("Filter should still contain %d", i)
+		}
+	}
+}
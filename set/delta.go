@@ -0,0 +1,193 @@
+package set
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/billryan/collections/internal/codec"
+)
+
+type (
+	// Delta is the change from one state of a set to another: the elements
+	// added and the elements removed. Shipping a delta instead of the whole
+	// set keeps the updates of a large, slowly changing set small.
+	//
+	// The zero value is an empty delta.
+	Delta struct {
+		Added   Set
+		Removed Set
+	}
+
+	deltaJSON struct {
+		Added   json.RawMessage `json:"added"`
+		Removed json.RawMessage `json:"removed"`
+	}
+)
+
+// Compute the delta turning old into new. Added has the implementation of
+// new and Removed that of old, so elements are compared as the sets
+// compare them.
+func Diff(old, new Set) Delta {
+	return Delta{Added: new.Difference(old), Removed: old.Difference(new)}
+}
+
+// Returns true if the delta changes nothing.
+func (d Delta) IsEmpty() bool {
+	return isEmpty(d.Added) && isEmpty(d.Removed)
+}
+
+// Applies the delta to s, removing the removed elements and adding the
+// added ones. Applying Diff(old, new) to a set equal to old makes it equal
+// to new.
+func (d Delta) Apply(s Set) {
+	if d.Removed != nil {
+		s.RemoveAll(d.Removed.ToSlice()...)
+	}
+	if d.Added != nil {
+		s.AddAll(d.Added.ToSlice()...)
+	}
+}
+
+// Returns the delta undoing this one.
+func (d Delta) Invert() Delta {
+	return Delta{Added: d.Removed, Removed: d.Added}
+}
+
+// Returns a delta applying this one and then next. An element added by one
+// and removed by the other cancels out, so composing Diff(a, b) and
+// Diff(b, c) gives Diff(a, c).
+func (d Delta) Compose(next Delta) Delta {
+	return Delta{
+		Added:   composeSide(d.Added, next.Removed, next.Added, d.Removed),
+		Removed: composeSide(d.Removed, next.Added, next.Removed, d.Added),
+	}
+}
+
+// Compose deltas in order. Composing no deltas gives an empty one.
+func ComposeAll(deltas ...Delta) Delta {
+	var d Delta
+	for _, next := range deltas {
+		d = d.Compose(next)
+	}
+	return d
+}
+
+// Get first minus undone, plus then minus cancelled. The result has the
+// implementation of first, or of then if first is nil.
+func composeSide(first, undone, then, cancelled Set) Set {
+	switch {
+	case first == nil && then == nil:
+		return nil
+	case first == nil:
+		return differenceOf(then, cancelled)
+	case then == nil:
+		return differenceOf(first, undone)
+	}
+	n := differenceOf(first, undone)
+	n.AddAll(differenceOf(then, cancelled).ToSlice()...)
+	return n
+}
+
+func differenceOf(s, other Set) Set {
+	if other == nil {
+		return s.Clone()
+	}
+	return s.Difference(other)
+}
+
+func isEmpty(s Set) bool {
+	return s == nil || s.IsEmpty()
+}
+
+func elementsOf(s Set) []interface{} {
+	if s == nil {
+		return []interface{}{}
+	}
+	return sortedElements(s)
+}
+
+// Encode the delta as a JSON object holding the sorted arrays of added and
+// removed elements.
+func (d Delta) MarshalJSON() ([]byte, error) {
+	added, err := json.Marshal(elementsOf(d.Added))
+	if err != nil {
+		return nil, err
+	}
+	removed, err := json.Marshal(elementsOf(d.Removed))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(deltaJSON{added, removed})
+}
+
+// Decodes a delta written by MarshalJSON into the sets of the delta,
+// creating HashSets for those that are nil. Numbers are decoded as
+// float64s, as encoding/json does.
+func (d *Delta) UnmarshalJSON(data []byte) error {
+	return d.UnmarshalJSONWith(data, DecodeAs[interface{}])
+}
+
+// Decodes a delta written by MarshalJSON as UnmarshalJSON does, decoding
+// each element with decode.
+func (d *Delta) UnmarshalJSONWith(data []byte, decode ElementDecoder) error {
+	var v deltaJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	var parts [2][]interface{}
+	for i, raw := range []json.RawMessage{v.Added, v.Removed} {
+		if raw == nil {
+			continue
+		}
+		es, err := decodeJSONElements(raw, decode)
+		if err != nil {
+			return err
+		}
+		parts[i] = es
+	}
+	d.fill(parts[0], parts[1])
+	return nil
+}
+
+// Encode the delta in a compact binary form: a version byte followed by
+// the added and the removed elements, each as a count and the elements in
+// the form of MarshalBinary of the sets.
+func (d Delta) MarshalBinary() ([]byte, error) {
+	b, err := appendElements([]byte{binaryVersion}, elementsOf(d.Added))
+	if err != nil {
+		return nil, err
+	}
+	return appendElements(b, elementsOf(d.Removed))
+}
+
+// Decodes a delta written by MarshalBinary into the sets of the delta,
+// creating HashSets for those that are nil.
+func (d *Delta) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errBinaryTruncated
+	}
+	if data[0] != binaryVersion {
+		return fmt.Errorf("set: unknown binary version %d", data[0])
+	}
+	r := codec.NewReader(data[1:])
+	added, removed := readElements(r), readElements(r)
+	if err := r.Close(); err != nil {
+		return fmt.Errorf("set: %w", err)
+	}
+	d.fill(added, removed)
+	return nil
+}
+
+// Replace the elements of the sets of the delta
+func (d *Delta) fill(added, removed []interface{}) {
+	d.Added, d.Removed = refill(d.Added, added), refill(d.Removed, removed)
+}
+
+func refill(s Set, es []interface{}) Set {
+	if s == nil {
+		s = NewHashSet()
+	}
+	s.Clear()
+	s.AddAll(es...)
+	return s
+}
@@ -0,0 +1,112 @@
+package set
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestDelta_DiffApply(t *testing.T) {
+	forEachPair(t, func(t *testing.T, newA, newB func(...interface{}) Set) {
+		old, new := newA(1, 2, 3, 5), newB(2, 3, 4, 6)
+		d := Diff(old, new)
+		if !Equal(d.Added, NewHashSet(4, 6)) || !Equal(d.Removed, NewHashSet(1, 5)) {
+			t.Errorf("Delta should add 4, 6 and remove 1, 5, got %v and %v", d.Added.ToSlice(), d.Removed.ToSlice())
+		}
+		s := newA(1, 2, 3, 5)
+		d.Apply(s)
+		if !Equal(s, new) {
+			t.Errorf("Applying the delta should give %v, got %v", new.ToSlice(), s.ToSlice())
+		}
+		d.Invert().Apply(s)
+		if !Equal(s, old) {
+			t.Errorf("Applying the inverse should give %v, got %v", old.ToSlice(), s.ToSlice())
+		}
+		if d.IsEmpty() || !Diff(old, newB(1, 2, 3, 5)).IsEmpty() {
+			t.Errorf("Only the delta between equal sets should be empty")
+		}
+	})
+}
+
+func TestDelta_Compose(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	random := func() Set {
+		s := NewHashSet()
+		for i := 0; i < 10; i++ {
+			if r.IntN(2) == 0 {
+				s.Add(i)
+			}
+		}
+		return s
+	}
+	for i := 0; i < 100; i++ {
+		a, b, c := random(), random(), random()
+		d := Diff(a, b).Compose(Diff(b, c))
+		if want := Diff(a, c); !Equal(d.Added, want.Added) || !Equal(d.Removed, want.Removed) {
+			t.Fatalf("Composing %v -> %v -> %v gave +%v -%v", a.ToSlice(), b.ToSlice(), c.ToSlice(), d.Added.ToSlice(), d.Removed.ToSlice())
+		}
+	}
+
+	var zero Delta
+	if !zero.IsEmpty() || !ComposeAll().IsEmpty() {
+		t.Errorf("The zero delta should be empty")
+	}
+	d := ComposeAll(zero, Delta{Added: NewHashSet(1)}, Delta{Removed: NewHashSet(2)}, zero)
+	if !Equal(d.Added, NewHashSet(1)) || !Equal(d.Removed, NewHashSet(2)) {
+		t.Errorf("Got +%v -%v, want +[1] -[2]", d.Added.ToSlice(), d.Removed.ToSlice())
+	}
+	s := NewHashSet(2, 3)
+	zero.Apply(s)
+	d.Apply(s)
+	if !Equal(s, NewHashSet(1, 3)) {
+		t.Errorf("Set should be 1, 3, got %v", s.ToSlice())
+	}
+}
+
+func TestDelta_Encoding(t *testing.T) {
+	d := Diff(NewHashSet(1, "b", 2.5), NewHashSet(3, "a", 2.5))
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"added":[3,"a"],"removed":[1,"b"]}` {
+		t.Errorf("Got %s", data)
+	}
+	var j Delta
+	if err := j.UnmarshalJSONWith(data, DecodeNumbers); err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(j.Added, d.Added) || !Equal(j.Removed, d.Removed) {
+		t.Errorf("JSON round trip gave +%v -%v", j.Added.ToSlice(), j.Removed.ToSlice())
+	}
+	if err := json.Unmarshal([]byte(`{"added":[1]}`), &j); err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(j.Added, NewHashSet(1.0)) || !j.Removed.IsEmpty() {
+		t.Errorf("Decoding should replace the delta, got +%v -%v", j.Added.ToSlice(), j.Removed.ToSlice())
+	}
+
+	data, err = d.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Decode into a set of the caller's choice
+	b := Delta{Added: NewLinkedHashSet()}
+	if err := b.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !Equal(b.Added, d.Added) || !Equal(b.Removed, d.Removed) {
+		t.Errorf("Binary round trip gave +%v -%v", b.Added.ToSlice(), b.Removed.ToSlice())
+	}
+	if _, ok := b.Added.(*LinkedHashSet); !ok {
+		t.Errorf("Decoding should keep the sets of the delta, got %T", b.Added)
+	}
+	for i := range data {
+		if err := b.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("Data truncated to %d bytes should fail", i)
+		}
+	}
+	if _, err := Diff(NewHashSet(), NewHashSet(struct{}{})).MarshalBinary(); err == nil {
+		t.Errorf("Encoding a struct should fail")
+	}
+}
@@ -0,0 +1,178 @@
+package set
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/billryan/collections/internal/codec"
+)
+
+// ElementDecoder decodes one element of a JSON array, letting the caller
+// choose the type elements are decoded to rather than the float64,
+// string, bool, []interface{} and map[string]interface{} of encoding/json.
+type ElementDecoder func(data []byte) (interface{}, error)
+
+// Version of the binary form
+const binaryVersion = 1
+
+var errBinaryTruncated = fmt.Errorf("set: %w", codec.ErrTruncated)
+
+// Decode an element as a T. Use it as DecodeAs[int] to read a JSON array of
+// integers as ints.
+func DecodeAs[T any](data []byte) (interface{}, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// Decode an element as encoding/json does, except that integers that fit
+// in an int are decoded as ints. Other numbers are float64s.
+func DecodeNumbers(data []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return fromNumbers(v), nil
+}
+
+// Replace the elements of s with those of a JSON array, decoding each
+// element with decode.
+func UnmarshalJSONWith(s Set, data []byte, decode ElementDecoder) error {
+	es, err := decodeJSONElements(data, decode)
+	if err != nil {
+		return err
+	}
+	s.Clear()
+	s.AddAll(es...)
+	return nil
+}
+
+// Decode the elements of a JSON array with decode
+func decodeJSONElements(data []byte, decode ElementDecoder) ([]interface{}, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	es := make([]interface{}, len(raw))
+	for i, r := range raw {
+		e, err := decode(r)
+		if err != nil {
+			return nil, err
+		}
+		es[i] = e
+	}
+	return es, nil
+}
+
+// Replace json.Numbers by ints where they fit and float64s otherwise
+func fromNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil && i == int64(int(i)) {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i := range v {
+			v[i] = fromNumbers(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = fromNumbers(v[k])
+		}
+	}
+	return v
+}
+
+// Get the elements of s in a deterministic order, so that equal sets
+// encode alike
+func sortedElements(s Set) []interface{} {
+	es := s.ToSlice()
+	slices.SortFunc(es, codec.Compare)
+	return es
+}
+
+// Sort typed elements in the order of sortedElements
+func sortElementsOf[T comparable](es []T) []T {
+	slices.SortFunc(es, func(a, b T) int { return codec.Compare(a, b) })
+	return es
+}
+
+// Encode the elements with gob, which keeps their types. Types other than
+// the basic ones must be registered with gob.Register.
+func gobEncode(es []interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(es)
+	return b.Bytes(), err
+}
+
+func gobDecode(data []byte) ([]interface{}, error) {
+	var es []interface{}
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&es)
+	return es, err
+}
+
+// Encode the elements in the compact binary form: a version byte and the
+// number of elements, a varint, followed by each element as a type tag and
+// its value. Integers are varints, floats little-endian and strings
+// prefixed by their length. Only nil and the basic types but complex
+// numbers and uintptr can be encoded.
+func marshalBinary(es []interface{}) ([]byte, error) {
+	return appendElements([]byte{binaryVersion}, es)
+}
+
+// Append the number of elements and the elements
+func appendElements(b []byte, es []interface{}) ([]byte, error) {
+	b = binary.AppendUvarint(b, uint64(len(es)))
+	for _, e := range es {
+		var err error
+		if b, err = codec.AppendElement(b, e); err != nil {
+			return nil, fmt.Errorf("set: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// Decode elements from the form written by marshalBinary
+func unmarshalBinary(data []byte) ([]interface{}, error) {
+	if len(data) == 0 {
+		return nil, errBinaryTruncated
+	}
+	if data[0] != binaryVersion {
+		return nil, fmt.Errorf("set: unknown binary version %d", data[0])
+	}
+	r := codec.NewReader(data[1:])
+	es := readElements(r)
+	if err := r.Close(); err != nil {
+		return nil, fmt.Errorf("set: %w", err)
+	}
+	return es, nil
+}
+
+// Read elements written by appendElements
+func readElements(r *codec.Reader) []interface{} {
+	// Every element takes at least a byte
+	es := make([]interface{}, r.Count(1))
+	for i := range es {
+		es[i] = r.Element()
+	}
+	return es
+}
+
+// Replace the elements of s with those decode gets from data, unless it
+// fails
+func decodeInto(s Set, data []byte, decode func([]byte) ([]interface{}, error)) error {
+	es, err := decode(data)
+	if err == nil {
+		s.Clear()
+		s.AddAll(es...)
+	}
+	return err
+}
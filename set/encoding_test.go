@@ -0,0 +1,263 @@
+package set
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+)
+
+func TestEncoding_JSONRoundTrip(t *testing.T) {
+	for _, impl := range implementations {
+		t.Run(impl.name, func(t *testing.T) {
+			s := impl.new(3, 1, 2)
+			data, err := json.Marshal(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "[1,2,3]"
+			if impl.name == "LinkedHashSet" {
+				// In insertion order
+				want = "[3,1,2]"
+			}
+			if string(data) != want {
+				t.Errorf("Got %s, want %s", data, want)
+			}
+			text, err := s.(encoding.TextMarshaler).MarshalText()
+			if err != nil || !bytes.Equal(text, data) {
+				t.Errorf("MarshalText gave %s, want %s", text, data)
+			}
+
+			n := impl.new(7)
+			if err := UnmarshalJSONWith(n, data, DecodeNumbers); err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(n, s) {
+				t.Errorf("Decoded %v, want %v", n.ToSlice(), s.ToSlice())
+			}
+		})
+	}
+}
+
+func TestEncoding_GobRoundTrip(t *testing.T) {
+	for _, impl := range implementations {
+		t.Run(impl.name, func(t *testing.T) {
+			s := impl.new(3, 1, 2)
+			var b bytes.Buffer
+			if err := gob.NewEncoder(&b).Encode(s); err != nil {
+				t.Fatal(err)
+			}
+			n := impl.new(7)
+			if err := gob.NewDecoder(&b).Decode(n); err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(n, s) {
+				t.Errorf("Decoded %v, want %v", n.ToSlice(), s.ToSlice())
+			}
+		})
+	}
+}
+
+func TestEncoding_BinaryRoundTrip(t *testing.T) {
+	for _, impl := range implementations {
+		t.Run(impl.name, func(t *testing.T) {
+			s := impl.new(3, 1, 2, 1000)
+			data, err := s.(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			n := impl.new(7)
+			if err := n.(encoding.BinaryUnmarshaler).UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if !Equal(n, s) {
+				t.Errorf("Decoded %v, want %v", n.ToSlice(), s.ToSlice())
+			}
+			if err := n.(encoding.BinaryUnmarshaler).UnmarshalBinary(data[:len(data)-1]); err == nil {
+				t.Error("Truncated data should fail")
+			}
+		})
+	}
+}
+
+func TestEncoding_KeepsTypes(t *testing.T) {
+	s := NewHashSet(nil, true, -7, int8(-1), int16(2), int32(3), int64(-4),
+		uint(5), uint8(6), uint16(7), uint32(8), uint64(1<<63), float32(1.5), 2.5, "x", "")
+	data, err := s.(*HashSet).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := &HashSet{}
+	if err := n.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !n.Equal(s) {
+		t.Errorf("Binary decoded %v, want %v", n.ToSlice(), s.ToSlice())
+	}
+
+	gobData, err := s.(*HashSet).GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &ConcurrentSet{}
+	if err := g.GobDecode(gobData); err != nil {
+		t.Fatal(err)
+	}
+	if !g.Equal(s) {
+		t.Errorf("Gob decoded %v, want %v", g.ToSlice(), s.ToSlice())
+	}
+}
+
+func TestEncoding_BinaryErrors(t *testing.T) {
+	if _, err := NewHashSet(struct{ X int }{1}).(*HashSet).MarshalBinary(); err == nil {
+		t.Error("Encoding a struct should fail")
+	}
+	s := NewHashSet(1)
+	abc, err := NewHashSet("abc").(*HashSet).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{nil, {9, 0}, {binaryVersion, 1, 99}, abc[:len(abc)-1], {binaryVersion, 0, 0}} {
+		if err := s.(*HashSet).UnmarshalBinary(data); err == nil {
+			t.Errorf("Decoding %v should fail", data)
+		}
+	}
+	if !Equal(s, NewHashSet(1)) {
+		t.Error("Failed decoding should leave the set unchanged")
+	}
+}
+
+func TestEncoding_Deterministic(t *testing.T) {
+	a := NewHashSet("b", 2, 1.5, true, nil, "a", int64(2), false)
+	b := NewConcurrentSet(false, int64(2), "a", nil, true, 1.5, 2, "b")
+	want := `[null,false,true,1.5,2,2,"a","b"]`
+	for _, s := range []Set{a, b} {
+		for i := 0; i < 5; i++ {
+			data, err := json.Marshal(s)
+			if err != nil || string(data) != want {
+				t.Fatalf("Got %s, want %s", data, want)
+			}
+		}
+	}
+	x, _ := a.(*HashSet).MarshalBinary()
+	y, _ := b.(*ConcurrentSet).MarshalBinary()
+	if !bytes.Equal(x, y) {
+		t.Error("Equal sets should encode alike")
+	}
+
+	type id string
+	data, err := NewHashSet(id("b"), "a", "b", id("a")).(*HashSet).MarshalText()
+	if want := `["a","a","b","b"]`; err != nil || string(data) != want {
+		t.Errorf("Named and unnamed strings should sort together, got %s, want %s", data, want)
+	}
+}
+
+func TestEncoding_Duplicates(t *testing.T) {
+	for _, impl := range implementations {
+		s := impl.new()
+		if err := UnmarshalJSONWith(s, []byte(`[1, 2, 2, 1]`), DecodeNumbers); err != nil {
+			t.Fatal(err)
+		}
+		if s.Len() != 2 {
+			t.Errorf("%s: length %d should be 2", impl.name, s.Len())
+		}
+	}
+
+	s := NewConcurrentSet()
+	if err := s.UnmarshalText([]byte(`["a", "b", "a"]`)); err != nil {
+		t.Fatal(err)
+	}
+	if s.Len() != 2 || len(s.ToSlice()) != 2 {
+		t.Errorf("Length %d should be 2", s.Len())
+	}
+}
+
+func TestEncoding_Decoders(t *testing.T) {
+	h := NewHasherSet(DeepHasher)
+	if err := UnmarshalJSONWith(h, []byte(`[1, 2.5, "x", [3], 1e100]`), DecodeNumbers); err != nil {
+		t.Fatal(err)
+	}
+	if !h.ContainsAll(1, 2.5, "x", []interface{}{3}, 1e100) || h.Len() != 5 {
+		t.Errorf("Got %v", h.ToSlice())
+	}
+
+	s := NewHashSet()
+	if err := UnmarshalJSONWith(s, []byte(`["a", "b"]`), DecodeAs[string]); err != nil {
+		t.Fatal(err)
+	}
+	if err := UnmarshalJSONWith(s, []byte(`[1, "b"]`), DecodeAs[int]); err == nil {
+		t.Error("A string is not an int")
+	}
+	if !Equal(s, NewHashSet("a", "b")) {
+		t.Error("Failed decoding should leave the set unchanged")
+	}
+}
+
+func TestEncoding_ZeroValues(t *testing.T) {
+	var config struct {
+		Hash    HashSet
+		Sharded ShardedSet
+		Deep    HasherSet
+		Linked  LinkedHashSet
+		Bits    BitSet
+		Roaring RoaringBitmap
+		Typed   HashSetOf[int]
+	}
+	doc := `{"Hash": ["a"], "Sharded": ["b"], "Deep": [[1]], "Linked": ["d", "c"],
+		"Bits": [5], "Roaring": [6], "Typed": [7]}`
+	if err := json.Unmarshal([]byte(doc), &config); err != nil {
+		t.Fatal(err)
+	}
+	if !config.Hash.Contains("a") || !config.Sharded.Contains("b") ||
+		!config.Deep.Contains([]interface{}{1.0}) || !config.Bits.Contains(5) ||
+		!config.Roaring.Test(6) || !config.Typed.Contains(7) {
+		t.Error("Decoded sets should hold their elements")
+	}
+
+	data, err := json.Marshal(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Hash":["a"],"Sharded":["b"],"Deep":[[1]],"Linked":["d","c"],"Bits":[5],"Roaring":[6],"Typed":[7]}`
+	if string(data) != want {
+		t.Errorf("Got %s, want %s", data, want)
+	}
+
+	var sorted SortedSet
+	if err := sorted.UnmarshalJSON([]byte(`[1]`)); err == nil {
+		t.Error("A SortedSet without a less function cannot be decoded into")
+	}
+}
+
+func TestEncoding_Typed(t *testing.T) {
+	for _, s := range []Of[int]{NewHashSetOf(3, 1, 2), NewConcurrentSetOf(2, 3, 1)} {
+		data, err := json.Marshal(s)
+		if err != nil || string(data) != "[1,2,3]" {
+			t.Fatalf("Got %s, want [1,2,3]", data)
+		}
+		n := NewConcurrentSetOf[int]()
+		if err := json.Unmarshal(data, n); err != nil {
+			t.Fatal(err)
+		}
+		if n.Len() != 3 || !n.ContainsAll(1, 2, 3) {
+			t.Errorf("Decoded %v", n.ToSlice())
+		}
+	}
+}
+
+func TestEncoding_Observable(t *testing.T) {
+	s := NewObservableSet(NewHashSet("b", "a"))
+	events := 0
+	s.Subscribe(func(Event) { events++ })
+	data, err := json.Marshal(s)
+	if err != nil || string(data) != `["a","b"]` {
+		t.Fatalf(`Got %s, want ["a","b"]`, data)
+	}
+	if err := json.Unmarshal([]byte(`["c"]`), s); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Equal(NewHashSet("c")) || events != 2 {
+		t.Errorf("Decoded %v with %d events", s.ToSlice(), events)
+	}
+}
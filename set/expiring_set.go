@@ -0,0 +1,534 @@
+package set
+
+import (
+	"container/heap"
+	"encoding/json"
+	"iter"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/billryan/collections"
+)
+
+type (
+	// ExpiringSet is a Set whose elements expire a time to live after they
+	// were added. Expired elements are no longer reported as present, and
+	// are evicted lazily by the next Add, Len or listing, or by Expire.
+	// No goroutine runs in the background. The set can also be bounded in
+	// size, evicting the element closest to expiry to make room. It is safe
+	// for concurrent use.
+	ExpiringSet struct {
+		mu      sync.Mutex
+		entries map[interface{}]*expiry
+		// Entries ordered by deadline, the soonest first
+		queue   expiryQueue
+		options ExpiringSetOptions
+		// Counter ordering entries of the same deadline by when they were
+		// added
+		seq uint64
+	}
+
+	// ExpiringSetOptions configures an ExpiringSet.
+	ExpiringSetOptions struct {
+		// Time to live of the elements added by Add. Elements added with a
+		// time to live of zero or less never expire.
+		TTL time.Duration
+		// Largest number of elements, or zero for no bound. Adding an element
+		// to a full set evicts the element closest to expiry, the oldest
+		// first among elements that never expire.
+		MaxLen int
+		// Clock giving the current time, time.Now if nil. Tests can pass a
+		// fake one.
+		Now func() time.Time
+		// Called with each element evicted because it expired or to make
+		// room, after the set is unlocked, so it may use the set. Elements
+		// removed by Remove, RemoveAll, Clear or a decode are not reported.
+		OnEvict func(e interface{}, reason EvictReason)
+	}
+
+	// EvictReason tells why an element was evicted from an ExpiringSet.
+	EvictReason uint8
+
+	expiry struct {
+		e interface{}
+		// Deadline of the element, zero if it never expires
+		at    time.Time
+		seq   uint64
+		index int
+	}
+
+	expiryQueue []*expiry
+
+	eviction struct {
+		e      interface{}
+		reason EvictReason
+	}
+)
+
+const (
+	// The element outlived its time to live.
+	Expired EvictReason = iota
+	// The element made room for another in a full set.
+	Capacity
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case Expired:
+		return "Expired"
+	case Capacity:
+		return "Capacity"
+	}
+	return "EvictReason(?)"
+}
+
+// Create a new expiring set whose elements live for ttl
+func NewExpiringSet(ttl time.Duration, initial ...interface{}) *ExpiringSet {
+	return NewExpiringSetWithOptions(ExpiringSetOptions{TTL: ttl}, initial...)
+}
+
+// Create a new expiring set configured by options
+func NewExpiringSetWithOptions(options ExpiringSetOptions, initial ...interface{}) *ExpiringSet {
+	if options.Now == nil {
+		options.Now = time.Now
+	}
+	s := &ExpiringSet{entries: make(map[interface{}]*expiry), options: options}
+
+	for _, v := range initial {
+		s.Add(v)
+	}
+
+	return s
+}
+
+// Adds the specified element to this set if it is not already present (optional operation).
+// The element expires after the TTL of the set. Adding a present element
+// restarts its time to live.
+func (s *ExpiringSet) Add(e interface{}) {
+	s.AddWithTTL(e, s.options.TTL)
+}
+
+// Adds the specified element to this set, expiring after ttl, or never if
+// ttl is zero or less. Adding a present element replaces its time to live.
+func (s *ExpiringSet) AddWithTTL(e interface{}, ttl time.Duration) {
+	s.mu.Lock()
+	evicted := s.expire(nil)
+	evicted = s.add(e, ttl, evicted)
+	s.mu.Unlock()
+	s.notify(evicted)
+}
+
+// Adds all of the elements to this set if they're not already present (optional operation).
+func (s *ExpiringSet) AddAll(es ...interface{}) {
+	s.mu.Lock()
+	evicted := s.expire(nil)
+	for _, e := range es {
+		evicted = s.add(e, s.options.TTL, evicted)
+	}
+	s.mu.Unlock()
+	s.notify(evicted)
+}
+
+// Removes all of the elements from this set (optional operation).
+func (s *ExpiringSet) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[interface{}]*expiry)
+	s.queue = nil
+}
+
+// Returns true if this set contains the specified element and it has not
+// expired.
+func (s *ExpiringSet) Contains(e interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x, exist := s.entries[e]
+	return exist && s.live(x, s.options.Now())
+}
+
+// Returns true if this set contains all of the elements of the specified collection.
+func (s *ExpiringSet) ContainsAll(es ...interface{}) bool {
+	for _, e := range es {
+		if !s.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the time the element expires at, the zero time if it never
+// does, and false if it is not present.
+func (s *ExpiringSet) ExpiresAt(e interface{}) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x, exist := s.entries[e]
+	if !exist || !s.live(x, s.options.Now()) {
+		return time.Time{}, false
+	}
+	return x.at, true
+}
+
+// Evicts the expired elements, returning how many there were.
+func (s *ExpiringSet) Expire() int {
+	s.mu.Lock()
+	evicted := s.expire(nil)
+	s.mu.Unlock()
+	s.notify(evicted)
+	return len(evicted)
+}
+
+// Call f for each item in the set
+func (s *ExpiringSet) Foreach(f func(interface{})) {
+	for _, e := range s.ToSlice() {
+		f(e)
+	}
+}
+
+// Call f for each item in the set until it returns false. It visits the
+// elements that were live when it started.
+func (s *ExpiringSet) Do(f func(interface{}) bool) {
+	for _, e := range s.ToSlice() {
+		if !f(e) {
+			return
+		}
+	}
+}
+
+// Returns a sequence over the elements in this set.
+func (s *ExpiringSet) All() iter.Seq[interface{}] {
+	return s.Do
+}
+
+// Returns an iterator over a snapshot of the elements in this set.
+func (s *ExpiringSet) Iterator() collections.Iterator {
+	return newIterator(s.ToSlice())
+}
+
+// Call f for each item in the set, set result as new key. The results live
+// for the TTL of the set from now.
+func (s *ExpiringSet) Map(f func(interface{}) interface{}) Set {
+	n := s.empty()
+	s.Foreach(func(e interface{}) {
+		n.Add(f(e))
+	})
+	return n
+}
+
+// Returns true if this set contains no elements.
+func (s *ExpiringSet) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+// Removes the specified element from this set if it is present (optional operation).
+func (s *ExpiringSet) Remove(e interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remove(e)
+}
+
+// Removes the specified elements from this set if it is present (optional operation).
+// Return true if all element exist.
+func (s *ExpiringSet) RemoveAll(es ...interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existAll := true
+	for _, e := range es {
+		if !s.remove(e) {
+			existAll = false
+		}
+	}
+	return existAll
+}
+
+// Return the number of elements in set s (cardinality of s).
+func (s *ExpiringSet) Len() uint32 {
+	s.mu.Lock()
+	evicted := s.expire(nil)
+	n := len(s.entries)
+	s.mu.Unlock()
+	s.notify(evicted)
+	return uint32(n)
+}
+
+// Returns an slice containing all of the elements in this set, soonest to
+// expire first.
+func (s *ExpiringSet) ToSlice() []interface{} {
+	s.mu.Lock()
+	evicted := s.expire(nil)
+	slice := make([]interface{}, 0, len(s.entries))
+	for _, x := range s.sorted() {
+		slice = append(slice, x.e)
+	}
+	s.mu.Unlock()
+	s.notify(evicted)
+	return slice
+}
+
+// Returns a deep clone of set. The elements keep their deadlines, and the
+// clone has the options of the set but for OnEvict.
+func (s *ExpiringSet) Clone() Set {
+	n := s.empty()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.options.Now()
+	for _, x := range s.sorted() {
+		if s.live(x, now) {
+			n.push(x.e, x.at)
+		}
+	}
+	return n
+}
+
+// Return a new set with elements common to the set and all others.
+// The elements of the result live for the TTL of the set from now.
+func (s *ExpiringSet) Intersection(others ...Set) Set {
+	return intersection(s.empty(), s, others)
+}
+
+// Return a new set with elements from the set and all others.
+// The elements of the result live for the TTL of the set from now.
+func (s *ExpiringSet) Union(others ...Set) Set {
+	return union(s.empty(), s, others)
+}
+
+// Return a new set with elements in the set that are not in the others.
+// The elements of the result live for the TTL of the set from now.
+func (s *ExpiringSet) Difference(others ...Set) Set {
+	return difference(s.empty(), s, others)
+}
+
+// Return a new set with elements in either the set or other but not both.
+// The elements of the result live for the TTL of the set from now.
+func (s *ExpiringSet) SymmetricDifference(other Set) Set {
+	return symmetricDifference(s.empty(), s, other)
+}
+
+// Test whether the set and other contain the same elements.
+func (s *ExpiringSet) Equal(other Set) bool {
+	return equal(s, other)
+}
+
+// Test whether the set has no elements in common with other.
+func (s *ExpiringSet) IsDisjoint(other Set) bool {
+	return isDisjoint(s, other)
+}
+
+// Test whether every element in the set is in other. set <= other
+func (s *ExpiringSet) IsSubset(other Set) bool {
+	return isSubset(s, other)
+}
+
+// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+func (s *ExpiringSet) IsProperSubset(other Set) bool {
+	return s.Len() < other.Len() && s.IsSubset(other)
+}
+
+// Test whether every element in other is in the set. set >= other
+func (s *ExpiringSet) IsSuperset(other Set) bool {
+	return other.IsSubset(s)
+}
+
+// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+func (s *ExpiringSet) IsProperSuperset(other Set) bool {
+	return s.Len() > other.Len() && s.IsSuperset(other)
+}
+
+// Replaces the elements of the set with those of a JSON array. They live
+// for the TTL of the set from now.
+func (s *ExpiringSet) UnmarshalText(text []byte) error {
+	s.lazyInit()
+	var v []interface{}
+	err := json.Unmarshal(text, &v)
+	if err == nil {
+		s.Clear()
+		s.AddAll(v...)
+	}
+	return err
+}
+
+// Encode the set as a JSON array, sorted so that equal sets encode alike.
+// Deadlines are not encoded.
+func (s *ExpiringSet) MarshalText() ([]byte, error) {
+	return json.Marshal(sortedElements(s))
+}
+
+// Encode the set as a JSON array, as MarshalText does.
+func (s *ExpiringSet) MarshalJSON() ([]byte, error) {
+	return s.MarshalText()
+}
+
+// Replaces the elements of the set with those of a JSON array.
+func (s *ExpiringSet) UnmarshalJSON(data []byte) error {
+	return s.UnmarshalText(data)
+}
+
+// Encode the set with gob, which keeps the types of the elements. Types
+// other than the basic ones must be registered with gob.Register.
+// Deadlines are not encoded.
+func (s *ExpiringSet) GobEncode() ([]byte, error) {
+	return gobEncode(sortedElements(s))
+}
+
+// Replaces the elements of the set with those encoded by GobEncode. They
+// live for the TTL of the set from now.
+func (s *ExpiringSet) GobDecode(data []byte) error {
+	s.lazyInit()
+	return decodeInto(s, data, gobDecode)
+}
+
+// Encode the set in a compact binary form. Only nil and basic types other
+// than complex numbers and uintptr can be encoded. Deadlines are not
+// encoded.
+func (s *ExpiringSet) MarshalBinary() ([]byte, error) {
+	return marshalBinary(sortedElements(s))
+}
+
+// Replaces the elements of the set with those encoded by MarshalBinary.
+// They live for the TTL of the set from now.
+func (s *ExpiringSet) UnmarshalBinary(data []byte) error {
+	s.lazyInit()
+	return decodeInto(s, data, unmarshalBinary)
+}
+
+// Give a zero ExpiringSet its map and clock, so that it can be decoded into.
+// Its elements never expire.
+func (s *ExpiringSet) lazyInit() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[interface{}]*expiry)
+	}
+	if s.options.Now == nil {
+		s.options.Now = time.Now
+	}
+}
+
+// Add or refresh e, evicting the element closest to expiry if the set is
+// full. Must be called with s.mu held.
+func (s *ExpiringSet) add(e interface{}, ttl time.Duration, evicted []eviction) []eviction {
+	var at time.Time
+	if ttl > 0 {
+		at = s.options.Now().Add(ttl)
+	}
+	if x, exist := s.entries[e]; exist {
+		s.seq++
+		x.at, x.seq = at, s.seq
+		heap.Fix(&s.queue, x.index)
+		return evicted
+	}
+	if s.options.MaxLen > 0 && len(s.entries) >= s.options.MaxLen {
+		x := heap.Pop(&s.queue).(*expiry)
+		delete(s.entries, x.e)
+		evicted = append(evicted, eviction{x.e, Capacity})
+	}
+	s.push(e, at)
+	return evicted
+}
+
+// Add e expiring at at, which must be absent. Must be called with s.mu
+// held.
+func (s *ExpiringSet) push(e interface{}, at time.Time) {
+	s.seq++
+	x := &expiry{e: e, at: at, seq: s.seq}
+	s.entries[e] = x
+	heap.Push(&s.queue, x)
+}
+
+// Must be called with s.mu held.
+func (s *ExpiringSet) remove(e interface{}) bool {
+	x, exist := s.entries[e]
+	if !exist {
+		return false
+	}
+	delete(s.entries, e)
+	heap.Remove(&s.queue, x.index)
+	return s.live(x, s.options.Now())
+}
+
+// Evict the expired elements, appending them to evicted. Must be called
+// with s.mu held.
+func (s *ExpiringSet) expire(evicted []eviction) []eviction {
+	now := s.options.Now()
+	for len(s.queue) > 0 && !s.live(s.queue[0], now) {
+		x := heap.Pop(&s.queue).(*expiry)
+		delete(s.entries, x.e)
+		evicted = append(evicted, eviction{x.e, Expired})
+	}
+	return evicted
+}
+
+// Report evictions to the callback. Must be called without s.mu held.
+func (s *ExpiringSet) notify(evicted []eviction) {
+	if s.options.OnEvict == nil {
+		return
+	}
+	for _, ev := range evicted {
+		s.options.OnEvict(ev.e, ev.reason)
+	}
+}
+
+// Test whether x has not expired by now
+func (s *ExpiringSet) live(x *expiry, now time.Time) bool {
+	return x.at.IsZero() || now.Before(x.at)
+}
+
+// Get the entries in the order of the queue. Must be called with s.mu held.
+func (s *ExpiringSet) sorted() []*expiry {
+	sorted := slices.Clone(s.queue)
+	slices.SortFunc(sorted, func(a, b *expiry) int {
+		if a.before(b) {
+			return -1
+		}
+		return 1
+	})
+	return sorted
+}
+
+// Create an empty set with the options of s but for OnEvict
+func (s *ExpiringSet) empty() *ExpiringSet {
+	options := s.options
+	options.OnEvict = nil
+	return NewExpiringSetWithOptions(options)
+}
+
+func (q expiryQueue) Len() int {
+	return len(q)
+}
+
+func (q expiryQueue) Less(i, j int) bool {
+	return q[i].before(q[j])
+}
+
+// Test whether a comes before b in the queue. Entries that never expire
+// come last, and entries of the same deadline in the order they were added.
+func (a *expiry) before(b *expiry) bool {
+	switch {
+	case a.at.IsZero() != b.at.IsZero():
+		return b.at.IsZero()
+	case !a.at.Equal(b.at):
+		return a.at.Before(b.at)
+	}
+	return a.seq < b.seq
+}
+
+func (q expiryQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *expiryQueue) Push(x interface{}) {
+	e := x.(*expiry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *expiryQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
@@ -0,0 +1,203 @@
+package set
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newExpiringSet(initial ...interface{}) Set {
+	return NewExpiringSet(time.Hour, initial...)
+}
+
+// A clock that only moves when told to
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestExpiringSet_Expiry(t *testing.T) {
+	clock := newFakeClock()
+	type evicted struct {
+		e      interface{}
+		reason EvictReason
+	}
+	var evictions []evicted
+	s := NewExpiringSetWithOptions(ExpiringSetOptions{
+		TTL: time.Minute,
+		Now: clock.Now,
+		OnEvict: func(e interface{}, reason EvictReason) {
+			evictions = append(evictions, evicted{e, reason})
+		},
+	}, "a")
+	s.AddWithTTL("b", 2*time.Minute)
+	s.AddWithTTL("forever", 0)
+
+	if at, ok := s.ExpiresAt("a"); !ok || !at.Equal(clock.Now().Add(time.Minute)) {
+		t.Errorf("a should expire in a minute, got %v", at)
+	}
+	if at, ok := s.ExpiresAt("forever"); !ok || !at.IsZero() {
+		t.Error("forever should never expire")
+	}
+
+	clock.advance(time.Minute)
+	if s.Contains("a") || !s.ContainsAll("b", "forever") {
+		t.Error("Only a should have expired")
+	}
+	if _, ok := s.ExpiresAt("a"); ok {
+		t.Error("An expired element should have no deadline")
+	}
+	if len(evictions) != 0 {
+		t.Error("Contains should not evict")
+	}
+	if s.Len() != 2 || len(evictions) != 1 || evictions[0] != (evicted{"a", Expired}) {
+		t.Errorf("Len should evict a, got length %d and %v", s.Len(), evictions)
+	}
+
+	// Re-adding restarts the time to live
+	clock.advance(90 * time.Second)
+	s.Add("b")
+	clock.advance(45 * time.Second)
+	if !s.Contains("b") {
+		t.Error("b should have been refreshed")
+	}
+	clock.advance(time.Hour)
+	if n := s.Expire(); n != 1 || !slices.Equal(s.ToSlice(), []interface{}{"forever"}) {
+		t.Errorf("Expire evicted %d leaving %v", n, s.ToSlice())
+	}
+	if s.Remove("b") || !s.Remove("forever") || !s.IsEmpty() {
+		t.Error("Remove should only find forever")
+	}
+}
+
+func TestExpiringSet_MaxLen(t *testing.T) {
+	clock := newFakeClock()
+	var evicted []interface{}
+	s := NewExpiringSetWithOptions(ExpiringSetOptions{
+		TTL:    time.Minute,
+		MaxLen: 3,
+		Now:    clock.Now,
+		OnEvict: func(e interface{}, reason EvictReason) {
+			if reason != Capacity {
+				t.Errorf("%v evicted as %v", e, reason)
+			}
+			evicted = append(evicted, e)
+		},
+	})
+	s.AddWithTTL(1, 0)
+	s.AddWithTTL(2, 0)
+	s.AddWithTTL(3, time.Hour)
+	s.AddAll(4, 5)
+	// 3 expires first, then 4 in a minute
+	if !slices.Equal(evicted, []interface{}{3, 4}) {
+		t.Errorf("Evicted %v, want 3, 4", evicted)
+	}
+	if !slices.Equal(s.ToSlice(), []interface{}{5, 1, 2}) {
+		t.Errorf("Got %v, want 5, 1, 2 soonest to expire first", s.ToSlice())
+	}
+	// Among elements that never expire, the oldest goes first
+	s.RemoveAll(5)
+	s.AddWithTTL(6, 0)
+	s.AddWithTTL(7, 0)
+	if !slices.Equal(evicted, []interface{}{3, 4, 1}) {
+		t.Errorf("Evicted %v, want 3, 4, 1", evicted)
+	}
+}
+
+func TestExpiringSet_Clone(t *testing.T) {
+	clock := newFakeClock()
+	s := NewExpiringSetWithOptions(ExpiringSetOptions{TTL: time.Minute, Now: clock.Now}, 1)
+	clock.advance(30 * time.Second)
+	s.Add(2)
+	c := s.Clone()
+	clock.advance(45 * time.Second)
+	if c.Contains(1) || !c.Contains(2) {
+		t.Error("The clone should keep the deadlines")
+	}
+
+	// 2 would expire in 15 seconds in s
+	u := s.Union(NewHashSet(3))
+	clock.advance(45 * time.Second)
+	if !Equal(u, NewHashSet(2, 3)) {
+		t.Errorf("Got %v, want 2, 3 living for a minute from the union", u.ToSlice())
+	}
+}
+
+func TestExpiringSet_DecodeZero(t *testing.T) {
+	s := NewExpiringSet(time.Hour, "a", "b")
+	text, _ := s.MarshalText()
+	gob, _ := s.GobEncode()
+	bin, _ := s.MarshalBinary()
+	decoders := map[string]func(z *ExpiringSet) error{
+		"UnmarshalText":   func(z *ExpiringSet) error { return z.UnmarshalText(text) },
+		"GobDecode":       func(z *ExpiringSet) error { return z.GobDecode(gob) },
+		"UnmarshalBinary": func(z *ExpiringSet) error { return z.UnmarshalBinary(bin) },
+	}
+	for name, decode := range decoders {
+		var z ExpiringSet
+		if err := decode(&z); err != nil || z.Len() != 2 || !z.ContainsAll("a", "b") {
+			t.Errorf("%s should decode into a zero set, got %v, %v", name, z.ToSlice(), err)
+		}
+	}
+}
+
+func TestExpiringSet_CallbackUsesSet(t *testing.T) {
+	clock := newFakeClock()
+	var s *ExpiringSet
+	s = NewExpiringSetWithOptions(ExpiringSetOptions{
+		TTL: time.Minute,
+		Now: clock.Now,
+		OnEvict: func(e interface{}, reason EvictReason) {
+			if e == "first" {
+				s.AddWithTTL("again", 0)
+			}
+		},
+	}, "first")
+	clock.advance(time.Minute)
+	s.Expire()
+	if !s.Contains("again") || s.Len() != 1 {
+		t.Error("The callback should be able to add to the set")
+	}
+}
+
+// Run under -race
+func TestExpiringSet_Concurrent(t *testing.T) {
+	clock := newFakeClock()
+	s := NewExpiringSetWithOptions(ExpiringSetOptions{TTL: time.Second, MaxLen: 100, Now: clock.Now})
+	wg := &sync.WaitGroup{}
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				s.Add(w*1000 + i)
+				s.Contains(i)
+				if i%50 == 0 {
+					clock.advance(time.Millisecond * 100)
+					s.ToSlice()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if s.Len() > 100 {
+		t.Errorf("Length %d should be at most 100", s.Len())
+	}
+}
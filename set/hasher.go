@@ -0,0 +1,134 @@
+package set
+
+import (
+	"math"
+	"reflect"
+
+	"github.com/billryan/collections/internal/hashing"
+)
+
+type (
+	// Hasher decides which elements a HasherSet considers equal. Elements
+	// that are Equal must have the same Hash; elements with the same Hash
+	// need not be Equal.
+	Hasher interface {
+		Hash(e interface{}) uint64
+		Equal(a, b interface{}) bool
+	}
+
+	funcHasher struct {
+		hash  func(e interface{}) uint64
+		equal func(a, b interface{}) bool
+	}
+
+	deepHasher struct{}
+)
+
+// DeepHasher considers elements equal when reflect.DeepEqual does, and
+// hashes them by walking their contents. It lets a HasherSet hold slices,
+// maps and structs containing them.
+var DeepHasher Hasher = deepHasher{}
+
+// Create a Hasher from a hash and an equality function.
+func NewHasher(hash func(e interface{}) uint64, equal func(a, b interface{}) bool) Hasher {
+	return funcHasher{hash, equal}
+}
+
+func (h funcHasher) Hash(e interface{}) uint64 {
+	return h.hash(e)
+}
+
+func (h funcHasher) Equal(a, b interface{}) bool {
+	return h.equal(a, b)
+}
+
+func (deepHasher) Hash(e interface{}) uint64 {
+	return deepHash(reflect.ValueOf(e), map[uintptr]bool{})
+}
+
+func (deepHasher) Equal(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// Hash a value consistently with reflect.DeepEqual. Pointers already being
+// hashed are not followed again, so cyclic values hash too.
+func deepHash(v reflect.Value, visiting map[uintptr]bool) uint64 {
+	if !v.IsValid() {
+		return hashing.Sum64(nil)
+	}
+	h := hashing.Sum64(v.Type().String())
+	switch v.Kind() {
+	case reflect.Bool:
+		return combine(h, hashing.Sum64(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return combine(h, hashing.Sum64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return combine(h, hashing.Sum64(v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		return combine(h, hashFloat(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return combine(combine(h, hashFloat(real(c))), hashFloat(imag(c)))
+	case reflect.String:
+		return combine(h, hashing.Sum64(v.String()))
+	case reflect.Array, reflect.Slice:
+		if v.Kind() == reflect.Slice {
+			if v.IsNil() {
+				return h
+			}
+			if visiting[v.Pointer()] {
+				return h
+			}
+			visiting[v.Pointer()] = true
+			defer delete(visiting, v.Pointer())
+		}
+		for i := 0; i < v.Len(); i++ {
+			h = combine(h, deepHash(v.Index(i), visiting))
+		}
+		return h
+	case reflect.Map:
+		if v.IsNil() || visiting[v.Pointer()] {
+			return h
+		}
+		visiting[v.Pointer()] = true
+		defer delete(visiting, v.Pointer())
+		// Entries come in no particular order, so they are summed
+		var sum uint64
+		it := v.MapRange()
+		for it.Next() {
+			sum += combine(deepHash(it.Key(), visiting), deepHash(it.Value(), visiting))
+		}
+		return combine(h, sum)
+	case reflect.Pointer:
+		if v.IsNil() || visiting[v.Pointer()] {
+			return h
+		}
+		visiting[v.Pointer()] = true
+		defer delete(visiting, v.Pointer())
+		return combine(h, deepHash(v.Elem(), visiting))
+	case reflect.Interface:
+		return combine(h, deepHash(v.Elem(), visiting))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			h = combine(h, deepHash(v.Field(i), visiting))
+		}
+		return h
+	default:
+		// Functions only DeepEqual when nil, channels and unsafe pointers
+		// when identical
+		return h
+	}
+}
+
+// Hash a float so that 0 and -0, which are equal, hash alike
+func hashFloat(f float64) uint64 {
+	if f == 0 {
+		f = 0
+	}
+	return hashing.Sum64(math.Float64bits(f))
+}
+
+// Combine two hashes, in an order dependent way
+func combine(h, x uint64) uint64 {
+	return hashing.Mix(h*31 + x)
+}
@@ -0,0 +1,303 @@
+package set
+
+import (
+	"encoding/json"
+	"iter"
+
+	"github.com/billryan/collections"
+)
+
+type (
+	// HasherSet is a hash set that hashes and compares its elements with a
+	// Hasher rather than Go's ==. It can hold values that cannot be map
+	// keys, such as slices, or apply its own notion of equality, such as
+	// case-insensitive strings. Elements with the same hash share a bucket.
+	//
+	// Set algebra works between HasherSets sharing a hasher. Combining one
+	// with a set of another implementation only works for elements that
+	// set can hold.
+	HasherSet struct {
+		hasher  Hasher
+		buckets map[uint64][]interface{}
+		size    uint32
+	}
+)
+
+// Create a new set using hasher to hash and compare its elements
+func NewHasherSet(hasher Hasher, initial ...interface{}) *HasherSet {
+	s := &HasherSet{hasher: hasher, buckets: make(map[uint64][]interface{})}
+
+	for _, v := range initial {
+		s.Add(v)
+	}
+
+	return s
+}
+
+// Adds the specified element to this set if it is not already present (optional operation).
+func (s *HasherSet) Add(e interface{}) {
+	h := s.hasher.Hash(e)
+	if s.find(h, e) < 0 {
+		s.buckets[h] = append(s.buckets[h], e)
+		s.size++
+	}
+}
+
+// Adds all of the elements to this set if they're not already present (optional operation).
+func (s *HasherSet) AddAll(es ...interface{}) {
+	for _, e := range es {
+		s.Add(e)
+	}
+}
+
+// Removes all of the elements from this set (optional operation).
+func (s *HasherSet) Clear() {
+	s.buckets = make(map[uint64][]interface{})
+	s.size = 0
+}
+
+// Returns true if this set contains the specified element.
+func (s *HasherSet) Contains(e interface{}) bool {
+	return s.find(s.hasher.Hash(e), e) >= 0
+}
+
+// Returns true if this set contains all of the elements of the specified collection.
+func (s *HasherSet) ContainsAll(es ...interface{}) bool {
+	for _, e := range es {
+		if !s.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Call f for each item in the set
+func (s *HasherSet) Foreach(f func(interface{})) {
+	for _, bucket := range s.buckets {
+		for _, e := range bucket {
+			f(e)
+		}
+	}
+}
+
+// Call f for each item in the set until it returns false
+func (s *HasherSet) Do(f func(interface{}) bool) {
+	for _, bucket := range s.buckets {
+		for _, e := range bucket {
+			if !f(e) {
+				return
+			}
+		}
+	}
+}
+
+// Returns a sequence over the elements in this set.
+func (s *HasherSet) All() iter.Seq[interface{}] {
+	return s.Do
+}
+
+// Returns an iterator over a snapshot of the elements in this set.
+func (s *HasherSet) Iterator() collections.Iterator {
+	return newIterator(s.ToSlice())
+}
+
+// Call f for each item in the set, set result as new key. The results are
+// collected into a set with the same hasher.
+func (s *HasherSet) Map(f func(interface{}) interface{}) Set {
+	n := s.empty()
+	s.Foreach(func(e interface{}) {
+		n.Add(f(e))
+	})
+	return n
+}
+
+// Returns true if this set contains no elements.
+func (s *HasherSet) IsEmpty() bool {
+	return s.size == 0
+}
+
+// Removes the specified element from this set if it is present (optional operation).
+func (s *HasherSet) Remove(e interface{}) bool {
+	h := s.hasher.Hash(e)
+	i := s.find(h, e)
+	if i < 0 {
+		return false
+	}
+	bucket := s.buckets[h]
+	if len(bucket) == 1 {
+		delete(s.buckets, h)
+	} else {
+		bucket[i] = bucket[len(bucket)-1]
+		bucket[len(bucket)-1] = nil
+		s.buckets[h] = bucket[:len(bucket)-1]
+	}
+	s.size--
+	return true
+}
+
+// Removes the specified elements from this set if it is present (optional operation).
+// Return true if all element exist.
+func (s *HasherSet) RemoveAll(es ...interface{}) bool {
+	existAll := true
+	for _, e := range es {
+		if !s.Remove(e) {
+			existAll = false
+		}
+	}
+	return existAll
+}
+
+// Return the number of elements in set s (cardinality of s).
+func (s *HasherSet) Len() uint32 {
+	return s.size
+}
+
+// Returns an slice containing all of the elements in this set.
+func (s *HasherSet) ToSlice() []interface{} {
+	slice := make([]interface{}, 0, s.size)
+	s.Foreach(func(e interface{}) {
+		slice = append(slice, e)
+	})
+	return slice
+}
+
+// Returns a deep clone of set, sharing its hasher
+func (s *HasherSet) Clone() Set {
+	n := s.empty()
+	for h, bucket := range s.buckets {
+		n.buckets[h] = append([]interface{}(nil), bucket...)
+	}
+	n.size = s.size
+	return n
+}
+
+// Return a new set with elements common to the set and all others.
+func (s *HasherSet) Intersection(others ...Set) Set {
+	return intersection(s.empty(), s, others)
+}
+
+// Return a new set with elements from the set and all others.
+func (s *HasherSet) Union(others ...Set) Set {
+	return union(s.empty(), s, others)
+}
+
+// Return a new set with elements in the set that are not in the others.
+func (s *HasherSet) Difference(others ...Set) Set {
+	return difference(s.empty(), s, others)
+}
+
+// Return a new set with elements in either the set or other but not both.
+func (s *HasherSet) SymmetricDifference(other Set) Set {
+	return symmetricDifference(s.empty(), s, other)
+}
+
+// Test whether the set and other contain the same elements.
+func (s *HasherSet) Equal(other Set) bool {
+	return equal(s, other)
+}
+
+// Test whether the set has no elements in common with other.
+func (s *HasherSet) IsDisjoint(other Set) bool {
+	return isDisjoint(s, other)
+}
+
+// Test whether every element in the set is in other. set <= other
+func (s *HasherSet) IsSubset(other Set) bool {
+	return isSubset(s, other)
+}
+
+// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+func (s *HasherSet) IsProperSubset(other Set) bool {
+	return s.Len() < other.Len() && s.IsSubset(other)
+}
+
+// Test whether every element in other is in the set. set >= other
+func (s *HasherSet) IsSuperset(other Set) bool {
+	return other.IsSubset(s)
+}
+
+// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+func (s *HasherSet) IsProperSuperset(other Set) bool {
+	return s.Len() > other.Len() && s.IsSuperset(other)
+}
+
+// Replaces the elements of the set with those of a JSON array.
+func (s *HasherSet) UnmarshalText(text []byte) error {
+	s.lazyInit()
+	var v []interface{}
+	err := json.Unmarshal(text, &v)
+	if err == nil {
+		s.Clear()
+		s.AddAll(v...)
+	}
+	return err
+}
+
+// Encode the set as a JSON array, sorted so that equal sets encode alike.
+func (s *HasherSet) MarshalText() ([]byte, error) {
+	return json.Marshal(sortedElements(s))
+}
+
+// Encode the set as a JSON array, as MarshalText does.
+func (s *HasherSet) MarshalJSON() ([]byte, error) {
+	return s.MarshalText()
+}
+
+// Replaces the elements of the set with those of a JSON array.
+func (s *HasherSet) UnmarshalJSON(data []byte) error {
+	return s.UnmarshalText(data)
+}
+
+// Encode the set with gob, which keeps the types of the elements. Types
+// other than the basic ones must be registered with gob.Register.
+func (s *HasherSet) GobEncode() ([]byte, error) {
+	return gobEncode(sortedElements(s))
+}
+
+// Replaces the elements of the set with those encoded by GobEncode.
+func (s *HasherSet) GobDecode(data []byte) error {
+	s.lazyInit()
+	return decodeInto(s, data, gobDecode)
+}
+
+// Encode the set in a compact binary form. Only nil and basic types other
+// than complex numbers and uintptr can be encoded.
+func (s *HasherSet) MarshalBinary() ([]byte, error) {
+	return marshalBinary(sortedElements(s))
+}
+
+// Replaces the elements of the set with those encoded by MarshalBinary.
+func (s *HasherSet) UnmarshalBinary(data []byte) error {
+	s.lazyInit()
+	return decodeInto(s, data, unmarshalBinary)
+}
+
+// Returns the hasher of the set.
+func (s *HasherSet) Hasher() Hasher {
+	return s.hasher
+}
+
+// Give a zero HasherSet the DeepHasher, so that it can be decoded into
+func (s *HasherSet) lazyInit() {
+	if s.hasher == nil {
+		s.hasher = DeepHasher
+	}
+	if s.buckets == nil {
+		s.buckets = make(map[uint64][]interface{})
+	}
+}
+
+// Get the position of e in the bucket for hash h, or -1
+func (s *HasherSet) find(h uint64, e interface{}) int {
+	for i, x := range s.buckets[h] {
+		if s.hasher.Equal(x, e) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Create an empty set with the same hasher as s
+func (s *HasherSet) empty() *HasherSet {
+	return NewHasherSet(s.hasher)
+}
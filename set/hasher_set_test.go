@@ -0,0 +1,108 @@
+package set
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/billryan/collections/internal/hashing"
+)
+
+func newDeepHasherSet(initial ...interface{}) Set {
+	return NewHasherSet(DeepHasher, initial...)
+}
+
+// Compares strings ignoring case
+var foldHasher = NewHasher(
+	func(e interface{}) uint64 { return hashing.Sum64(strings.ToLower(e.(string))) },
+	func(a, b interface{}) bool { return strings.EqualFold(a.(string), b.(string)) },
+)
+
+type account struct {
+	Name  string
+	Roles []string
+	Meta  map[string]int
+}
+
+func TestHasherSet_Unhashable(t *testing.T) {
+	s := NewHasherSet(DeepHasher, []int{1, 2}, []int{1, 2}, []int{2, 1}, map[string]int{"a": 1})
+	if s.Len() != 3 {
+		t.Errorf("Length should be 3, got %d", s.Len())
+	}
+	if !s.ContainsAll([]int{1, 2}, []int{2, 1}, map[string]int{"a": 1}) || s.Contains([]int{1}) {
+		t.Errorf("Set should hold [1 2], [2 1] and map[a:1], got %v", s.ToSlice())
+	}
+
+	a := account{"ann", []string{"admin"}, map[string]int{"x": 1, "y": 2}}
+	s.Add(a)
+	s.Add(&account{"bob", nil, nil})
+	if !s.Contains(account{"ann", []string{"admin"}, map[string]int{"y": 2, "x": 1}}) {
+		t.Errorf("Structs with slices and maps should compare by content")
+	}
+	if !s.Contains(&account{"bob", nil, nil}) {
+		t.Errorf("Pointers should compare by what they point to")
+	}
+	if !s.Remove([]int{1, 2}) || s.Remove([]int{1, 2}) || s.Len() != 4 {
+		t.Errorf("Remove should report whether the element was present")
+	}
+}
+
+func TestHasherSet_Cyclic(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+	n := &node{}
+	n.Next = n
+	s := NewHasherSet(DeepHasher, n)
+	if !s.Contains(n) {
+		t.Errorf("Cyclic values should hash")
+	}
+}
+
+func TestHasherSet_CustomEquality(t *testing.T) {
+	s := NewHasherSet(foldHasher, "Ann@Example.com", "bob@example.com")
+	s.Add("ann@example.COM")
+	if s.Len() != 2 || !s.Contains("BOB@EXAMPLE.COM") {
+		t.Errorf("Emails should compare ignoring case, got %v", s.ToSlice())
+	}
+	o := NewHasherSet(foldHasher, "ANN@EXAMPLE.COM", "carl@example.com")
+	if i := s.Intersection(o); i.Len() != 1 || !i.Contains("ann@example.com") {
+		t.Errorf("Intersection should be ann, got %v", i.ToSlice())
+	}
+	if u := s.Union(o); u.Len() != 3 || u.(*HasherSet).Hasher() == nil {
+		t.Errorf("Union should be ann, bob, carl with the same hasher, got %v", u.ToSlice())
+	}
+	if d := s.Difference(o); !Equal(d, NewHasherSet(foldHasher, "BOB@example.com")) {
+		t.Errorf("Difference should be bob, got %v", d.ToSlice())
+	}
+	if m := s.Map(func(e interface{}) interface{} { return strings.ToUpper(e.(string)) }); !Equal(m, s) {
+		t.Errorf("Map should keep the hasher, got %v", m.ToSlice())
+	}
+}
+
+func TestHasherSet_Collisions(t *testing.T) {
+	constant := NewHasher(
+		func(interface{}) uint64 { return 0 },
+		func(a, b interface{}) bool { return a == b },
+	)
+	s := NewHasherSet(constant)
+	for i := 0; i < 100; i++ {
+		s.Add(i)
+	}
+	s.Add(50)
+	if s.Len() != 100 || len(s.buckets) != 1 {
+		t.Errorf("Colliding elements should share one bucket, got %d in %d", s.Len(), len(s.buckets))
+	}
+	for i := 0; i < 100; i += 2 {
+		s.Remove(i)
+	}
+	for i := 0; i < 100; i++ {
+		if s.Contains(i) != (i%2 == 1) {
+			t.Fatalf("Set should contain exactly the odd numbers, wrong about %d", i)
+		}
+	}
+	c := s.Clone()
+	c.Clear()
+	if s.Len() != 50 || !c.IsEmpty() {
+		t.Errorf("Clone should not share buckets with the set")
+	}
+}
@@ -1,6 +1,11 @@
 package set
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"iter"
+
+	"github.com/billryan/collections"
+)
 
 type (
 	HashSet struct {
@@ -53,6 +58,25 @@ func (s *HashSet) Foreach(f func(interface{})) {
 	}
 }
 
+// Call f for each item in the set until it returns false
+func (s *HashSet) Do(f func(interface{}) bool) {
+	for k := range s.hash {
+		if !f(k) {
+			return
+		}
+	}
+}
+
+// Returns a sequence over the elements in this set.
+func (s *HashSet) All() iter.Seq[interface{}] {
+	return s.Do
+}
+
+// Returns an iterator over a snapshot of the elements in this set.
+func (s *HashSet) Iterator() collections.Iterator {
+	return newIterator(s.ToSlice())
+}
+
 // Call f for each item in the set, set result as new key
 func (s *HashSet) Map(f func(interface{}) interface{}) Set {
 	n := make(map[interface{}]nothing)
@@ -115,71 +139,37 @@ func (s *HashSet) Clone() Set {
 
 // Return a new set with elements common to the set and all others.
 func (s *HashSet) Intersection(others ...Set) Set {
-	n := make(map[interface{}]nothing)
-
-	for k := range s.hash {
-		existAll := true
-		for _, set := range others {
-			if !set.Contains(k) {
-				existAll = false
-				break
-			}
-		}
-		if existAll {
-			n[k] = nothing{}
-		}
-	}
-
-	return &HashSet{n}
+	return intersection(NewHashSet(), s, others)
 }
 
 // Return a new set with elements from the set and all others.
 func (s *HashSet) Union(others ...Set) Set {
-	n := make(map[interface{}]nothing)
-
-	for k := range s.hash {
-		n[k] = nothing{}
-	}
-	for _, set := range others {
-		hashset := set.(*HashSet)
-		for k := range hashset.hash {
-			n[k] = nothing{}
-		}
-	}
-
-	return &HashSet{n}
+	return union(NewHashSet(), s, others)
 }
 
 // Return a new set with elements in the set that are not in the others.
 func (s *HashSet) Difference(others ...Set) Set {
-	n := make(map[interface{}]nothing)
+	return difference(NewHashSet(), s, others)
+}
 
-	for k := range s.hash {
-		existAny := false
-		for _, set := range others {
-			if set.Contains(k) {
-				existAny = true
-			}
-		}
-		if !existAny {
-			n[k] = nothing{}
-		}
-	}
+// Return a new set with elements in either the set or other but not both.
+func (s *HashSet) SymmetricDifference(other Set) Set {
+	return symmetricDifference(NewHashSet(), s, other)
+}
 
-	return &HashSet{n}
+// Test whether the set and other contain the same elements.
+func (s *HashSet) Equal(other Set) bool {
+	return equal(s, other)
+}
+
+// Test whether the set has no elements in common with other.
+func (s *HashSet) IsDisjoint(other Set) bool {
+	return isDisjoint(s, other)
 }
 
 // Test whether every element in the set is in other. set <= other
 func (s *HashSet) IsSubset(other Set) bool {
-	if s.Len() > other.Len() {
-		return false
-	}
-	for k := range s.hash {
-		if !other.Contains(k) {
-			return false
-		}
-	}
-	return true
+	return isSubset(s, other)
 }
 
 // Test whether the set is a proper subset of other, that is, set <= other and set != other.
@@ -208,3 +198,46 @@ func (s *HashSet) UnmarshalText(text []byte) error {
 	}
 	return err
 }
+
+// Encode the set as a JSON array, sorted so that equal sets encode alike.
+func (s *HashSet) MarshalText() ([]byte, error) {
+	return json.Marshal(sortedElements(s))
+}
+
+// Encode the set as a JSON array, as MarshalText does.
+func (s *HashSet) MarshalJSON() ([]byte, error) {
+	return s.MarshalText()
+}
+
+// Replaces the elements of the set with those of a JSON array.
+func (s *HashSet) UnmarshalJSON(data []byte) error {
+	return s.UnmarshalText(data)
+}
+
+// Encode the set with gob, which keeps the types of the elements. Types
+// other than the basic ones must be registered with gob.Register.
+func (s *HashSet) GobEncode() ([]byte, error) {
+	return gobEncode(sortedElements(s))
+}
+
+// Replaces the elements of the set with those encoded by GobEncode.
+func (s *HashSet) GobDecode(data []byte) error {
+	if s.hash == nil {
+		s.hash = make(map[interface{}]nothing)
+	}
+	return decodeInto(s, data, gobDecode)
+}
+
+// Encode the set in a compact binary form. Only nil and basic types other
+// than complex numbers and uintptr can be encoded.
+func (s *HashSet) MarshalBinary() ([]byte, error) {
+	return marshalBinary(sortedElements(s))
+}
+
+// Replaces the elements of the set with those encoded by MarshalBinary.
+func (s *HashSet) UnmarshalBinary(data []byte) error {
+	if s.hash == nil {
+		s.hash = make(map[interface{}]nothing)
+	}
+	return decodeInto(s, data, unmarshalBinary)
+}
@@ -0,0 +1,222 @@
+package set
+
+import (
+	"encoding/json"
+	"iter"
+)
+
+type (
+	HashSetOf[T comparable] struct {
+		hash map[T]nothing
+	}
+)
+
+// Adds the specified element to this set if it is not already present (optional operation).
+func (s *HashSetOf[T]) Add(e T) {
+	s.hash[e] = nothing{}
+}
+
+// Adds all of the elements to this set if they're not already present (optional operation).
+func (s *HashSetOf[T]) AddAll(es ...T) {
+	for _, e := range es {
+		s.hash[e] = nothing{}
+	}
+}
+
+// Removes all of the elements from this set (optional operation).
+func (s *HashSetOf[T]) Clear() {
+	for e := range s.hash {
+		delete(s.hash, e)
+	}
+}
+
+// Returns true if this set contains the specified element.
+func (s *HashSetOf[T]) Contains(e T) bool {
+	_, exist := s.hash[e]
+	return exist
+}
+
+// Returns true if this set contains all of the elements of the specified collection.
+func (s *HashSetOf[T]) ContainsAll(es ...T) bool {
+	for _, e := range es {
+		_, exist := s.hash[e]
+		if !exist {
+			return false
+		}
+	}
+	return true
+}
+
+// Call f for each item in the set
+func (s *HashSetOf[T]) Foreach(f func(T)) {
+	for k := range s.hash {
+		f(k)
+	}
+}
+
+// Returns a sequence over the elements in this set.
+func (s *HashSetOf[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for k := range s.hash {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Returns true if this set contains no elements.
+func (s *HashSetOf[T]) IsEmpty() bool {
+	return len(s.hash) == 0
+}
+
+// Removes the specified element from this set if it is present (optional operation).
+func (s *HashSetOf[T]) Remove(e T) bool {
+	_, exist := s.hash[e]
+	delete(s.hash, e)
+	return exist
+}
+
+// Removes the specified elements from this set if it is present (optional operation).
+// Return true if all element exist.
+func (s *HashSetOf[T]) RemoveAll(es ...T) bool {
+	existAll := true
+	for _, e := range es {
+		_, exist := s.hash[e]
+		if exist {
+			delete(s.hash, e)
+		} else {
+			existAll = false
+		}
+	}
+	return existAll
+}
+
+// Return the number of elements in set s (cardinality of s).
+func (s *HashSetOf[T]) Len() uint32 {
+	return uint32(len(s.hash))
+}
+
+// Returns an slice containing all of the elements in this set.
+func (s *HashSetOf[T]) ToSlice() []T {
+	slice := make([]T, 0, len(s.hash))
+	for e := range s.hash {
+		slice = append(slice, e)
+	}
+	return slice
+}
+
+// Returns a deep clone of set
+func (s *HashSetOf[T]) Clone() Of[T] {
+	n := make(map[T]nothing, len(s.hash))
+
+	for k := range s.hash {
+		n[k] = nothing{}
+	}
+
+	return &HashSetOf[T]{n}
+}
+
+// Return a new set with elements common to the set and all others.
+func (s *HashSetOf[T]) Intersection(others ...Of[T]) Of[T] {
+	n := make(map[T]nothing)
+
+	for k := range s.hash {
+		existAll := true
+		for _, set := range others {
+			if !set.Contains(k) {
+				existAll = false
+				break
+			}
+		}
+		if existAll {
+			n[k] = nothing{}
+		}
+	}
+
+	return &HashSetOf[T]{n}
+}
+
+// Return a new set with elements from the set and all others.
+func (s *HashSetOf[T]) Union(others ...Of[T]) Of[T] {
+	n := make(map[T]nothing, len(s.hash))
+
+	for k := range s.hash {
+		n[k] = nothing{}
+	}
+	for _, set := range others {
+		set.Foreach(func(k T) {
+			n[k] = nothing{}
+		})
+	}
+
+	return &HashSetOf[T]{n}
+}
+
+// Return a new set with elements in the set that are not in the others.
+func (s *HashSetOf[T]) Difference(others ...Of[T]) Of[T] {
+	n := make(map[T]nothing)
+
+	for k := range s.hash {
+		existAny := false
+		for _, set := range others {
+			if set.Contains(k) {
+				existAny = true
+				break
+			}
+		}
+		if !existAny {
+			n[k] = nothing{}
+		}
+	}
+
+	return &HashSetOf[T]{n}
+}
+
+// Test whether every element in the set is in other. set <= other
+func (s *HashSetOf[T]) IsSubset(other Of[T]) bool {
+	if s.Len() > other.Len() {
+		return false
+	}
+	for k := range s.hash {
+		if !other.Contains(k) {
+			return false
+		}
+	}
+	return true
+}
+
+// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+func (s *HashSetOf[T]) IsProperSubset(other Of[T]) bool {
+	return s.Len() < other.Len() && s.IsSubset(other)
+}
+
+// Test whether every element in other is in the set. set >= other
+func (s *HashSetOf[T]) IsSuperset(other Of[T]) bool {
+	return other.IsSubset(s)
+}
+
+// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+func (s *HashSetOf[T]) IsProperSuperset(other Of[T]) bool {
+	return s.Len() > other.Len() && s.IsSuperset(other)
+}
+
+// Encode the set as a JSON array, sorted so that equal sets encode alike.
+func (s *HashSetOf[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(sortElementsOf(s.ToSlice()))
+}
+
+// Replaces the elements of the set with those of a JSON array, decoded as
+// Ts.
+func (s *HashSetOf[T]) UnmarshalJSON(data []byte) error {
+	var v []T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if s.hash == nil {
+		s.hash = make(map[T]nothing)
+	}
+	s.Clear()
+	s.AddAll(v...)
+	return nil
+}
@@ -0,0 +1,91 @@
+package set
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestNewHashSetOf(t *testing.T) {
+	s := NewHashSetOf[int]()
+	if s.Len() != 0 {
+		t.Error("Length of empty init set should be 0")
+	}
+
+	s = NewHashSetOf(1, 4, 8, 8)
+	if s.Len() != 3 {
+		t.Error("Length should be 3")
+	}
+}
+
+func TestHashSetOf_AddRemove(t *testing.T) {
+	s := NewHashSetOf[string]()
+	s.AddAll("k1", "k2")
+	if !s.ContainsAll("k1", "k2") {
+		t.Error("Set should contain 'k1' and 'k2'")
+	}
+	if !s.Remove("k1") {
+		t.Error("Remove should report 'k1' as present")
+	}
+	if s.RemoveAll("k1", "k2") {
+		t.Error("RemoveAll should report 'k1' as missing")
+	}
+	if !s.IsEmpty() {
+		t.Error("Set should be empty")
+	}
+}
+
+func TestHashSetOf_ToSlice(t *testing.T) {
+	s := NewHashSetOf(4, 1, 2)
+	slice := s.ToSlice()
+	sort.Ints(slice)
+	if len(slice) != 3 || slice[0] != 1 || slice[1] != 2 || slice[2] != 4 {
+		t.Errorf("slice %v should be 1, 2, 4", slice)
+	}
+}
+
+func TestHashSetOf_Clone(t *testing.T) {
+	s := NewHashSetOf(1, 2, 4)
+	s2 := s.Clone()
+	s.Clear()
+	if !s2.ContainsAll(1, 2, 4) {
+		t.Error("Set s2 should contain 1, 2, 4")
+	}
+}
+
+func TestHashSetOf_Algebra(t *testing.T) {
+	s1 := NewHashSetOf(1, 2, 4)
+	s2 := NewHashSetOf(1, 2, 8)
+	s3 := NewConcurrentSetOf(2, 3)
+
+	u := s1.Union(s2, s3)
+	if u.Len() != 5 || !u.ContainsAll(1, 2, 3, 4, 8) {
+		t.Error("Union should be 1, 2, 3, 4, 8")
+	}
+
+	i := s1.Intersection(s2, s3)
+	if i.Len() != 1 || !i.Contains(2) {
+		t.Error("Intersection should be 2")
+	}
+
+	d := s1.Difference(s2, s3)
+	if d.Len() != 1 || !d.Contains(4) {
+		t.Error("Difference should be 4")
+	}
+}
+
+func TestHashSetOf_Subset(t *testing.T) {
+	s1 := NewHashSetOf(1, 2, 4)
+	s2 := NewHashSetOf(2, 4)
+	if !s2.IsSubset(s1) || s1.IsSubset(s2) {
+		t.Error("Set s2 should be subset of s1 and not the other way round")
+	}
+	if !s2.IsProperSubset(s1) || s1.IsProperSubset(s1) {
+		t.Error("Set s2 should be the only proper subset of s1")
+	}
+	if !s1.IsSuperset(s2) || s2.IsSuperset(s1) {
+		t.Error("Set s1 should be superset of s2 and not the other way round")
+	}
+	if !s1.IsProperSuperset(s2) || s1.IsProperSuperset(s1) {
+		t.Error("Set s1 should only be proper superset of s2")
+	}
+}
@@ -288,3 +288,56 @@ func TestHashSet_UnmarshalText(t *testing.T) {
 	//		t.Errorf("toml unmarshal error %s", err)
 	//	}
 }
+
+func TestHashSet_Iterator(t *testing.T) {
+	s := NewHashSet(1, 2, 4)
+	it := IteratorOf(s)
+	sum := 0
+	for it.Next() {
+		if it.Key() != it.Value() {
+			t.Error("Key and value should both be the element")
+		}
+		sum += it.Value().(int)
+	}
+	if sum != 7 || it.Err() != nil {
+		t.Error("Iterator should visit 1, 2, 4")
+	}
+
+	n := 0
+	for range All(s) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Error("All should stop when the consumer does")
+	}
+}
+
+// A Set implementing only the methods of the interface
+type plainSet struct {
+	Set
+}
+
+func TestPlainSet_Iteration(t *testing.T) {
+	s := plainSet{NewHashSet(1, 2, 4)}
+	sum := 0
+	for e := range All(s) {
+		sum += e.(int)
+	}
+	it := IteratorOf(s)
+	for it.Next() {
+		sum += it.Value().(int)
+	}
+	if sum != 14 {
+		t.Errorf("All and IteratorOf should visit 1, 2, 4, got a sum of %d", sum)
+	}
+
+	n := 0
+	Do(s, func(e interface{}) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Error("Do should stop when f returns false")
+	}
+}
@@ -0,0 +1,318 @@
+// Package hll provides HyperLogLog sketches, which estimate the number of
+// distinct elements of a stream in a few kilobytes, with a relative
+// standard error of about 1.04 / sqrt(2^precision).
+//
+// Following HyperLogLog++, a sketch starts out sparse, keeping only the
+// registers that were hit at a higher precision, which makes small counts
+// nearly exact. It turns dense once that would take more room than the
+// plain registers. Dense counts use the improved estimator of Ertl, which
+// needs no empirical bias tables.
+package hll
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"slices"
+
+	"github.com/billryan/collections/internal/hashing"
+)
+
+type (
+	// Sketch is a HyperLogLog sketch. The zero value is an empty sketch of
+	// DefaultPrecision.
+	Sketch struct {
+		p uint8
+		// One register per bucket, holding the highest rank seen, or nil
+		// while the sketch is sparse
+		registers []uint8
+		// Ranks at precision sparsePrecision of the buckets hit, while the
+		// sketch is sparse
+		sparse map[uint32]uint8
+	}
+)
+
+const (
+	// Lowest, highest and default precision. A sketch has 2^precision
+	// registers.
+	MinPrecision     = 4
+	MaxPrecision     = 18
+	DefaultPrecision = 14
+
+	// Precision of the sparse representation
+	sparsePrecision = 25
+
+	version = 1
+)
+
+// ErrPrecision is returned when merging sketches of different precisions.
+var ErrPrecision = errors.New("hll: sketches differ in precision")
+
+// Create a sketch of 2^precision registers. Panics unless precision is
+// within [MinPrecision, MaxPrecision].
+func New(precision uint8) *Sketch {
+	if precision < MinPrecision || precision > MaxPrecision {
+		panic(fmt.Sprintf("hll: precision %d out of [%d, %d]", precision, MinPrecision, MaxPrecision))
+	}
+	return &Sketch{p: precision, sparse: map[uint32]uint8{}}
+}
+
+// Adds the specified element to this sketch.
+func (s *Sketch) Add(e interface{}) {
+	s.lazyInit()
+	s.addHash(hashing.Sum64(e))
+}
+
+// Adds all of the elements to this sketch.
+func (s *Sketch) AddAll(es ...interface{}) {
+	for _, e := range es {
+		s.Add(e)
+	}
+}
+
+// Estimate the number of distinct elements added to this sketch.
+func (s *Sketch) Count() uint64 {
+	if s.registers == nil {
+		// Linear counting over the sparse buckets, nearly exact while so
+		// few of them are hit
+		m := float64(uint64(1) << sparsePrecision)
+		return uint64(math.Round(m * math.Log(m/(m-float64(len(s.sparse))))))
+	}
+	return uint64(math.Round(estimate(s.registers, s.p)))
+}
+
+// Adds the elements of other to this sketch. Both must have the same
+// precision.
+func (s *Sketch) Merge(other *Sketch) error {
+	s.lazyInit()
+	other.lazyInit()
+	if s.p != other.p {
+		return ErrPrecision
+	}
+	if s.registers == nil && other.registers == nil {
+		for i, r := range other.sparse {
+			s.sparse[i] = max(s.sparse[i], r)
+		}
+		s.densifyIfLarge()
+		return nil
+	}
+	s.densify()
+	registers := other.registers
+	if registers == nil {
+		registers = other.Clone().densify()
+	}
+	for i, r := range registers {
+		s.registers[i] = max(s.registers[i], r)
+	}
+	return nil
+}
+
+// Get the precision of the sketch.
+func (s *Sketch) Precision() uint8 {
+	s.lazyInit()
+	return s.p
+}
+
+// Returns true if the sketch still has its sparse representation.
+func (s *Sketch) IsSparse() bool {
+	return s.registers == nil
+}
+
+// Removes all of the elements from this sketch, making it sparse again.
+func (s *Sketch) Clear() {
+	s.lazyInit()
+	s.registers = nil
+	s.sparse = map[uint32]uint8{}
+}
+
+// Returns a deep clone of the sketch.
+func (s *Sketch) Clone() *Sketch {
+	n := &Sketch{p: s.p, registers: slices.Clone(s.registers)}
+	if s.sparse != nil {
+		n.sparse = make(map[uint32]uint8, len(s.sparse))
+		for i, r := range s.sparse {
+			n.sparse[i] = r
+		}
+	}
+	return n
+}
+
+// Encode the sketch: a version, the precision and whether it is dense,
+// followed either by the sparse buckets in order with their ranks or by
+// all registers. All numbers are little-endian.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	s.lazyInit()
+	le := binary.LittleEndian
+	if s.registers != nil {
+		return append([]byte{version, s.p, 1}, s.registers...), nil
+	}
+	b := []byte{version, s.p, 0}
+	b = le.AppendUint32(b, uint32(len(s.sparse)))
+	keys := make([]uint32, 0, len(s.sparse))
+	for i := range s.sparse {
+		keys = append(keys, i)
+	}
+	slices.Sort(keys)
+	for _, i := range keys {
+		b = le.AppendUint32(b, i)
+		b = append(b, s.sparse[i])
+	}
+	return b, nil
+}
+
+// Replace the sketch with one decoded from the form written by
+// MarshalBinary.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	le := binary.LittleEndian
+	if len(data) < 3 {
+		return errors.New("hll: truncated data")
+	}
+	if data[0] != version {
+		return fmt.Errorf("hll: unknown version %d", data[0])
+	}
+	p, dense := data[1], data[2]
+	if p < MinPrecision || p > MaxPrecision {
+		return fmt.Errorf("hll: precision %d out of [%d, %d]", p, MinPrecision, MaxPrecision)
+	}
+	data = data[3:]
+
+	switch dense {
+	case 1:
+		if len(data) != 1<<p {
+			return fmt.Errorf("hll: %d registers for precision %d", len(data), p)
+		}
+		for _, r := range data {
+			if int(r) > 64-int(p)+1 {
+				return fmt.Errorf("hll: register rank %d out of range", r)
+			}
+		}
+		s.p, s.registers, s.sparse = p, slices.Clone(data), nil
+	case 0:
+		if len(data) < 4 || uint64(len(data)-4) != 5*uint64(le.Uint32(data)) {
+			return errors.New("hll: truncated data")
+		}
+		n := int(le.Uint32(data))
+		sparse := make(map[uint32]uint8, n)
+		for j := 0; j < n; j++ {
+			entry := data[4+5*j:]
+			i, r := le.Uint32(entry), entry[4]
+			if i >= 1<<sparsePrecision || r < 1 || r > 64-sparsePrecision+1 {
+				return errors.New("hll: invalid sparse entry")
+			}
+			sparse[i] = r
+		}
+		s.p, s.registers, s.sparse = p, nil, sparse
+	default:
+		return fmt.Errorf("hll: unknown representation %d", dense)
+	}
+	return nil
+}
+
+// Give a zero Sketch its precision and sparse buckets
+func (s *Sketch) lazyInit() {
+	if s.p == 0 {
+		*s = *New(DefaultPrecision)
+	}
+}
+
+// Record hash h: its top bits pick a bucket, and the rank of the rest,
+// that is the position of its first set bit, goes to the bucket's
+// register if higher
+func (s *Sketch) addHash(h uint64) {
+	if s.registers != nil {
+		i, r := split(h, s.p)
+		s.registers[i] = max(s.registers[i], r)
+		return
+	}
+	i, r := split(h, sparsePrecision)
+	s.sparse[uint32(i)] = max(s.sparse[uint32(i)], r)
+	s.densifyIfLarge()
+}
+
+// Turn dense once the sparse buckets take more room than the registers
+func (s *Sketch) densifyIfLarge() {
+	if len(s.sparse) > 1<<s.p/8 {
+		s.densify()
+	}
+}
+
+// Turn dense, returning the registers
+func (s *Sketch) densify() []uint8 {
+	if s.registers != nil {
+		return s.registers
+	}
+	s.registers = make([]uint8, 1<<s.p)
+	shift := sparsePrecision - s.p
+	for i, r := range s.sparse {
+		// The bits of the sparse bucket beyond the first p belong to the
+		// rest of the hash at precision p
+		if rest := i & (1<<shift - 1); rest != 0 {
+			r = uint8(bits.LeadingZeros32(rest)-(32-int(shift))) + 1
+		} else {
+			r += shift
+		}
+		j := i >> shift
+		s.registers[j] = max(s.registers[j], r)
+	}
+	s.sparse = nil
+	return s.registers
+}
+
+// Split a hash into a bucket of p bits and the rank of the remaining bits
+func split(h uint64, p uint8) (uint64, uint8) {
+	rest := h<<p | 1<<(p-1)
+	return h >> (64 - p), uint8(bits.LeadingZeros64(rest)) + 1
+}
+
+// Estimate the cardinality from dense registers with the improved raw
+// estimator of Ertl, "New cardinality estimation algorithms for
+// HyperLogLog sketches" (2017)
+func estimate(registers []uint8, p uint8) float64 {
+	q := 64 - int(p)
+	counts := make([]float64, q+2)
+	for _, r := range registers {
+		counts[r]++
+	}
+	m := float64(len(registers))
+
+	z := m * tau(1-counts[q+1]/m)
+	for k := q; k >= 1; k-- {
+		z = 0.5 * (z + counts[k])
+	}
+	z += m * sigma(counts[0]/m)
+	return m * m / (2 * math.Ln2) / z
+}
+
+func sigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		prev := z
+		z += x * y
+		y += y
+		if z == prev {
+			return z
+		}
+	}
+}
+
+func tau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		prev := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if z == prev {
+			return z / 3
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package hll
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Check an estimate is within tolerance times the standard error of a
+// sketch of precision p.
+func checkEstimate(t *testing.T, what string, got, want uint64, p uint8, tolerance float64) {
+	t.Helper()
+	stdErr := 1.04 / math.Sqrt(float64(uint64(1)<<p))
+	if diff := math.Abs(float64(got) - float64(want)); diff > tolerance*stdErr*float64(want)+1 {
+		t.Errorf("%s should be about %d, got %d (%.2f%% off)", what, want, got, 100*diff/float64(want))
+	}
+}
+
+func TestNew(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Precision 3 should panic")
+		}
+	}()
+	New(3)
+}
+
+func TestSketch_ZeroValue(t *testing.T) {
+	var s Sketch
+	if s.Count() != 0 || s.Precision() != DefaultPrecision {
+		t.Errorf("Zero sketch should be empty at precision %d", DefaultPrecision)
+	}
+	s.AddAll(1, 2, 3)
+	if s.Count() != 3 {
+		t.Errorf("Count should be 3, got %d", s.Count())
+	}
+	var other Sketch
+	if err := other.Merge(&s); err != nil || other.Count() != 3 {
+		t.Errorf("Merging into a zero sketch should count 3, got %d (%v)", other.Count(), err)
+	}
+}
+
+func TestSketch_Count(t *testing.T) {
+	for _, p := range []uint8{4, 10, 14, 18} {
+		s := New(p)
+		if s.Count() != 0 {
+			t.Errorf("Empty sketch should count 0, got %d", s.Count())
+		}
+		n := 0
+		for _, target := range []int{10, 100, 1000, 10000, 100000, 1000000} {
+			for ; n < target; n++ {
+				s.Add(n)
+				s.Add(n)
+			}
+			tolerance := 4.0
+			if s.IsSparse() {
+				// Linear counting is nearly exact
+				tolerance = 0.1
+			}
+			checkEstimate(t, fmt.Sprintf("Count of %d at precision %d", n, p), s.Count(), uint64(n), p, tolerance)
+		}
+		if s.IsSparse() {
+			t.Errorf("Sketch of precision %d should have turned dense", p)
+		}
+	}
+}
+
+func TestSketch_Sparse(t *testing.T) {
+	s := New(14)
+	for i := 0; i < 1000; i++ {
+		s.Add(fmt.Sprint("user-", i))
+	}
+	if !s.IsSparse() {
+		t.Fatalf("1000 elements should fit the sparse representation")
+	}
+	if c := s.Count(); c < 995 || c > 1005 {
+		t.Errorf("Sparse count should be nearly exact, got %d", c)
+	}
+	dense := s.Clone()
+	dense.densify()
+	checkEstimate(t, "Count after turning dense", dense.Count(), 1000, 14, 4)
+	for i := 0; i < 1000; i++ {
+		dense.Add(fmt.Sprint("user-", i))
+	}
+	if !bytes.Equal(dense.registers, s.Clone().densify()) {
+		t.Errorf("Turning dense should give the registers of a dense sketch")
+	}
+}
+
+func TestSketch_Merge(t *testing.T) {
+	for _, n := range []int{500, 50000} {
+		a, b := New(12), New(12)
+		for i := 0; i < n; i++ {
+			a.Add(i)
+			b.Add(i + n/2)
+		}
+		// Every combination of sparse and dense
+		for _, densify := range [][2]bool{{false, false}, {true, false}, {false, true}, {true, true}} {
+			x, y := a.Clone(), b.Clone()
+			if densify[0] {
+				x.densify()
+			}
+			if densify[1] {
+				y.densify()
+			}
+			if err := x.Merge(y); err != nil {
+				t.Fatal(err)
+			}
+			checkEstimate(t, fmt.Sprintf("Merge of %v", densify), x.Count(), uint64(n+n/2), 12, 4)
+		}
+	}
+	if err := New(12).Merge(New(13)); err != ErrPrecision {
+		t.Errorf("Merging sketches of different precisions should fail, got %v", err)
+	}
+}
+
+func TestSketch_MarshalBinary(t *testing.T) {
+	for _, n := range []int{100, 100000} {
+		s := New(11)
+		for i := 0; i < n; i++ {
+			s.Add(i)
+		}
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := New(4)
+		if err := d.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if d.Precision() != 11 || d.IsSparse() != s.IsSparse() || d.Count() != s.Count() {
+			t.Errorf("Decoded sketch should match, got precision %d and count %d", d.Precision(), d.Count())
+		}
+		if again, _ := d.MarshalBinary(); !bytes.Equal(again, data) {
+			t.Errorf("Encoding should be stable")
+		}
+		if err := d.UnmarshalBinary(data[:len(data)-1]); err == nil {
+			t.Errorf("Decoding truncated data should fail")
+		}
+	}
+	if err := New(4).UnmarshalBinary([]byte{version, 30, 1}); err == nil {
+		t.Errorf("Decoding precision 30 should fail")
+	}
+}
@@ -0,0 +1,104 @@
+package hll
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/billryan/collections/set"
+)
+
+// Sketch the elements of a set at DefaultPrecision.
+func FromSet(s set.Set) *Sketch {
+	sk := New(DefaultPrecision)
+	set.Do(s, func(e interface{}) bool {
+		sk.Add(e)
+		return true
+	})
+	return sk
+}
+
+// Estimate the number of elements in the union of the sets without
+// building it. Each set is iterated once.
+func EstimateUnion(sets ...set.Set) uint64 {
+	if len(sets) == 0 {
+		return 0
+	}
+	sketches := sketchAll(sets)
+	return union(sets, sketches, 1<<len(sets)-1)
+}
+
+// MaxIntersectionSets is the largest number of sets EstimateIntersection
+// accepts.
+const MaxIntersectionSets = 20
+
+// ErrTooManySets is returned by EstimateIntersection when given more than
+// MaxIntersectionSets sets.
+var ErrTooManySets = fmt.Errorf("hll: intersection of more than %d sets", MaxIntersectionSets)
+
+// Estimate the number of elements in the intersection of the sets without
+// building it, by inclusion–exclusion over the estimated unions of every
+// combination of the sets. Each set is iterated once, but the 2^n - 1
+// unions of n sets each merge up to n sketches, so the cost doubles with
+// every set; more than MaxIntersectionSets sets return ErrTooManySets.
+// Since the error of each union estimate is relative to the union, small
+// intersections of large sets are estimated poorly.
+func EstimateIntersection(sets ...set.Set) (uint64, error) {
+	if len(sets) > MaxIntersectionSets {
+		return 0, ErrTooManySets
+	}
+	if len(sets) == 0 {
+		return 0, nil
+	}
+	smallest := uint64(sets[0].Len())
+	for _, s := range sets[1:] {
+		smallest = min(smallest, uint64(s.Len()))
+	}
+	if smallest == 0 || len(sets) == 1 {
+		return smallest, nil
+	}
+
+	sketches := sketchAll(sets)
+	n := int64(0)
+	for combination := uint(1); combination < 1<<len(sets); combination++ {
+		size := int64(union(sets, sketches, combination))
+		if bits.OnesCount(combination)%2 == 1 {
+			n += size
+		} else {
+			n -= size
+		}
+	}
+	return uint64(min(max(n, 0), int64(smallest))), nil
+}
+
+func sketchAll(sets []set.Set) []*Sketch {
+	sketches := make([]*Sketch, len(sets))
+	for i, s := range sets {
+		sketches[i] = FromSet(s)
+	}
+	return sketches
+}
+
+// Estimate the size of the union of the sets whose bits are set in
+// combination, keeping it between the size of the largest set and the sum
+// of their sizes. A single set is counted exactly.
+func union(sets []set.Set, sketches []*Sketch, combination uint) uint64 {
+	var largest, sum uint64
+	var merged *Sketch
+	for i, s := range sets {
+		if combination&(1<<i) == 0 {
+			continue
+		}
+		largest = max(largest, uint64(s.Len()))
+		sum += uint64(s.Len())
+		if merged == nil {
+			merged = sketches[i].Clone()
+		} else {
+			// Sketches of the same precision always merge
+			merged.Merge(sketches[i])
+		}
+	}
+	if bits.OnesCount(combination) == 1 {
+		return sum
+	}
+	return min(max(merged.Count(), largest), sum)
+}
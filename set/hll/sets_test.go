@@ -0,0 +1,50 @@
+package hll
+
+import (
+	"testing"
+
+	"github.com/billryan/collections/set"
+)
+
+func rangeSet(from, to int) set.Set {
+	s := set.NewHashSet()
+	for i := from; i < to; i++ {
+		s.Add(i)
+	}
+	return s
+}
+
+func TestEstimateUnion(t *testing.T) {
+	a, b, c := rangeSet(0, 20000), rangeSet(10000, 30000), rangeSet(25000, 40000)
+	checkEstimate(t, "Union", EstimateUnion(a, b, c), 40000, DefaultPrecision, 4)
+	if n := EstimateUnion(a); n != 20000 {
+		t.Errorf("Union of one set should be its length, got %d", n)
+	}
+	if n := EstimateUnion(); n != 0 {
+		t.Errorf("Union of no sets should be empty, got %d", n)
+	}
+}
+
+func TestEstimateIntersection(t *testing.T) {
+	a, b, c := rangeSet(0, 20000), rangeSet(10000, 30000), rangeSet(5000, 15000)
+	// The error is relative to the unions, 30000 here
+	if n, err := EstimateIntersection(a, b); err != nil || n < 9000 || n > 11000 {
+		t.Errorf("Intersection should be about 10000, got %d (%v)", n, err)
+	}
+	if n, err := EstimateIntersection(a, b, c); err != nil || n < 4000 || n > 6000 {
+		t.Errorf("Intersection should be about 5000, got %d (%v)", n, err)
+	}
+	if n, _ := EstimateIntersection(a, rangeSet(50000, 50100)); n > 1000 {
+		t.Errorf("Disjoint sets should have a small intersection, got %d", n)
+	}
+	if n, _ := EstimateIntersection(a, set.NewHashSet()); n != 0 {
+		t.Errorf("Intersection with an empty set should be 0, got %d", n)
+	}
+	many := make([]set.Set, MaxIntersectionSets+1)
+	for i := range many {
+		many[i] = a
+	}
+	if _, err := EstimateIntersection(many...); err != ErrTooManySets {
+		t.Errorf("Intersection of %d sets should fail, got %v", len(many), err)
+	}
+}
@@ -0,0 +1,359 @@
+// Package iblt provides invertible Bloom lookup tables for set
+// reconciliation: finding the few elements two large sets differ by while
+// exchanging data proportional to the difference, not to the sets.
+//
+// Each side builds a Sketch of its set, sized for the expected difference,
+// and sends it to the other. Subtracting the sketches cancels the elements
+// both sets share, and Decode lists the rest. When the size of the
+// difference is unknown, each side first sends an Estimator, a few
+// kilobytes, and sizes its sketch from the Estimate.
+//
+// Elements are encoded to be recovered, so only nil and the basic types but
+// complex numbers and uintptr are supported, as in the binary form of
+// set.HashSet.
+package iblt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/billryan/collections/internal/codec"
+	"github.com/billryan/collections/internal/hashing"
+	"github.com/billryan/collections/set"
+)
+
+type (
+	// Sketch is an invertible Bloom lookup table. Every element is added to
+	// one cell in each of hashes partitions of the table. A cell counts its
+	// elements and keeps the XOR of their encodings and of their hashes, so
+	// a cell left with a single element gives it back.
+	Sketch struct {
+		cells  []cell
+		hashes int
+	}
+
+	cell struct {
+		count   int64
+		keySum  []byte
+		hashSum uint64
+	}
+
+	// Difference is the decoded content of a sketch. For the difference
+	// a.Subtract(b), Local holds the elements only in a and Remote those
+	// only in b.
+	Difference struct {
+		Local, Remote set.Set
+	}
+)
+
+const (
+	// Default number of cells each element is added to
+	DefaultHashes = 4
+	// Version of the binary form
+	version = 1
+	// Increment between the hashes of the partitions, from the golden ratio
+	golden = 0x9e3779b97f4a7c15
+)
+
+var (
+	// ErrIncompatible is returned when subtracting sketches of different
+	// sizes.
+	ErrIncompatible = errors.New("iblt: sketches differ in size or number of hash functions")
+	// ErrIncomplete is returned by Decode when the difference is too large
+	// for the sketch to list it all.
+	ErrIncomplete = errors.New("iblt: difference too large for the sketch to decode")
+	// ErrCorrupt is returned by Decode when the cells of the sketch could
+	// not have been made by adding and removing elements.
+	ErrCorrupt = errors.New("iblt: corrupt sketch")
+
+	errTruncated = fmt.Errorf("iblt: %w", codec.ErrTruncated)
+)
+
+// Create a sketch of about cells cells, rounded up to a multiple of
+// DefaultHashes.
+func New(cells uint) *Sketch {
+	return NewWithSize(cells, DefaultHashes)
+}
+
+// Create a sketch adding every element to hashes cells, of about cells
+// cells, rounded up to a multiple of hashes. Panics if hashes is 0.
+func NewWithSize(cells, hashes uint) *Sketch {
+	if hashes < 1 {
+		panic("iblt: sketch without hash functions")
+	}
+	part := max((cells+hashes-1)/hashes, 1)
+	return &Sketch{cells: make([]cell, part*hashes), hashes: int(hashes)}
+}
+
+// Create a sketch that decodes a difference of up to d elements with high
+// probability.
+func ForDifference(d uint) *Sketch {
+	return New(CellsFor(d))
+}
+
+// Returns the number of cells a sketch needs to decode a difference of d
+// elements with high probability. Small tables need proportionally more
+// room, as a few unlucky collisions are enough to stop decoding.
+func CellsFor(d uint) uint {
+	return uint(1.5*float64(d) + 8*math.Sqrt(float64(d)) + 30)
+}
+
+// Adds the specified element to this sketch.
+func (s *Sketch) Add(e interface{}) error {
+	return s.update(e, 1)
+}
+
+// Adds all of the elements to this sketch, stopping at the first one that
+// cannot be encoded.
+func (s *Sketch) AddAll(es ...interface{}) error {
+	for _, e := range es {
+		if err := s.Add(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Removes the specified element from this sketch. Removing an element that
+// was not added is allowed: it then decodes as a Remote element.
+func (s *Sketch) Remove(e interface{}) error {
+	return s.update(e, -1)
+}
+
+// Returns the number of cells of the sketch.
+func (s *Sketch) Cells() int {
+	return len(s.cells)
+}
+
+// Returns the number of cells each element is added to.
+func (s *Sketch) Hashes() int {
+	return s.hashes
+}
+
+// Returns a new sketch of the elements of this sketch that are not in
+// other, minus those of other that are not in this one. Both sketches must
+// have the same size.
+func (s *Sketch) Subtract(other *Sketch) (*Sketch, error) {
+	if len(s.cells) != len(other.cells) || s.hashes != other.hashes {
+		return nil, ErrIncompatible
+	}
+	n := s.Clone()
+	for i := range n.cells {
+		c, o := &n.cells[i], &other.cells[i]
+		c.count -= o.count
+		c.hashSum ^= o.hashSum
+		c.keySum = xor(c.keySum, o.keySum)
+	}
+	return n, nil
+}
+
+// Lists the elements of the sketch, by repeatedly taking one out of a cell
+// holding a single element. On a sketch made by Subtract that gives the
+// difference of the sets. Returns ErrIncomplete along with the elements
+// found so far if cells are left holding several elements, which happens
+// when the difference is too large for the sketch, and ErrCorrupt if an
+// element comes out twice or more elements than cells come out.
+func (s *Sketch) Decode() (Difference, error) {
+	d := Difference{Local: set.NewHashSet(), Remote: set.NewHashSet()}
+	n := s.Clone()
+	queue := make([]int, len(n.cells))
+	for i := range queue {
+		queue[i] = i
+	}
+	// Taking an element out empties a cell for good, so a sketch holds no
+	// more elements than cells
+	peeled := make(map[string]bool)
+	for len(queue) > 0 {
+		i := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		c := &n.cells[i]
+		e, key, ok := c.pure()
+		if !ok {
+			continue
+		}
+		h := c.hashSum
+		indexes := n.indexes(h)
+		if !slices.Contains(indexes, i) {
+			// The cell only looks pure
+			continue
+		}
+		if peeled[string(key)] || len(peeled) == len(n.cells) {
+			return d, ErrCorrupt
+		}
+		peeled[string(key)] = true
+		if c.count > 0 {
+			d.Local.Add(e)
+		} else {
+			d.Remote.Add(e)
+		}
+		count := c.count
+		for _, j := range indexes {
+			n.cells[j].remove(key, h, count)
+			queue = append(queue, j)
+		}
+	}
+	for _, c := range n.cells {
+		if !c.empty() {
+			return d, ErrIncomplete
+		}
+	}
+	return d, nil
+}
+
+// Returns the delta turning the local set into the remote one.
+func (d Difference) Delta() set.Delta {
+	return set.Delta{Added: d.Remote, Removed: d.Local}
+}
+
+// Returns a deep clone of the sketch.
+func (s *Sketch) Clone() *Sketch {
+	n := &Sketch{cells: make([]cell, len(s.cells)), hashes: s.hashes}
+	for i, c := range s.cells {
+		n.cells[i] = cell{c.count, append([]byte(nil), c.keySum...), c.hashSum}
+	}
+	return n
+}
+
+// Encode the sketch: a version byte, the number of cells and of hash
+// functions as varints, then for each cell its count as a varint, its hash
+// sum, little-endian, and its key sum prefixed by its length.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	b := []byte{version}
+	b = binary.AppendUvarint(b, uint64(len(s.cells)))
+	b = binary.AppendUvarint(b, uint64(s.hashes))
+	for _, c := range s.cells {
+		b = binary.AppendVarint(b, c.count)
+		b = binary.LittleEndian.AppendUint64(b, c.hashSum)
+		b = binary.AppendUvarint(b, uint64(len(c.keySum)))
+		b = append(b, c.keySum...)
+	}
+	return b, nil
+}
+
+// Replace the sketch with one decoded from the form written by
+// MarshalBinary.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errTruncated
+	}
+	if data[0] != version {
+		return fmt.Errorf("iblt: unknown version %d", data[0])
+	}
+	r := codec.NewReader(data[1:])
+	n, err := readSketch(r)
+	if err == nil {
+		err = r.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("iblt: %w", err)
+	}
+	*s = *n
+	return nil
+}
+
+// Read the cells of a sketch, after its version byte
+func readSketch(r *codec.Reader) (*Sketch, error) {
+	// Every cell takes at least 10 bytes
+	cells := r.Count(10)
+	hashes := r.Uvarint()
+	if r.Err() != nil {
+		return nil, r.Err()
+	}
+	if hashes < 1 || cells < 1 || uint64(cells)%hashes != 0 {
+		return nil, fmt.Errorf("%d cells cannot be split between %d hash functions", cells, hashes)
+	}
+	s := &Sketch{cells: make([]cell, cells), hashes: int(hashes)}
+	for i := range s.cells {
+		c := &s.cells[i]
+		c.count = r.Varint()
+		c.hashSum = binary.LittleEndian.Uint64(r.Next(8))
+		c.keySum = append([]byte(nil), r.Next(r.Count(1))...)
+	}
+	return s, r.Err()
+}
+
+func (s *Sketch) update(e interface{}, count int64) error {
+	key, err := codec.AppendElement(nil, e)
+	if err != nil {
+		return fmt.Errorf("iblt: %w", err)
+	}
+	h := hashKey(key)
+	for _, i := range s.indexes(h) {
+		s.cells[i].remove(key, h, -count)
+	}
+	return nil
+}
+
+// Get the cell of each partition for an element of hash h
+func (s *Sketch) indexes(h uint64) []int {
+	part := len(s.cells) / s.hashes
+	indexes := make([]int, s.hashes)
+	for i := range indexes {
+		indexes[i] = i*part + int(hashing.Mix(h+uint64(i)*golden)%uint64(part))
+	}
+	return indexes
+}
+
+// Hash an encoded element. Hashing the encoding rather than the element
+// keeps apart elements of different types that hashing.Sum64 hashes alike.
+func hashKey(key []byte) uint64 {
+	return hashing.Sum64(key)
+}
+
+// Take count copies of an element out of the cell
+func (c *cell) remove(key []byte, h uint64, count int64) {
+	c.count -= count
+	c.hashSum ^= h
+	c.keySum = xor(c.keySum, key)
+}
+
+// Get the element of a cell holding a single one, or a single one taken
+// out, with its encoding. The encoding of an element ends where decoding
+// it stops: the rest of the key sum must be zeros, and the hash sum must
+// be its hash.
+func (c *cell) pure() (interface{}, []byte, bool) {
+	if c.count != 1 && c.count != -1 {
+		return nil, nil, false
+	}
+	r := codec.NewReader(c.keySum)
+	e := r.Element()
+	if r.Err() != nil {
+		return nil, nil, false
+	}
+	key := c.keySum[:len(c.keySum)-r.Len()]
+	for _, b := range c.keySum[len(key):] {
+		if b != 0 {
+			return nil, nil, false
+		}
+	}
+	if hashKey(key) != c.hashSum {
+		return nil, nil, false
+	}
+	return e, append([]byte(nil), key...), true
+}
+
+func (c *cell) empty() bool {
+	if c.count != 0 || c.hashSum != 0 {
+		return false
+	}
+	for _, b := range c.keySum {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// XOR b into a, as if the shorter one were padded with zeros
+func xor(a, b []byte) []byte {
+	for len(a) < len(b) {
+		a = append(a, 0)
+	}
+	for i := range b {
+		a[i] ^= b[i]
+	}
+	return a
+}
@@ -0,0 +1,181 @@
+package iblt
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/billryan/collections/set"
+)
+
+func TestNewWithSize(t *testing.T) {
+	s := NewWithSize(10, 3)
+	if s.Cells() != 12 || s.Hashes() != 3 {
+		t.Errorf("10 cells should round up to 12, got %d cells and %d hashes", s.Cells(), s.Hashes())
+	}
+	if New(0).Cells() != DefaultHashes {
+		t.Errorf("A sketch should have a cell per hash at least")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("0 hashes should panic")
+		}
+	}()
+	NewWithSize(10, 0)
+}
+
+func TestSketch_Decode(t *testing.T) {
+	a, b := ForDifference(10), ForDifference(10)
+	for i := 0; i < 1000; i++ {
+		a.Add(i)
+		b.Add(i)
+	}
+	a.AddAll("x", 0.5, nil, int64(7), "")
+	b.AddAll(1000, uint8(0), "a\x00\x00")
+	diff, err := a.Subtract(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := diff.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !set.Equal(d.Local, set.NewHashSet("x", 0.5, nil, int64(7), "")) {
+		t.Errorf("Local should be x, 0.5, nil, 7, \"\", got %v", d.Local.ToSlice())
+	}
+	if !set.Equal(d.Remote, set.NewHashSet(1000, uint8(0), "a\x00\x00")) {
+		t.Errorf("Remote should be 1000, 0, a, got %v", d.Remote.ToSlice())
+	}
+
+	// Decoding leaves the sketch alone
+	if d2, err := diff.Decode(); err != nil || !set.Equal(d2.Local, d.Local) {
+		t.Errorf("Decoding twice should give the same result, got %v, %v", d2.Local.ToSlice(), err)
+	}
+	empty, _ := a.Subtract(a)
+	if d, err := empty.Decode(); err != nil || !d.Local.IsEmpty() || !d.Remote.IsEmpty() {
+		t.Errorf("Equal sketches should have no difference, got %v, %v", d, err)
+	}
+}
+
+func TestSketch_Remove(t *testing.T) {
+	s := New(40)
+	s.AddAll(1, 2, 3)
+	s.Remove(2)
+	s.Remove(4)
+	d, err := s.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !set.Equal(d.Local, set.NewHashSet(1, 3)) || !set.Equal(d.Remote, set.NewHashSet(4)) {
+		t.Errorf("Got local %v and remote %v", d.Local.ToSlice(), d.Remote.ToSlice())
+	}
+}
+
+func TestSketch_Incomplete(t *testing.T) {
+	s := ForDifference(10)
+	for i := 0; i < 500; i++ {
+		s.Add(fmt.Sprint("id-", i))
+	}
+	d, err := s.Decode()
+	if !errors.Is(err, ErrIncomplete) {
+		t.Fatalf("Decoding 500 elements from %d cells should fail, got %v", s.Cells(), err)
+	}
+	// The partial result holds only elements of the sketch
+	d.Local.Foreach(func(e interface{}) {
+		var i int
+		if _, err := fmt.Sscanf(e.(string), "id-%d", &i); err != nil || i >= 500 {
+			t.Errorf("Decoded %v, which was never added", e)
+		}
+	})
+	if !d.Remote.IsEmpty() {
+		t.Errorf("Nothing was removed, got %v", d.Remote.ToSlice())
+	}
+}
+
+// Zero the first cell holding anything, as a corrupted transfer might
+func zeroCell(s *Sketch) {
+	for i := range s.cells {
+		if !s.cells[i].empty() {
+			s.cells[i] = cell{}
+			return
+		}
+	}
+}
+
+func TestSketch_Corrupt(t *testing.T) {
+	s := NewWithSize(8, 4)
+	s.Add(1)
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c Sketch
+	if err := c.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	zeroCell(&c)
+	if _, err := c.Decode(); err != ErrCorrupt {
+		t.Errorf("Decoding an element missing from a cell should fail, got %v", err)
+	}
+}
+
+func TestSketch_DecodeRate(t *testing.T) {
+	for _, n := range []uint{1, 10, 100, 1000} {
+		failed := 0
+		for run := 0; run < 100; run++ {
+			s := ForDifference(n)
+			for i := uint(0); i < n; i++ {
+				s.Add(fmt.Sprint(run, "-", i))
+			}
+			if d, err := s.Decode(); err != nil {
+				failed++
+			} else if d.Local.Len() != uint32(n) {
+				t.Errorf("Decoded %d elements, want %d", d.Local.Len(), n)
+			}
+		}
+		if failed > 2 {
+			t.Errorf("A difference of %d failed to decode %d times out of 100", n, failed)
+		}
+	}
+}
+
+func TestSketch_Subtract(t *testing.T) {
+	if _, err := New(12).Subtract(New(24)); err != ErrIncompatible {
+		t.Errorf("Got %v, want %v", err, ErrIncompatible)
+	}
+	if _, err := NewWithSize(12, 3).Subtract(NewWithSize(12, 4)); err != ErrIncompatible {
+		t.Errorf("Got %v, want %v", err, ErrIncompatible)
+	}
+	if err := New(12).Add([]int{1}); err == nil {
+		t.Errorf("Adding a slice should fail")
+	}
+}
+
+func TestSketch_MarshalBinary(t *testing.T) {
+	s := New(20)
+	s.AddAll(1, "two", 3.0)
+	s.Remove(4)
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n Sketch
+	if err := n.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	d, err := n.Decode()
+	if err != nil || !set.Equal(d.Local, set.NewHashSet(1, "two", 3.0)) || !set.Equal(d.Remote, set.NewHashSet(4)) {
+		t.Errorf("Decoded %v and %v, %v", d.Local.ToSlice(), d.Remote.ToSlice(), err)
+	}
+	for i := range data {
+		if err := n.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("Data truncated to %d bytes should fail", i)
+		}
+	}
+	if err := n.UnmarshalBinary(append(data, 0)); err == nil {
+		t.Errorf("Trailing data should fail")
+	}
+	if err := n.UnmarshalBinary([]byte{version, 5, 2, 0}); err == nil {
+		t.Errorf("5 cells between 2 hashes should fail")
+	}
+}
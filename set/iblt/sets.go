@@ -0,0 +1,33 @@
+package iblt
+
+import (
+	"github.com/billryan/collections/set"
+)
+
+// Sketch the elements of a set in a sketch that decodes a difference of up
+// to d elements with high probability.
+func FromSet(s set.Set, d uint) (*Sketch, error) {
+	sk := ForDifference(d)
+	if err := addSet(s, sk.Add); err != nil {
+		return nil, err
+	}
+	return sk, nil
+}
+
+// Build an estimator of the elements of a set.
+func EstimatorFromSet(s set.Set) (*Estimator, error) {
+	e := NewEstimator()
+	if err := addSet(s, e.Add); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func addSet(s set.Set, add func(interface{}) error) error {
+	var err error
+	set.Do(s, func(e interface{}) bool {
+		err = add(e)
+		return err == nil
+	})
+	return err
+}
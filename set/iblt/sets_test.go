@@ -0,0 +1,65 @@
+package iblt
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/billryan/collections/set"
+)
+
+func TestReconcile(t *testing.T) {
+	local, remote := set.NewHashSet(), set.NewConcurrentSet()
+	for i := 0; i < 10000; i++ {
+		id := fmt.Sprintf("object-%05d", i)
+		if i%500 != 0 {
+			local.Add(id)
+		}
+		if i%700 != 0 {
+			remote.Add(id)
+		}
+	}
+
+	// Each side sends its estimator, then a sketch sized from the estimate
+	localEst, err := EstimatorFromSet(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteEst, err := EstimatorFromSet(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	est, err := localEst.Estimate(remoteEst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	localSketch, _ := FromSet(local, est+est/2)
+	remoteSketch, _ := FromSet(remote, est+est/2)
+	if localSketch.Cells() != ForEstimate(est).Cells() {
+		t.Errorf("ForEstimate should give %d cells like FromSet, got %d", localSketch.Cells(), ForEstimate(est).Cells())
+	}
+	diff, err := localSketch.Subtract(remoteSketch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := diff.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := local.Difference(remote); !set.Equal(d.Local, want) {
+		t.Errorf("Local should be %v, got %v", want.ToSlice(), d.Local.ToSlice())
+	}
+	if want := remote.Difference(local); !set.Equal(d.Remote, want) {
+		t.Errorf("Remote should be %v, got %v", want.ToSlice(), d.Remote.ToSlice())
+	}
+	d.Delta().Apply(local)
+	if !set.Equal(local, remote) {
+		t.Errorf("Applying the delta should make the sets equal")
+	}
+
+	if _, err := FromSet(set.NewHashSet(struct{}{}), 1); err == nil {
+		t.Errorf("Sketching a struct should fail")
+	}
+	if _, err := EstimatorFromSet(set.NewHashSet(struct{}{})); err == nil {
+		t.Errorf("Estimating a struct should fail")
+	}
+}
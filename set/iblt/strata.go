@@ -0,0 +1,142 @@
+package iblt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"github.com/billryan/collections/internal/codec"
+	"github.com/billryan/collections/internal/hashing"
+)
+
+type (
+	// Estimator is a strata estimator: it estimates the size of the
+	// difference between two sets in a fixed amount of memory. An element
+	// lands in stratum k with probability 2^-(k+1), and each stratum is a
+	// small sketch. Decoding the differences of the strata from the sparsest
+	// down, the first stratum that fails to decode is about the one where the
+	// difference stops fitting, and the elements decoded so far, scaled up
+	// by the sampling rate, give the estimate.
+	Estimator struct {
+		strata []*Sketch
+	}
+)
+
+const (
+	// Strata of an estimator, enough for differences of billions
+	strata = 32
+	// Cells of each stratum
+	strataCells = 80
+	// Seed keeping the choice of stratum apart from the hashes of the
+	// cells
+	strataSeed = 0x5bd1e9955bd1e995
+)
+
+// Create an empty estimator.
+func NewEstimator() *Estimator {
+	e := &Estimator{strata: make([]*Sketch, strata)}
+	for i := range e.strata {
+		e.strata[i] = New(strataCells)
+	}
+	return e
+}
+
+// Adds the specified element to this estimator.
+func (e *Estimator) Add(x interface{}) error {
+	key, err := codec.AppendElement(nil, x)
+	if err != nil {
+		return fmt.Errorf("iblt: %w", err)
+	}
+	// Strata only count elements, so they hold hashes, which are short
+	h := hashKey(key)
+	stratum := min(bits.TrailingZeros64(hashing.Mix(h^strataSeed)), strata-1)
+	return e.strata[stratum].Add(h)
+}
+
+// Adds all of the elements to this estimator, stopping at the first one
+// that cannot be encoded.
+func (e *Estimator) AddAll(xs ...interface{}) error {
+	for _, x := range xs {
+		if err := e.Add(x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Estimate the number of elements in either this estimator or other but
+// not both. Differences of up to a few dozen elements are counted exactly.
+// Larger ones are typically within 20%, but can be underestimated by a
+// third, so size sketches from the estimate with headroom, as ForEstimate
+// does. Returns ErrCorrupt if a stratum of either estimator is corrupt.
+func (e *Estimator) Estimate(other *Estimator) (uint, error) {
+	if len(e.strata) != len(other.strata) {
+		return 0, ErrIncompatible
+	}
+	count := uint(0)
+	for i := len(e.strata) - 1; i >= 0; i-- {
+		diff, err := e.strata[i].Subtract(other.strata[i])
+		if err != nil {
+			return 0, err
+		}
+		d, err := diff.Decode()
+		if err == ErrCorrupt {
+			return 0, err
+		}
+		if err != nil {
+			// Strata i and below hold about 2^-i of the elements
+			return count << (i + 1), nil
+		}
+		count += uint(d.Local.Len() + d.Remote.Len())
+	}
+	return count, nil
+}
+
+// Create a sketch for a difference estimated by Estimate, with headroom for
+// the error of the estimate.
+func ForEstimate(estimate uint) *Sketch {
+	return ForDifference(estimate + estimate/2)
+}
+
+// Encode the estimator: a version byte, the number of strata, a varint,
+// and each stratum in the form of Sketch.MarshalBinary without the version
+// byte.
+func (e *Estimator) MarshalBinary() ([]byte, error) {
+	b := []byte{version}
+	b = binary.AppendUvarint(b, uint64(len(e.strata)))
+	for _, s := range e.strata {
+		data, err := s.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, data[1:]...)
+	}
+	return b, nil
+}
+
+// Replace the estimator with one decoded from the form written by
+// MarshalBinary.
+func (e *Estimator) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errTruncated
+	}
+	if data[0] != version {
+		return fmt.Errorf("iblt: unknown version %d", data[0])
+	}
+	r := codec.NewReader(data[1:])
+	// Every stratum takes at least 10 bytes
+	n := r.Count(10)
+	ss := make([]*Sketch, 0, n)
+	for i := 0; i < n && r.Err() == nil; i++ {
+		s, err := readSketch(r)
+		if err != nil {
+			return fmt.Errorf("iblt: %w", err)
+		}
+		ss = append(ss, s)
+	}
+	if err := r.Close(); err != nil {
+		return fmt.Errorf("iblt: %w", err)
+	}
+	e.strata = ss
+	return nil
+}
@@ -0,0 +1,69 @@
+package iblt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEstimator_Estimate(t *testing.T) {
+	for _, d := range []int{0, 1, 20, 1000, 20000} {
+		a, b := NewEstimator(), NewEstimator()
+		for i := 0; i < 5000; i++ {
+			a.Add(i)
+			b.Add(i)
+		}
+		for i := 0; i < d; i++ {
+			if i%2 == 0 {
+				a.Add(fmt.Sprint("a-", i))
+			} else {
+				b.Add(fmt.Sprint("b-", i))
+			}
+		}
+		est, err := a.Estimate(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d <= 20 && est != uint(d) {
+			t.Errorf("A difference of %d should be counted exactly, got %d", d, est)
+		}
+		if float64(est) < 0.5*float64(d) || float64(est) > 2*float64(d) {
+			t.Errorf("A difference of %d estimated as %d", d, est)
+		}
+	}
+}
+
+func TestEstimator_Corrupt(t *testing.T) {
+	a := NewEstimator()
+	a.Add(1)
+	for _, s := range a.strata {
+		zeroCell(s)
+	}
+	if _, err := a.Estimate(NewEstimator()); err != ErrCorrupt {
+		t.Errorf("Estimating from a corrupt estimator should fail, got %v", err)
+	}
+}
+
+func TestEstimator_MarshalBinary(t *testing.T) {
+	a, b := NewEstimator(), NewEstimator()
+	a.AddAll(1, 2, 3, 4)
+	b.AddAll(3, 4, 5)
+	data, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n Estimator
+	if err := n.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if est, err := n.Estimate(b); err != nil || est != 3 {
+		t.Errorf("Estimate should be 3, got %d, %v", est, err)
+	}
+	for _, i := range []int{0, 1, len(data) / 2, len(data) - 1} {
+		if err := n.UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("Data truncated to %d bytes should fail", i)
+		}
+	}
+	if _, err := n.Estimate(&Estimator{}); err != ErrIncompatible {
+		t.Errorf("Got %v, want %v", err, ErrIncompatible)
+	}
+}
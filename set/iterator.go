@@ -0,0 +1,33 @@
+package set
+
+type (
+	// iterator walks a snapshot of the elements of a set
+	iterator struct {
+		elements []interface{}
+		index    int
+	}
+)
+
+func newIterator(elements []interface{}) *iterator {
+	return &iterator{elements, -1}
+}
+
+func (it *iterator) Next() bool {
+	if it.index+1 >= len(it.elements) {
+		return false
+	}
+	it.index++
+	return true
+}
+
+func (it *iterator) Key() interface{} {
+	return it.elements[it.index]
+}
+
+func (it *iterator) Value() interface{} {
+	return it.elements[it.index]
+}
+
+func (it *iterator) Err() error {
+	return nil
+}
@@ -0,0 +1,347 @@
+package set
+
+import (
+	"container/list"
+	"encoding/json"
+	"iter"
+
+	"github.com/billryan/collections"
+)
+
+type (
+	// LinkedHashSet is a hash set that remembers the order its elements
+	// were added in, and iterates, lists, maps and encodes them in that
+	// order. In access order, adding an element already present moves it to
+	// the end, so that First is the least recently added one, as needed by
+	// LRU caches. Only adding counts as an access: Contains leaves the order
+	// alone, so re-add an element to mark it as used.
+	LinkedHashSet struct {
+		hash        map[interface{}]*list.Element
+		order       *list.List
+		accessOrder bool
+	}
+)
+
+// Create a new set keeping its elements in insertion order
+func NewLinkedHashSet(initial ...interface{}) *LinkedHashSet {
+	s := &LinkedHashSet{hash: make(map[interface{}]*list.Element), order: list.New()}
+
+	for _, v := range initial {
+		s.Add(v)
+	}
+
+	return s
+}
+
+// Create a new set keeping its elements in access order: adding an element
+// already present moves it to the end. Contains does not move it.
+func NewAccessOrderedSet(initial ...interface{}) *LinkedHashSet {
+	s := NewLinkedHashSet()
+	s.accessOrder = true
+
+	for _, v := range initial {
+		s.Add(v)
+	}
+
+	return s
+}
+
+// Adds the specified element to the end of this set if it is not already
+// present (optional operation). In access order, an element already present
+// is moved to the end.
+func (s *LinkedHashSet) Add(e interface{}) {
+	if el, exist := s.hash[e]; exist {
+		if s.accessOrder {
+			s.order.MoveToBack(el)
+		}
+		return
+	}
+	s.hash[e] = s.order.PushBack(e)
+}
+
+// Adds all of the elements to this set if they're not already present (optional operation).
+func (s *LinkedHashSet) AddAll(es ...interface{}) {
+	for _, e := range es {
+		s.Add(e)
+	}
+}
+
+// Removes all of the elements from this set (optional operation).
+func (s *LinkedHashSet) Clear() {
+	s.hash = make(map[interface{}]*list.Element)
+	s.order.Init()
+}
+
+// Returns true if this set contains the specified element.
+func (s *LinkedHashSet) Contains(e interface{}) bool {
+	_, exist := s.hash[e]
+	return exist
+}
+
+// Returns true if this set contains all of the elements of the specified collection.
+func (s *LinkedHashSet) ContainsAll(es ...interface{}) bool {
+	for _, e := range es {
+		if _, exist := s.hash[e]; !exist {
+			return false
+		}
+	}
+	return true
+}
+
+// Call f for each item in the set, in order
+func (s *LinkedHashSet) Foreach(f func(interface{})) {
+	s.Do(func(e interface{}) bool {
+		f(e)
+		return true
+	})
+}
+
+// Call f for each item in the set in order until it returns false. f may
+// remove the item it is given.
+func (s *LinkedHashSet) Do(f func(interface{}) bool) {
+	for el := s.order.Front(); el != nil; {
+		next := el.Next()
+		if !f(el.Value) {
+			return
+		}
+		el = next
+	}
+}
+
+// Returns a sequence over the elements in this set, in order.
+func (s *LinkedHashSet) All() iter.Seq[interface{}] {
+	return s.Do
+}
+
+// Returns a sequence over the elements in this set, in reverse order.
+func (s *LinkedHashSet) Backward() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		for el := s.order.Back(); el != nil; {
+			prev := el.Prev()
+			if !yield(el.Value) {
+				return
+			}
+			el = prev
+		}
+	}
+}
+
+// Returns an iterator over a snapshot of the elements in this set, in order.
+func (s *LinkedHashSet) Iterator() collections.Iterator {
+	return newIterator(s.ToSlice())
+}
+
+// Call f for each item in the set, set result as new key. The results are
+// kept in the order they were first produced.
+func (s *LinkedHashSet) Map(f func(interface{}) interface{}) Set {
+	n := s.empty()
+	s.Foreach(func(e interface{}) {
+		n.Add(f(e))
+	})
+	return n
+}
+
+// Returns true if this set contains no elements.
+func (s *LinkedHashSet) IsEmpty() bool {
+	return len(s.hash) == 0
+}
+
+// Removes the specified element from this set if it is present (optional operation).
+func (s *LinkedHashSet) Remove(e interface{}) bool {
+	el, exist := s.hash[e]
+	if exist {
+		s.order.Remove(el)
+		delete(s.hash, e)
+	}
+	return exist
+}
+
+// Removes the specified elements from this set if it is present (optional operation).
+// Return true if all element exist.
+func (s *LinkedHashSet) RemoveAll(es ...interface{}) bool {
+	existAll := true
+	for _, e := range es {
+		if !s.Remove(e) {
+			existAll = false
+		}
+	}
+	return existAll
+}
+
+// Return the number of elements in set s (cardinality of s).
+func (s *LinkedHashSet) Len() uint32 {
+	return uint32(len(s.hash))
+}
+
+// Returns an slice containing all of the elements in this set, in order.
+func (s *LinkedHashSet) ToSlice() []interface{} {
+	slice := make([]interface{}, 0, len(s.hash))
+	s.Foreach(func(e interface{}) {
+		slice = append(slice, e)
+	})
+	return slice
+}
+
+// Returns a deep clone of set, keeping its order and ordering mode
+func (s *LinkedHashSet) Clone() Set {
+	return union(s.empty(), s, nil)
+}
+
+// Return a new set with elements common to the set and all others, in the
+// order of the set.
+func (s *LinkedHashSet) Intersection(others ...Set) Set {
+	n := s.empty()
+	s.Do(func(e interface{}) bool {
+		for _, set := range others {
+			if !set.Contains(e) {
+				return true
+			}
+		}
+		n.Add(e)
+		return true
+	})
+	return n
+}
+
+// Return a new set with the elements of the set followed by the new
+// elements of each of the others, in their order.
+func (s *LinkedHashSet) Union(others ...Set) Set {
+	return union(s.empty(), s, others)
+}
+
+// Return a new set with elements in the set that are not in the others, in
+// the order of the set.
+func (s *LinkedHashSet) Difference(others ...Set) Set {
+	return difference(s.empty(), s, others)
+}
+
+// Return a new set with elements in either the set or other but not both,
+// those of the set first.
+func (s *LinkedHashSet) SymmetricDifference(other Set) Set {
+	return symmetricDifference(s.empty(), s, other)
+}
+
+// Test whether the set and other contain the same elements, in any order.
+func (s *LinkedHashSet) Equal(other Set) bool {
+	return equal(s, other)
+}
+
+// Test whether the set has no elements in common with other.
+func (s *LinkedHashSet) IsDisjoint(other Set) bool {
+	return isDisjoint(s, other)
+}
+
+// Test whether every element in the set is in other. set <= other
+func (s *LinkedHashSet) IsSubset(other Set) bool {
+	return isSubset(s, other)
+}
+
+// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+func (s *LinkedHashSet) IsProperSubset(other Set) bool {
+	return s.Len() < other.Len() && s.IsSubset(other)
+}
+
+// Test whether every element in other is in the set. set >= other
+func (s *LinkedHashSet) IsSuperset(other Set) bool {
+	return other.IsSubset(s)
+}
+
+// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+func (s *LinkedHashSet) IsProperSuperset(other Set) bool {
+	return s.Len() > other.Len() && s.IsSuperset(other)
+}
+
+// Replaces the elements of the set with those of a JSON array, in the
+// order of the array.
+func (s *LinkedHashSet) UnmarshalText(text []byte) error {
+	s.lazyInit()
+	var v []interface{}
+	err := json.Unmarshal(text, &v)
+	if err == nil {
+		s.Clear()
+		s.AddAll(v...)
+	}
+	return err
+}
+
+// Encode the set as a JSON array, in order.
+func (s *LinkedHashSet) MarshalText() ([]byte, error) {
+	return json.Marshal(s.ToSlice())
+}
+
+// Encode the set as a JSON array, as MarshalText does.
+func (s *LinkedHashSet) MarshalJSON() ([]byte, error) {
+	return s.MarshalText()
+}
+
+// Replaces the elements of the set with those of a JSON array, in the
+// order of the array.
+func (s *LinkedHashSet) UnmarshalJSON(data []byte) error {
+	s.lazyInit()
+	return s.UnmarshalText(data)
+}
+
+// Encode the set with gob, which keeps the types of the elements. Types
+// other than the basic ones must be registered with gob.Register.
+func (s *LinkedHashSet) GobEncode() ([]byte, error) {
+	return gobEncode(s.ToSlice())
+}
+
+// Replaces the elements of the set with those encoded by GobEncode.
+func (s *LinkedHashSet) GobDecode(data []byte) error {
+	s.lazyInit()
+	return decodeInto(s, data, gobDecode)
+}
+
+// Encode the set in a compact binary form. Only nil and basic types other
+// than complex numbers and uintptr can be encoded.
+func (s *LinkedHashSet) MarshalBinary() ([]byte, error) {
+	return marshalBinary(s.ToSlice())
+}
+
+// Replaces the elements of the set with those encoded by MarshalBinary.
+func (s *LinkedHashSet) UnmarshalBinary(data []byte) error {
+	s.lazyInit()
+	return decodeInto(s, data, unmarshalBinary)
+}
+
+// Returns the first element in this set.
+func (s *LinkedHashSet) First() (interface{}, bool) {
+	if el := s.order.Front(); el != nil {
+		return el.Value, true
+	}
+	return nil, false
+}
+
+// Returns the last element in this set.
+func (s *LinkedHashSet) Last() (interface{}, bool) {
+	if el := s.order.Back(); el != nil {
+		return el.Value, true
+	}
+	return nil, false
+}
+
+// Removes and returns the first element in this set. In access order this
+// is the least recently added one.
+func (s *LinkedHashSet) PopFirst() (interface{}, bool) {
+	e, ok := s.First()
+	if ok {
+		s.Remove(e)
+	}
+	return e, ok
+}
+
+// Create an empty set with the same ordering mode as s
+func (s *LinkedHashSet) empty() *LinkedHashSet {
+	n := NewLinkedHashSet()
+	n.accessOrder = s.accessOrder
+	return n
+}
+
+// Give a zero LinkedHashSet its map and list, so that it can be decoded into
+func (s *LinkedHashSet) lazyInit() {
+	if s.hash == nil {
+		s.hash = make(map[interface{}]*list.Element)
+		s.order = list.New()
+	}
+}
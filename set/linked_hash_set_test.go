@@ -0,0 +1,120 @@
+package set
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func newLinkedHashSet(initial ...interface{}) Set {
+	return NewLinkedHashSet(initial...)
+}
+
+func TestLinkedHashSet_Order(t *testing.T) {
+	s := NewLinkedHashSet("c", "a", "b", "a")
+	s.Add("c")
+	want := []interface{}{"c", "a", "b"}
+	if !reflect.DeepEqual(s.ToSlice(), want) {
+		t.Errorf("Elements should keep insertion order %v, got %v", want, s.ToSlice())
+	}
+	var seen []interface{}
+	s.Foreach(func(e interface{}) { seen = append(seen, e) })
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("Foreach should follow insertion order, got %v", seen)
+	}
+	var backward []interface{}
+	for e := range s.Backward() {
+		backward = append(backward, e)
+	}
+	if !reflect.DeepEqual(backward, []interface{}{"b", "a", "c"}) {
+		t.Errorf("Backward should reverse the order, got %v", backward)
+	}
+
+	s.Remove("a")
+	s.Add("a")
+	if !reflect.DeepEqual(s.ToSlice(), []interface{}{"c", "b", "a"}) {
+		t.Errorf("A removed element should come back last, got %v", s.ToSlice())
+	}
+	s.Do(func(e interface{}) bool {
+		s.Remove(e)
+		return true
+	})
+	if !s.IsEmpty() {
+		t.Errorf("Do should allow removing the current element, left %v", s.ToSlice())
+	}
+}
+
+func TestLinkedHashSet_AccessOrder(t *testing.T) {
+	s := NewAccessOrderedSet(1, 2, 3)
+	s.Add(1)
+	if s.Contains(2); !reflect.DeepEqual(s.ToSlice(), []interface{}{2, 3, 1}) {
+		t.Errorf("Re-adding should move to the end and Contains should not, got %v", s.ToSlice())
+	}
+	if e, ok := s.PopFirst(); !ok || e != 2 {
+		t.Errorf("PopFirst should evict the least recently added, got %v", e)
+	}
+	if c := s.Clone().(*LinkedHashSet); !c.accessOrder {
+		t.Errorf("Clone should keep access order")
+	}
+}
+
+func TestLinkedHashSet_Ends(t *testing.T) {
+	s := NewLinkedHashSet()
+	if _, ok := s.First(); ok {
+		t.Errorf("Empty set should have no first element")
+	}
+	if _, ok := s.PopFirst(); ok {
+		t.Errorf("Empty set should have nothing to pop")
+	}
+	s.AddAll(5, 6, 7)
+	first, _ := s.First()
+	last, _ := s.Last()
+	if first != 5 || last != 7 {
+		t.Errorf("First and last should be 5 and 7, got %v and %v", first, last)
+	}
+	if e, _ := s.PopFirst(); e != 5 || s.Len() != 2 {
+		t.Errorf("PopFirst should remove 5, got %v", e)
+	}
+}
+
+func TestLinkedHashSet_Algebra(t *testing.T) {
+	s := NewLinkedHashSet(3, 1, 2)
+	if u := s.Union(NewLinkedHashSet(5, 1, 4)); !reflect.DeepEqual(u.ToSlice(), []interface{}{3, 1, 2, 5, 4}) {
+		t.Errorf("Union should keep the order of its operands, got %v", u.ToSlice())
+	}
+	if i := s.Intersection(NewHashSet(2, 3)); !reflect.DeepEqual(i.ToSlice(), []interface{}{3, 2}) {
+		t.Errorf("Intersection should keep the order of the set, got %v", i.ToSlice())
+	}
+	if d := s.Difference(NewHashSet(1)); !reflect.DeepEqual(d.ToSlice(), []interface{}{3, 2}) {
+		t.Errorf("Difference should keep the order of the set, got %v", d.ToSlice())
+	}
+	m := s.Map(func(e interface{}) interface{} { return e.(int) * 10 })
+	if !reflect.DeepEqual(m.ToSlice(), []interface{}{30, 10, 20}) {
+		t.Errorf("Map should keep the order of the set, got %v", m.ToSlice())
+	}
+}
+
+func TestLinkedHashSet_JSON(t *testing.T) {
+	s := NewLinkedHashSet("z", "x", "y")
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `["z","x","y"]` {
+		t.Errorf("Encoding should keep the order, got %s", data)
+	}
+	var d LinkedHashSet
+	if err := json.Unmarshal(data, &d); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(d.ToSlice(), s.ToSlice()) {
+		t.Errorf("Decoding should keep the order, got %v", d.ToSlice())
+	}
+	if err := d.UnmarshalText([]byte(`["b","a"]`)); err != nil || !reflect.DeepEqual(d.ToSlice(), []interface{}{"b", "a"}) {
+		t.Errorf("UnmarshalText should replace the elements in order, got %v", d.ToSlice())
+	}
+	var z LinkedHashSet
+	if err := z.UnmarshalText([]byte(`["b","a"]`)); err != nil || !reflect.DeepEqual(z.ToSlice(), []interface{}{"b", "a"}) {
+		t.Errorf("UnmarshalText should decode into a zero set, got %v", z.ToSlice())
+	}
+}
@@ -0,0 +1,284 @@
+package set
+
+import (
+	"cmp"
+	"encoding/json"
+	"iter"
+	"slices"
+
+	"github.com/billryan/collections"
+	"github.com/billryan/collections/internal/codec"
+)
+
+type (
+	// MultiSet, also known as a bag, is a collection that counts how many
+	// times each element was added. It is not a Set, since adding an
+	// element takes a count, but Distinct gives the Set of its elements.
+	MultiSet struct {
+		counts map[interface{}]uint
+		size   uint
+	}
+
+	multiSetEntryJSON struct {
+		Element json.RawMessage `json:"element"`
+		Count   uint            `json:"count"`
+	}
+)
+
+// Create a new multiset, adding each initial element once
+func NewMultiSet(initial ...interface{}) *MultiSet {
+	m := &MultiSet{counts: make(map[interface{}]uint)}
+
+	for _, v := range initial {
+		m.Add(v, 1)
+	}
+
+	return m
+}
+
+// Adds n occurrences of the specified element.
+func (m *MultiSet) Add(e interface{}, n uint) {
+	if n == 0 {
+		return
+	}
+	m.counts[e] += n
+	m.size += n
+}
+
+// Removes up to n occurrences of the specified element. Returns the number
+// removed.
+func (m *MultiSet) Remove(e interface{}, n uint) uint {
+	count := m.counts[e]
+	n = min(n, count)
+	m.SetCount(e, count-n)
+	return n
+}
+
+// Sets the number of occurrences of the specified element, removing it if
+// n is 0.
+func (m *MultiSet) SetCount(e interface{}, n uint) {
+	m.size = m.size - m.counts[e] + n
+	if n == 0 {
+		delete(m.counts, e)
+	} else {
+		m.counts[e] = n
+	}
+}
+
+// Returns the number of occurrences of the specified element.
+func (m *MultiSet) Count(e interface{}) uint {
+	return m.counts[e]
+}
+
+// Returns true if the multiset holds at least one occurrence of the
+// specified element.
+func (m *MultiSet) Contains(e interface{}) bool {
+	return m.counts[e] > 0
+}
+
+// Removes all of the elements from this multiset.
+func (m *MultiSet) Clear() {
+	m.counts = make(map[interface{}]uint)
+	m.size = 0
+}
+
+// Returns true if this multiset contains no elements.
+func (m *MultiSet) IsEmpty() bool {
+	return m.size == 0
+}
+
+// Return the number of occurrences of all elements.
+func (m *MultiSet) Len() uint32 {
+	return uint32(m.size)
+}
+
+// Returns the set of distinct elements of this multiset.
+func (m *MultiSet) Distinct() Set {
+	n := NewHashSet()
+	for e := range m.counts {
+		n.Add(e)
+	}
+	return n
+}
+
+// Call f for each occurrence of each element until it returns false. This
+// makes a multiset a collections.Collection.
+func (m *MultiSet) Do(f func(interface{}) bool) {
+	for e, n := range m.counts {
+		for i := uint(0); i < n; i++ {
+			if !f(e) {
+				return
+			}
+		}
+	}
+}
+
+// Returns a sequence over each occurrence of each element.
+func (m *MultiSet) All() iter.Seq[interface{}] {
+	return m.Do
+}
+
+// Returns a sequence over the distinct elements with their counts.
+func (m *MultiSet) Counts() iter.Seq2[interface{}, uint] {
+	return func(yield func(interface{}, uint) bool) {
+		for e, n := range m.counts {
+			if !yield(e, n) {
+				return
+			}
+		}
+	}
+}
+
+// Returns the k elements with the highest counts, most common first, as
+// entries whose value is the count. Elements with equal counts come in the
+// order the encoders of the package sort elements in. A negative k returns
+// all elements.
+func (m *MultiSet) MostCommon(k int) []collections.Entry {
+	entries := make([]collections.Entry, 0, len(m.counts))
+	for e, n := range m.counts {
+		entries = append(entries, collections.Entry{Key: e, Value: n})
+	}
+	slices.SortFunc(entries, func(a, b collections.Entry) int {
+		if c := cmp.Compare(b.Value.(uint), a.Value.(uint)); c != 0 {
+			return c
+		}
+		return codec.Compare(a.Key, b.Key)
+	})
+	if k >= 0 && k < len(entries) {
+		entries = entries[:k]
+	}
+	return entries
+}
+
+// Returns a deep clone of the multiset.
+func (m *MultiSet) Clone() *MultiSet {
+	n := NewMultiSet()
+	for e, c := range m.counts {
+		n.counts[e] = c
+	}
+	n.size = m.size
+	return n
+}
+
+// Return a new multiset counting each element as many times as the
+// multiset or any of the others at most does.
+func (m *MultiSet) Union(others ...*MultiSet) *MultiSet {
+	n := m.Clone()
+	for _, o := range others {
+		for e, c := range o.counts {
+			if c > n.counts[e] {
+				n.SetCount(e, c)
+			}
+		}
+	}
+	return n
+}
+
+// Return a new multiset counting each element as many times as the
+// multiset and all of the others do together.
+func (m *MultiSet) Sum(others ...*MultiSet) *MultiSet {
+	n := m.Clone()
+	for _, o := range others {
+		for e, c := range o.counts {
+			n.Add(e, c)
+		}
+	}
+	return n
+}
+
+// Return a new multiset counting each element as many times as the
+// multiset and all of the others at least do.
+func (m *MultiSet) Intersection(others ...*MultiSet) *MultiSet {
+	n := NewMultiSet()
+	for e, c := range m.counts {
+		for _, o := range others {
+			c = min(c, o.counts[e])
+		}
+		n.Add(e, c)
+	}
+	return n
+}
+
+// Return a new multiset counting each element as many times as the
+// multiset does, less the times the others do, down to zero.
+func (m *MultiSet) Difference(others ...*MultiSet) *MultiSet {
+	n := m.Clone()
+	for _, o := range others {
+		for e, c := range o.counts {
+			n.Remove(e, c)
+		}
+	}
+	return n
+}
+
+// Test whether the multiset and other count every element the same.
+func (m *MultiSet) Equal(other *MultiSet) bool {
+	if m.size != other.size || len(m.counts) != len(other.counts) {
+		return false
+	}
+	for e, c := range m.counts {
+		if other.counts[e] != c {
+			return false
+		}
+	}
+	return true
+}
+
+// Encode the multiset as a JSON array of objects holding an element and
+// its count, sorted by element so that equal multisets encode alike.
+func (m *MultiSet) MarshalText() ([]byte, error) {
+	keys := make([]interface{}, 0, len(m.counts))
+	for e := range m.counts {
+		keys = append(keys, e)
+	}
+	slices.SortFunc(keys, codec.Compare)
+	entries := make([]multiSetEntryJSON, len(keys))
+	for i, e := range keys {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = multiSetEntryJSON{data, m.counts[e]}
+	}
+	return json.Marshal(entries)
+}
+
+// Replaces the elements of the multiset with those of the form written by
+// MarshalText. Numbers are decoded as float64s, as encoding/json does.
+func (m *MultiSet) UnmarshalText(text []byte) error {
+	return m.UnmarshalJSONWith(text, DecodeAs[interface{}])
+}
+
+// Encode the multiset as MarshalText does.
+func (m *MultiSet) MarshalJSON() ([]byte, error) {
+	return m.MarshalText()
+}
+
+// Replaces the elements of the multiset with those of the form written by
+// MarshalJSON, as UnmarshalText does.
+func (m *MultiSet) UnmarshalJSON(data []byte) error {
+	return m.UnmarshalText(data)
+}
+
+// Replaces the elements of the multiset with those of the form written by
+// MarshalJSON, decoding each element with decode. The counts of elements
+// listed more than once add up.
+func (m *MultiSet) UnmarshalJSONWith(data []byte, decode ElementDecoder) error {
+	var entries []multiSetEntryJSON
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	es := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		e, err := decode(entry.Element)
+		if err != nil {
+			return err
+		}
+		es[i] = e
+	}
+	m.Clear()
+	for i, e := range es {
+		m.Add(e, entries[i].Count)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package set
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/billryan/collections"
+)
+
+func TestMultiSet_Counts(t *testing.T) {
+	m := NewMultiSet("a", "b", "a")
+	m.Add("c", 3)
+	m.Add("d", 0)
+	if m.Count("a") != 2 || m.Count("c") != 3 || m.Count("d") != 0 || m.Contains("d") {
+		t.Errorf("Counts should be a:2 b:1 c:3, got %v", m.counts)
+	}
+	if m.Len() != 6 {
+		t.Errorf("Length should count every occurrence, got %d", m.Len())
+	}
+	if n := m.Remove("c", 2); n != 2 || m.Count("c") != 1 {
+		t.Errorf("Removing 2 of 3 should leave 1, removed %d", n)
+	}
+	if n := m.Remove("a", 5); n != 2 || m.Contains("a") {
+		t.Errorf("Removing more than there are should remove all, removed %d", n)
+	}
+	if m.Len() != 2 || !Equal(m.Distinct(), NewHashSet("b", "c")) {
+		t.Errorf("Multiset should be b:1 c:1, got %v", m.counts)
+	}
+	m.SetCount("b", 4)
+	m.SetCount("c", 0)
+	if m.Len() != 4 || m.Contains("c") {
+		t.Errorf("SetCount should replace counts, got %v", m.counts)
+	}
+	occurrences := 0
+	m.Do(func(e interface{}) bool {
+		occurrences++
+		return true
+	})
+	if occurrences != 4 {
+		t.Errorf("Do should visit each occurrence, got %d", occurrences)
+	}
+	m.Clear()
+	if !m.IsEmpty() || m.Len() != 0 {
+		t.Errorf("Cleared multiset should be empty")
+	}
+}
+
+func TestMultiSet_Algebra(t *testing.T) {
+	a, b := NewMultiSet(), NewMultiSet()
+	a.Add("x", 3)
+	a.Add("y", 1)
+	b.Add("x", 1)
+	b.Add("y", 2)
+	b.Add("z", 1)
+	counts := func(x, y, z uint) *MultiSet {
+		m := NewMultiSet()
+		m.Add("x", x)
+		m.Add("y", y)
+		m.Add("z", z)
+		return m
+	}
+	for _, c := range []struct {
+		name      string
+		got, want *MultiSet
+	}{
+		{"Union", a.Union(b), counts(3, 2, 1)},
+		{"Sum", a.Sum(b), counts(4, 3, 1)},
+		{"Intersection", a.Intersection(b), counts(1, 1, 0)},
+		{"Difference", a.Difference(b), counts(2, 0, 0)},
+	} {
+		if !c.got.Equal(c.want) {
+			t.Errorf("%s should be %v, got %v", c.name, c.want.counts, c.got.counts)
+		}
+	}
+	if !a.Equal(counts(3, 1, 0)) {
+		t.Errorf("Algebra should leave the multiset alone, got %v", a.counts)
+	}
+}
+
+func TestMultiSet_MostCommon(t *testing.T) {
+	m := NewMultiSet()
+	m.Add("a", 1)
+	m.Add("b", 5)
+	m.Add("c", 3)
+	want := []collections.Entry{{Key: "b", Value: uint(5)}, {Key: "c", Value: uint(3)}}
+	if got := m.MostCommon(2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Two most common should be %v, got %v", want, got)
+	}
+	if got := m.MostCommon(-1); len(got) != 3 {
+		t.Errorf("A negative k should return all elements, got %v", got)
+	}
+	m.Add("d", 5)
+	m.Add("a", 4)
+	want = []collections.Entry{{Key: "a", Value: uint(5)}, {Key: "b", Value: uint(5)}, {Key: "d", Value: uint(5)}}
+	for i := 0; i < 10; i++ {
+		if got := m.MostCommon(3); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Ties should be sorted by element, got %v", got)
+		}
+	}
+}
+
+func TestMultiSet_JSON(t *testing.T) {
+	m := NewMultiSet("b", "a", "b")
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `[{"element":"a","count":1},{"element":"b","count":2}]`; string(data) != want {
+		t.Errorf("Encoding should be %s, got %s", want, data)
+	}
+	if text, _ := m.MarshalText(); !bytes.Equal(text, data) {
+		t.Errorf("MarshalText should encode as MarshalJSON, got %s", text)
+	}
+	var d MultiSet
+	if err := json.Unmarshal(data, &d); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Equal(m) {
+		t.Errorf("Decoding should give back the multiset, got %v", d.counts)
+	}
+
+	// Elements printing alike keep their types
+	m = NewMultiSet(1, "1", 1)
+	data, err = m.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.UnmarshalJSONWith(data, DecodeNumbers); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Equal(m) || d.Count(1) != 2 || d.Count("1") != 1 {
+		t.Errorf("Decoding should give back the multiset, got %v", d.counts)
+	}
+	if err := d.UnmarshalText([]byte(`{"a":2}`)); err == nil || d.Len() != 3 {
+		t.Errorf("Decoding an object should fail and leave the multiset unchanged")
+	}
+}
@@ -0,0 +1,423 @@
+package set
+
+import (
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/billryan/collections"
+)
+
+type (
+	// ObservableSet wraps a Set and notifies subscribers of every change made
+	// through it. Changes are serialized, and each subscriber sees their
+	// events in the order the changes were made. Changes made to the wrapped
+	// set directly are not observed.
+	//
+	// The wrapper adds no safety for readers: wrap a ConcurrentSet or a
+	// ShardedSet to read while other goroutines change the set.
+	ObservableSet struct {
+		set Set
+		// Held while changing the set and delivering the events
+		mu     sync.Mutex
+		subsMu sync.RWMutex
+		subs   []*Subscription
+	}
+
+	// EventKind tells what kind of change an Event reports.
+	EventKind uint8
+
+	// Event reports a change to an ObservableSet. Added and Removed events
+	// list the elements that actually changed, so adding a present element
+	// emits nothing, and AddAll and RemoveAll emit a single event for the
+	// whole batch. A Cleared event lists the elements the set held.
+	Event struct {
+		Kind     EventKind
+		Elements []interface{}
+	}
+
+	// DropPolicy tells what a channel subscription does with an event when
+	// its buffer is full.
+	DropPolicy uint8
+
+	// Subscription is a subscriber of an ObservableSet, receiving events
+	// through a callback or a channel until it is cancelled.
+	Subscription struct {
+		set    *ObservableSet
+		f      func(Event)
+		ch     chan Event
+		policy DropPolicy
+		// Guards ch against being closed while an event is sent
+		mu        sync.Mutex
+		done      chan struct{}
+		once      sync.Once
+		cancelled atomic.Bool
+		dropped   atomic.Uint64
+	}
+)
+
+const (
+	Added EventKind = iota
+	Removed
+	Cleared
+)
+
+const (
+	// Block waits for the subscriber to receive the event, holding up
+	// changes to the set meanwhile.
+	Block DropPolicy = iota
+	// DropNewest discards the event that does not fit.
+	DropNewest
+	// DropOldest discards the oldest buffered event to make room. Without
+	// a buffer it discards the new event.
+	DropOldest
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Cleared:
+		return "Cleared"
+	}
+	return "EventKind(?)"
+}
+
+// Create a new observable set wrapping s
+func NewObservableSet(s Set) *ObservableSet {
+	return &ObservableSet{set: s}
+}
+
+// Subscribe f to the changes of the set. f is called synchronously after
+// each change, in order, and must not change the set.
+func (s *ObservableSet) Subscribe(f func(Event)) *Subscription {
+	return s.subscribe(&Subscription{f: f})
+}
+
+// Subscribe a channel of the given buffer size to the changes of the set.
+// When the buffer is full the event is handled according to policy. The
+// channel is closed by Cancel.
+func (s *ObservableSet) SubscribeChan(buffer int, policy DropPolicy) *Subscription {
+	return s.subscribe(&Subscription{ch: make(chan Event, buffer), policy: policy})
+}
+
+func (s *ObservableSet) subscribe(sub *Subscription) *Subscription {
+	sub.set = s
+	sub.done = make(chan struct{})
+	s.subsMu.Lock()
+	defer s.subsMu.Unlock()
+	s.subs = append(s.subs, sub)
+	return sub
+}
+
+// Returns the wrapped set. Changes made to it directly are not observed.
+func (s *ObservableSet) Unwrap() Set {
+	return s.set
+}
+
+// Adds the specified element to this set if it is not already present (optional operation).
+func (s *ObservableSet) Add(e interface{}) {
+	s.AddAll(e)
+}
+
+// Adds all of the elements to this set if they're not already present (optional operation).
+// Emits one Added event with the elements that were not present.
+func (s *ObservableSet) AddAll(es ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var added []interface{}
+	for _, e := range es {
+		if !s.set.Contains(e) {
+			s.set.Add(e)
+			added = append(added, e)
+		}
+	}
+	s.publish(Added, added)
+}
+
+// Removes all of the elements from this set (optional operation).
+// Emits a Cleared event unless the set was empty.
+func (s *ObservableSet) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	elements := s.set.ToSlice()
+	s.set.Clear()
+	s.publish(Cleared, elements)
+}
+
+// Returns true if this set contains the specified element.
+func (s *ObservableSet) Contains(e interface{}) bool {
+	return s.set.Contains(e)
+}
+
+// Returns true if this set contains all of the elements of the specified collection.
+func (s *ObservableSet) ContainsAll(es ...interface{}) bool {
+	return s.set.ContainsAll(es...)
+}
+
+// Call f for each item in the set
+func (s *ObservableSet) Foreach(f func(interface{})) {
+	s.set.Foreach(f)
+}
+
+// Call f for each item in the set until it returns false
+func (s *ObservableSet) Do(f func(interface{}) bool) {
+	Do(s.set, f)
+}
+
+// Returns a sequence over the elements in this set.
+func (s *ObservableSet) All() iter.Seq[interface{}] {
+	return All(s.set)
+}
+
+// Returns an iterator over a snapshot of the elements in this set.
+func (s *ObservableSet) Iterator() collections.Iterator {
+	return IteratorOf(s.set)
+}
+
+// Call f for each item in the set, set result as new key. The result is
+// of the wrapped implementation and not observed.
+func (s *ObservableSet) Map(f func(interface{}) interface{}) Set {
+	return s.set.Map(f)
+}
+
+// Returns true if this set contains no elements.
+func (s *ObservableSet) IsEmpty() bool {
+	return s.set.IsEmpty()
+}
+
+// Removes the specified element from this set if it is present (optional operation).
+func (s *ObservableSet) Remove(e interface{}) bool {
+	return s.RemoveAll(e)
+}
+
+// Removes the specified elements from this set if it is present (optional operation).
+// Return true if all element exist. Emits one Removed event with the
+// elements that were present.
+func (s *ObservableSet) RemoveAll(es ...interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var removed []interface{}
+	existAll := true
+	for _, e := range es {
+		if s.set.Remove(e) {
+			removed = append(removed, e)
+		} else {
+			existAll = false
+		}
+	}
+	s.publish(Removed, removed)
+	return existAll
+}
+
+// Return the number of elements in set s (cardinality of s).
+func (s *ObservableSet) Len() uint32 {
+	return s.set.Len()
+}
+
+// Returns an slice containing all of the elements in this set.
+func (s *ObservableSet) ToSlice() []interface{} {
+	return s.set.ToSlice()
+}
+
+// Returns a deep clone of the wrapped set, without the subscribers
+func (s *ObservableSet) Clone() Set {
+	return s.set.Clone()
+}
+
+// Return a new set with elements common to the set and all others.
+// The result has the wrapped implementation.
+func (s *ObservableSet) Intersection(others ...Set) Set {
+	return s.set.Intersection(others...)
+}
+
+// Return a new set with elements from the set and all others.
+// The result has the wrapped implementation.
+func (s *ObservableSet) Union(others ...Set) Set {
+	return s.set.Union(others...)
+}
+
+// Return a new set with elements in the set that are not in the others.
+// The result has the wrapped implementation.
+func (s *ObservableSet) Difference(others ...Set) Set {
+	return s.set.Difference(others...)
+}
+
+// Return a new set with elements in either the set or other but not both.
+// The result has the wrapped implementation.
+func (s *ObservableSet) SymmetricDifference(other Set) Set {
+	return SymmetricDifference(s.set, other)
+}
+
+// Test whether the set and other contain the same elements.
+func (s *ObservableSet) Equal(other Set) bool {
+	return Equal(s.set, other)
+}
+
+// Test whether the set has no elements in common with other.
+func (s *ObservableSet) IsDisjoint(other Set) bool {
+	return IsDisjoint(s.set, other)
+}
+
+// Test whether every element in the set is in other. set <= other
+func (s *ObservableSet) IsSubset(other Set) bool {
+	return s.set.IsSubset(other)
+}
+
+// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+func (s *ObservableSet) IsProperSubset(other Set) bool {
+	return s.set.IsProperSubset(other)
+}
+
+// Test whether every element in other is in the set. set >= other
+func (s *ObservableSet) IsSuperset(other Set) bool {
+	return s.set.IsSuperset(other)
+}
+
+// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+func (s *ObservableSet) IsProperSuperset(other Set) bool {
+	return s.set.IsProperSuperset(other)
+}
+
+// Replaces the elements of the set with those of a JSON array, decoded as
+// the wrapped set would. Emits a Removed event for the elements that are
+// gone and an Added event for the new ones.
+func (s *ObservableSet) UnmarshalText(text []byte) error {
+	n := s.set.Clone()
+	n.Clear()
+	if err := n.UnmarshalText(text); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var added, removed []interface{}
+	s.set.Foreach(func(e interface{}) {
+		if !n.Contains(e) {
+			removed = append(removed, e)
+		}
+	})
+	n.Foreach(func(e interface{}) {
+		if !s.set.Contains(e) {
+			added = append(added, e)
+		}
+	})
+	s.set.RemoveAll(removed...)
+	s.publish(Removed, removed)
+	s.set.AddAll(added...)
+	s.publish(Added, added)
+	return nil
+}
+
+// Encode the set as the wrapped set does.
+func (s *ObservableSet) MarshalText() ([]byte, error) {
+	return marshalText(s.set)
+}
+
+// Encode the set as a JSON array, as MarshalText does.
+func (s *ObservableSet) MarshalJSON() ([]byte, error) {
+	return marshalText(s.set)
+}
+
+// Replaces the elements of the set with those of a JSON array, as
+// UnmarshalText does.
+func (s *ObservableSet) UnmarshalJSON(data []byte) error {
+	return s.UnmarshalText(data)
+}
+
+// Deliver an event to every subscriber, unless it has no elements. Must be
+// called with s.mu held.
+func (s *ObservableSet) publish(kind EventKind, elements []interface{}) {
+	if len(elements) == 0 {
+		return
+	}
+	s.subsMu.RLock()
+	subs := make([]*Subscription, len(s.subs))
+	copy(subs, s.subs)
+	s.subsMu.RUnlock()
+
+	ev := Event{kind, elements}
+	for _, sub := range subs {
+		sub.deliver(ev)
+	}
+}
+
+// Returns the channel the events are sent to, or nil for a callback
+// subscription.
+func (sub *Subscription) Events() <-chan Event {
+	return sub.ch
+}
+
+// Returns the number of events dropped because the buffer was full.
+func (sub *Subscription) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
+// Stop delivering events to the subscriber and close its channel. It may be
+// called more than once, and from within the callback.
+func (sub *Subscription) Cancel() {
+	sub.once.Do(func() {
+		sub.cancelled.Store(true)
+		// Release a delivery blocked on a full channel
+		close(sub.done)
+
+		s := sub.set
+		s.subsMu.Lock()
+		for i, o := range s.subs {
+			if o == sub {
+				s.subs = slices.Delete(s.subs, i, i+1)
+				break
+			}
+		}
+		s.subsMu.Unlock()
+
+		if sub.ch != nil {
+			sub.mu.Lock()
+			close(sub.ch)
+			sub.mu.Unlock()
+		}
+	})
+}
+
+func (sub *Subscription) deliver(ev Event) {
+	if sub.f != nil {
+		if !sub.cancelled.Load() {
+			sub.f(ev)
+		}
+		return
+	}
+
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	if sub.cancelled.Load() {
+		return
+	}
+	switch {
+	case sub.policy == DropNewest, sub.policy == DropOldest && cap(sub.ch) == 0:
+		select {
+		case sub.ch <- ev:
+		default:
+			sub.dropped.Add(1)
+		}
+	case sub.policy == DropOldest:
+		for {
+			select {
+			case sub.ch <- ev:
+				return
+			default:
+			}
+			select {
+			case <-sub.ch:
+				sub.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case sub.ch <- ev:
+		case <-sub.done:
+		}
+	}
+}
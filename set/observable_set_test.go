@@ -0,0 +1,214 @@
+package set
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Collects the events delivered to a callback
+type recorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *recorder) record(ev Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+func sortedInts(es []interface{}) []int {
+	ints := make([]int, len(es))
+	for i, e := range es {
+		ints[i] = e.(int)
+	}
+	slices.Sort(ints)
+	return ints
+}
+
+func TestObservableSet_Events(t *testing.T) {
+	for _, impl := range []struct {
+		name string
+		new  func(initial ...interface{}) Set
+	}{
+		{"HashSet", NewHashSet},
+		{"ConcurrentSet", NewConcurrentSet},
+	} {
+		t.Run(impl.name, func(t *testing.T) {
+			s := NewObservableSet(impl.new(1))
+			r := &recorder{}
+			s.Subscribe(r.record)
+
+			s.Add(1)
+			s.AddAll(1, 2, 3, 2)
+			s.Remove(5)
+			s.RemoveAll(1, 3, 4)
+			s.Clear()
+			s.Clear()
+
+			want := []struct {
+				kind     EventKind
+				elements []int
+			}{
+				{Added, []int{2, 3}},
+				{Removed, []int{1, 3}},
+				{Cleared, []int{2}},
+			}
+			if len(r.events) != len(want) {
+				t.Fatalf("Got %d events %v, want %d", len(r.events), r.events, len(want))
+			}
+			for i, w := range want {
+				ev := r.events[i]
+				if ev.Kind != w.kind || !slices.Equal(sortedInts(ev.Elements), w.elements) {
+					t.Errorf("Event %d is %v %v, want %v %v", i, ev.Kind, ev.Elements, w.kind, w.elements)
+				}
+			}
+			if !s.IsEmpty() || !s.Unwrap().IsEmpty() {
+				t.Error("Set should be empty")
+			}
+		})
+	}
+}
+
+func TestObservableSet_UnmarshalText(t *testing.T) {
+	s := NewObservableSet(NewHashSet("a", "b"))
+	r := &recorder{}
+	s.Subscribe(r.record)
+	if err := s.UnmarshalText([]byte(`["b", "c"]`)); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Equal(NewHashSet("b", "c")) {
+		t.Errorf("Set should be b, c, got %v", s.ToSlice())
+	}
+	if len(r.events) != 2 ||
+		r.events[0].Kind != Removed || r.events[0].Elements[0] != "a" ||
+		r.events[1].Kind != Added || r.events[1].Elements[0] != "c" {
+		t.Errorf("Events should remove a and add c, got %v", r.events)
+	}
+
+	if s.UnmarshalText([]byte(`{`)) == nil || s.Len() != 2 {
+		t.Error("Invalid text should fail leaving the set unchanged")
+	}
+}
+
+func TestObservableSet_Cancel(t *testing.T) {
+	s := NewObservableSet(NewHashSet())
+	calls := 0
+	var sub *Subscription
+	sub = s.Subscribe(func(Event) {
+		calls++
+		sub.Cancel()
+	})
+	s.Add(1)
+	s.Add(2)
+	sub.Cancel()
+	if calls != 1 {
+		t.Errorf("Callback called %d times, want once before cancelling", calls)
+	}
+	if sub.Events() != nil {
+		t.Error("Callback subscription should have no channel")
+	}
+
+	ch := s.SubscribeChan(1, Block)
+	ch.Cancel()
+	if _, ok := <-ch.Events(); ok {
+		t.Error("Channel should be closed by Cancel")
+	}
+	s.Add(3)
+}
+
+func TestObservableSet_DropPolicy(t *testing.T) {
+	s := NewObservableSet(NewHashSet())
+	newest := s.SubscribeChan(2, DropNewest)
+	oldest := s.SubscribeChan(2, DropOldest)
+	unbuffered := s.SubscribeChan(0, DropOldest)
+	for i := 0; i < 5; i++ {
+		s.Add(i)
+	}
+
+	receive := func(sub *Subscription) []interface{} {
+		var got []interface{}
+		sub.Cancel()
+		for ev := range sub.Events() {
+			got = append(got, ev.Elements[0])
+		}
+		return got
+	}
+	if got := receive(newest); !slices.Equal(got, []interface{}{0, 1}) || newest.Dropped() != 3 {
+		t.Errorf("DropNewest kept %v dropping %d, want 0, 1 dropping 3", got, newest.Dropped())
+	}
+	if got := receive(oldest); !slices.Equal(got, []interface{}{3, 4}) || oldest.Dropped() != 3 {
+		t.Errorf("DropOldest kept %v dropping %d, want 3, 4 dropping 3", got, oldest.Dropped())
+	}
+	if got := receive(unbuffered); len(got) != 0 || unbuffered.Dropped() != 5 {
+		t.Errorf("Unbuffered DropOldest kept %v dropping %d, want none dropping 5", got, unbuffered.Dropped())
+	}
+}
+
+func TestObservableSet_Block(t *testing.T) {
+	s := NewObservableSet(NewConcurrentSet())
+	sub := s.SubscribeChan(0, Block)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			s.Add(i)
+		}
+	}()
+
+	next := 0
+	for ev := range sub.Events() {
+		if ev.Kind != Added || ev.Elements[0] != next {
+			t.Fatalf("Got %v %v, want Added %d", ev.Kind, ev.Elements, next)
+		}
+		next++
+		if next == 50 {
+			// Release the writer blocked on the next event
+			sub.Cancel()
+		}
+	}
+	<-done
+	if next != 50 || s.Len() != 100 || sub.Dropped() != 0 {
+		t.Errorf("Received %d events for %d elements", next, s.Len())
+	}
+}
+
+// Run under -race: concurrent writers and subscribers see one event per
+// change actually made.
+func TestObservableSet_Concurrent(t *testing.T) {
+	s := NewObservableSet(NewConcurrentSet())
+	r := &recorder{}
+	s.Subscribe(r.record)
+	sub := s.SubscribeChan(1024, Block)
+	received := make(chan int)
+	go func() {
+		n := 0
+		for ev := range sub.Events() {
+			n += len(ev.Elements)
+		}
+		received <- n
+	}()
+
+	wg := &sync.WaitGroup{}
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				s.AddAll(i, i+1)
+				s.Contains(i)
+			}
+		}()
+	}
+	wg.Wait()
+	sub.Cancel()
+
+	total := 0
+	for _, ev := range r.events {
+		total += len(ev.Elements)
+	}
+	if total != 201 || <-received != 201 || s.Len() != 201 {
+		t.Errorf("Got %d elements added, want 201", total)
+	}
+}
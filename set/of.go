@@ -0,0 +1,156 @@
+package set
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Of is the type-safe counterpart of Set for elements of type T.
+type Of[T comparable] interface {
+	// Adds the specified element to this set if it is not already present (optional operation).
+	Add(e T)
+
+	// Adds all of the elements to this set if they're not already present (optional operation).
+	AddAll(es ...T)
+
+	// Removes all of the elements from this set (optional operation).
+	Clear()
+
+	// Returns true if this set contains the specified element.
+	Contains(e T) bool
+
+	// Returns true if this set contains all of the elements of the specified collection.
+	ContainsAll(es ...T) bool
+
+	// Call f for each item in the set
+	Foreach(f func(T))
+
+	// Returns a sequence over the elements in this set.
+	All() iter.Seq[T]
+
+	// Returns true if this set contains no elements.
+	IsEmpty() bool
+
+	// Removes the specified element from this set if it is present (optional operation).
+	Remove(e T) bool
+
+	// Removes the specified elements from this set if it is present (optional operation).
+	// Return true if all element exist.
+	RemoveAll(es ...T) bool
+
+	// Return the number of elements in set s (cardinality of s).
+	Len() uint32
+
+	// Returns an slice containing all of the elements in this set.
+	ToSlice() []T
+
+	// Returns a deep clone of set
+	Clone() Of[T]
+
+	// Return a new set with elements common to the set and all others.
+	Intersection(others ...Of[T]) Of[T]
+
+	// Return a new set with elements from the set and all others.
+	Union(others ...Of[T]) Of[T]
+
+	// Return a new set with elements in the set that are not in the others.
+	Difference(others ...Of[T]) Of[T]
+
+	// Test whether every element in the set is in other. set <= other
+	IsSubset(other Of[T]) bool
+
+	// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+	IsProperSubset(other Of[T]) bool
+
+	// Test whether every element in other is in the set. set >= other
+	IsSuperset(other Of[T]) bool
+
+	// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+	IsProperSuperset(other Of[T]) bool
+}
+
+// Create a new typed hash set
+func NewHashSetOf[T comparable](initial ...T) Of[T] {
+	s := &HashSetOf[T]{make(map[T]nothing, len(initial))}
+
+	for _, v := range initial {
+		s.Add(v)
+	}
+
+	return s
+}
+
+// Create a new typed concurrent set
+func NewConcurrentSetOf[T comparable](initial ...T) Of[T] {
+	s := &ConcurrentSetOf[T]{}
+
+	for _, v := range initial {
+		s.Add(v)
+	}
+
+	return s
+}
+
+// Map f for each item of s into a new set of type U.
+// The result uses the same implementation as s.
+func Map[T, U comparable](s Of[T], f func(T) U) Of[U] {
+	var n Of[U]
+	if _, ok := s.(*ConcurrentSetOf[T]); ok {
+		n = NewConcurrentSetOf[U]()
+	} else {
+		n = NewHashSetOf[U]()
+	}
+
+	s.Foreach(func(e T) {
+		n.Add(f(e))
+	})
+
+	return n
+}
+
+// Convert a typed set into an untyped Set holding the same elements.
+// A *ConcurrentSetOf becomes a ConcurrentSet, anything else a HashSet.
+func ToUntyped[T comparable](s Of[T]) Set {
+	var n Set
+	if _, ok := s.(*ConcurrentSetOf[T]); ok {
+		n = NewConcurrentSet()
+	} else {
+		n = NewHashSet()
+	}
+
+	s.Foreach(func(e T) {
+		n.Add(e)
+	})
+
+	return n
+}
+
+// Convert an untyped Set into a typed one. It returns an error if any
+// element of s is not of type T. A *ConcurrentSet becomes a ConcurrentSetOf,
+// anything else a HashSetOf.
+func FromUntyped[T comparable](s Set) (Of[T], error) {
+	var n Of[T]
+	if _, ok := s.(*ConcurrentSet); ok {
+		n = NewConcurrentSetOf[T]()
+	} else {
+		n = NewHashSetOf[T]()
+	}
+
+	var err error
+	s.Foreach(func(e interface{}) {
+		if err != nil {
+			return
+		}
+		v, ok := e.(T)
+		if !ok {
+			err = fmt.Errorf("set: element %v of type %T is not a %T", e, e, v)
+			return
+		}
+		n.Add(v)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return n, nil
+}
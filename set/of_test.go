@@ -0,0 +1,59 @@
+package set
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestMap(t *testing.T) {
+	s := Map(NewHashSetOf(1, 2, 3), strconv.Itoa)
+	if s.Len() != 3 || !s.ContainsAll("1", "2", "3") {
+		t.Error("Set should be '1', '2', '3'")
+	}
+
+	c := Map(NewConcurrentSetOf(-1, 1), func(x int) int { return x * x })
+	if c.Len() != 1 || !c.Contains(1) {
+		t.Error("Set should be 1")
+	}
+	if _, ok := c.(*ConcurrentSetOf[int]); !ok {
+		t.Error("Map of a concurrent set should be concurrent")
+	}
+}
+
+func TestToUntyped(t *testing.T) {
+	s := ToUntyped(NewHashSetOf("a", "b"))
+	if _, ok := s.(*HashSet); !ok {
+		t.Error("Untyped hash set should be a *HashSet")
+	}
+	if s.Len() != 2 || !s.ContainsAll("a", "b") {
+		t.Error("Set should contain 'a' and 'b'")
+	}
+
+	c := ToUntyped(NewConcurrentSetOf(1))
+	if _, ok := c.(*ConcurrentSet); !ok {
+		t.Error("Untyped concurrent set should be a *ConcurrentSet")
+	}
+}
+
+func TestFromUntyped(t *testing.T) {
+	s, err := FromUntyped[int](NewHashSet(1, 2))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if s.Len() != 2 || !s.ContainsAll(1, 2) {
+		t.Error("Set should contain 1 and 2")
+	}
+
+	c, err := FromUntyped[int](NewConcurrentSet(1))
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+	if _, ok := c.(*ConcurrentSetOf[int]); !ok {
+		t.Error("Typed concurrent set should be a *ConcurrentSetOf")
+	}
+
+	_, err = FromUntyped[int](NewHashSet(1, "2"))
+	if err == nil {
+		t.Error("Converting a mixed set should fail")
+	}
+}
@@ -0,0 +1,638 @@
+package set
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"math/bits"
+	"slices"
+	"sort"
+
+	"github.com/billryan/collections"
+)
+
+type (
+	// RoaringBitmap is a compressed Set of uint32s, suited to large, sparse
+	// sets of IDs. Elements are grouped by their high 16 bits into
+	// containers holding the low 16 bits, either as a sorted array, a bitmap
+	// or a list of runs, whichever fits the chunk. Set algebra between
+	// roaring bitmaps works container by container. Adding anything but a
+	// uint32 panics.
+	RoaringBitmap struct {
+		keys       []uint16
+		containers []container
+	}
+)
+
+// Cookies opening the serialized form, telling whether it holds run
+// containers
+const (
+	serialCookieNoRun = 12346
+	serialCookie      = 12347
+	// Below this many containers, bitmaps with runs leave out the offsets
+	noOffsetThreshold = 4
+)
+
+var errRoaringTruncated = errors.New("set: truncated roaring bitmap")
+
+// Create a new roaring bitmap
+func NewRoaringBitmap(initial ...uint32) *RoaringBitmap {
+	s := &RoaringBitmap{}
+
+	for _, v := range initial {
+		s.Set(v)
+	}
+
+	return s
+}
+
+// Add x to the set.
+func (s *RoaringBitmap) Set(x uint32) {
+	i, found := s.index(uint16(x >> 16))
+	if found {
+		s.containers[i] = s.containers[i].add(uint16(x))
+		return
+	}
+	s.keys = slices.Insert(s.keys, i, uint16(x>>16))
+	s.containers = slices.Insert(s.containers, i, container(arrayContainer{uint16(x)}))
+}
+
+// Remove x from the set. Returns true if it was there.
+func (s *RoaringBitmap) Unset(x uint32) bool {
+	i, found := s.index(uint16(x >> 16))
+	if !found || !s.containers[i].contains(uint16(x)) {
+		return false
+	}
+	s.containers[i] = s.containers[i].remove(uint16(x))
+	if s.containers[i].cardinality() == 0 {
+		s.keys = slices.Delete(s.keys, i, i+1)
+		s.containers = slices.Delete(s.containers, i, i+1)
+	}
+	return true
+}
+
+// Test whether x is in the set.
+func (s *RoaringBitmap) Test(x uint32) bool {
+	i, found := s.index(uint16(x >> 16))
+	return found && s.containers[i].contains(uint16(x))
+}
+
+// Adds the specified element to this set if it is not already present (optional operation).
+// Panics unless e is a uint32.
+func (s *RoaringBitmap) Add(e interface{}) {
+	x, ok := e.(uint32)
+	if !ok {
+		panic(fmt.Sprintf("set: RoaringBitmap holds uint32s, got %T", e))
+	}
+	s.Set(x)
+}
+
+// Adds all of the elements to this set if they're not already present (optional operation).
+func (s *RoaringBitmap) AddAll(es ...interface{}) {
+	for _, e := range es {
+		s.Add(e)
+	}
+}
+
+// Removes all of the elements from this set (optional operation).
+func (s *RoaringBitmap) Clear() {
+	s.keys, s.containers = nil, nil
+}
+
+// Returns true if this set contains the specified element.
+func (s *RoaringBitmap) Contains(e interface{}) bool {
+	x, ok := e.(uint32)
+	return ok && s.Test(x)
+}
+
+// Returns true if this set contains all of the elements of the specified collection.
+func (s *RoaringBitmap) ContainsAll(es ...interface{}) bool {
+	for _, e := range es {
+		if !s.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Call f for each item in the set, in ascending order
+func (s *RoaringBitmap) Foreach(f func(interface{})) {
+	s.do(func(x uint32) bool {
+		f(x)
+		return true
+	})
+}
+
+// Call f for each item in the set in ascending order until it returns false
+func (s *RoaringBitmap) Do(f func(interface{}) bool) {
+	s.do(func(x uint32) bool {
+		return f(x)
+	})
+}
+
+// Returns a sequence over the elements in this set, in ascending order.
+func (s *RoaringBitmap) All() iter.Seq[interface{}] {
+	return s.Do
+}
+
+// Returns a sequence over the elements in this set as uint32s, in ascending order.
+func (s *RoaringBitmap) Uint32s() iter.Seq[uint32] {
+	return func(yield func(uint32) bool) {
+		s.do(yield)
+	}
+}
+
+// Returns an iterator over a snapshot of the elements in this set, in ascending order.
+func (s *RoaringBitmap) Iterator() collections.Iterator {
+	return newIterator(s.ToSlice())
+}
+
+// Call f for each item in the set, set result as new key. The results need
+// not be uint32s, so they are collected into a HashSet.
+func (s *RoaringBitmap) Map(f func(interface{}) interface{}) Set {
+	n := NewHashSet()
+	s.Foreach(func(e interface{}) {
+		n.Add(f(e))
+	})
+	return n
+}
+
+// Returns true if this set contains no elements.
+func (s *RoaringBitmap) IsEmpty() bool {
+	return len(s.keys) == 0
+}
+
+// Removes the specified element from this set if it is present (optional operation).
+func (s *RoaringBitmap) Remove(e interface{}) bool {
+	x, ok := e.(uint32)
+	return ok && s.Unset(x)
+}
+
+// Removes the specified elements from this set if it is present (optional operation).
+// Return true if all element exist.
+func (s *RoaringBitmap) RemoveAll(es ...interface{}) bool {
+	existAll := true
+	for _, e := range es {
+		if !s.Remove(e) {
+			existAll = false
+		}
+	}
+	return existAll
+}
+
+// Return the number of elements in set s (cardinality of s). A set holding
+// every uint32 overflows it; use Cardinality for that.
+func (s *RoaringBitmap) Len() uint32 {
+	return uint32(s.Cardinality())
+}
+
+// Return the number of elements in the set.
+func (s *RoaringBitmap) Cardinality() uint64 {
+	var n uint64
+	for _, c := range s.containers {
+		n += uint64(c.cardinality())
+	}
+	return n
+}
+
+// Returns an slice containing all of the elements in this set, in ascending order.
+func (s *RoaringBitmap) ToSlice() []interface{} {
+	slice := make([]interface{}, 0, s.Cardinality())
+	s.Foreach(func(e interface{}) {
+		slice = append(slice, e)
+	})
+	return slice
+}
+
+// Returns a deep clone of set
+func (s *RoaringBitmap) Clone() Set {
+	return s.clone()
+}
+
+// Return a new set with elements common to the set and all others.
+// Other roaring bitmaps are combined container by container.
+func (s *RoaringBitmap) Intersection(others ...Set) Set {
+	if bitmaps, ok := allRoaringBitmaps(others); ok {
+		n := s.clone()
+		for _, o := range bitmaps {
+			n = n.And(o)
+		}
+		return n
+	}
+	return intersection(NewRoaringBitmap(), s, others)
+}
+
+// Return a new set with elements from the set and all others.
+// Other roaring bitmaps are combined container by container.
+func (s *RoaringBitmap) Union(others ...Set) Set {
+	if bitmaps, ok := allRoaringBitmaps(others); ok {
+		n := s.clone()
+		for _, o := range bitmaps {
+			n = n.Or(o)
+		}
+		return n
+	}
+	return union(NewRoaringBitmap(), s, others)
+}
+
+// Return a new set with elements in the set that are not in the others.
+// Other roaring bitmaps are combined container by container.
+func (s *RoaringBitmap) Difference(others ...Set) Set {
+	if bitmaps, ok := allRoaringBitmaps(others); ok {
+		n := s.clone()
+		for _, o := range bitmaps {
+			n = n.AndNot(o)
+		}
+		return n
+	}
+	return difference(NewRoaringBitmap(), s, others)
+}
+
+// Return a new set with elements in either the set or other but not both.
+func (s *RoaringBitmap) SymmetricDifference(other Set) Set {
+	if o, ok := other.(*RoaringBitmap); ok {
+		return s.Xor(o)
+	}
+	return symmetricDifference(NewRoaringBitmap(), s, other)
+}
+
+// Test whether the set and other contain the same elements.
+func (s *RoaringBitmap) Equal(other Set) bool {
+	if o, ok := other.(*RoaringBitmap); ok {
+		return s.Xor(o).IsEmpty()
+	}
+	return equal(s, other)
+}
+
+// Test whether the set has no elements in common with other.
+func (s *RoaringBitmap) IsDisjoint(other Set) bool {
+	if o, ok := other.(*RoaringBitmap); ok {
+		return s.And(o).IsEmpty()
+	}
+	return isDisjoint(s, other)
+}
+
+// Test whether every element in the set is in other. set <= other
+func (s *RoaringBitmap) IsSubset(other Set) bool {
+	if o, ok := other.(*RoaringBitmap); ok {
+		return s.AndNot(o).IsEmpty()
+	}
+	return isSubset(s, other)
+}
+
+// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+func (s *RoaringBitmap) IsProperSubset(other Set) bool {
+	return s.Len() < other.Len() && s.IsSubset(other)
+}
+
+// Test whether every element in other is in the set. set >= other
+func (s *RoaringBitmap) IsSuperset(other Set) bool {
+	return other.IsSubset(s)
+}
+
+// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+func (s *RoaringBitmap) IsProperSuperset(other Set) bool {
+	return s.Len() > other.Len() && s.IsSuperset(other)
+}
+
+// Replaces the elements of the set with those of a JSON array of uint32s.
+func (s *RoaringBitmap) UnmarshalText(text []byte) error {
+	var v []uint32
+	err := json.Unmarshal(text, &v)
+	if err == nil {
+		s.Clear()
+		for _, x := range v {
+			s.Set(x)
+		}
+	}
+	return err
+}
+
+// Encode the set as a JSON array, in ascending order.
+func (s *RoaringBitmap) MarshalText() ([]byte, error) {
+	return json.Marshal(slices.Collect(s.Uint32s()))
+}
+
+// Encode the set as a JSON array, as MarshalText does.
+func (s *RoaringBitmap) MarshalJSON() ([]byte, error) {
+	return s.MarshalText()
+}
+
+// Replaces the elements of the set with those of a JSON array of uint32s.
+func (s *RoaringBitmap) UnmarshalJSON(data []byte) error {
+	return s.UnmarshalText(data)
+}
+
+// Returns the number of elements less than x. This is the position x has,
+// or would have if it were added.
+func (s *RoaringBitmap) Rank(x uint32) int {
+	i, found := s.index(uint16(x >> 16))
+	n := 0
+	for _, c := range s.containers[:i] {
+		n += c.cardinality()
+	}
+	if found {
+		n += s.containers[i].rank(uint16(x))
+	}
+	return n
+}
+
+// Returns the element at position i in ascending order, and false if i is
+// out of range.
+func (s *RoaringBitmap) Select(i int) (uint32, bool) {
+	if i < 0 {
+		return 0, false
+	}
+	for j, c := range s.containers {
+		if n := c.cardinality(); i >= n {
+			i -= n
+			continue
+		}
+		return uint32(s.keys[j])<<16 | uint32(c.at(i)), true
+	}
+	return 0, false
+}
+
+// Returns a new bitmap with the elements in both s and other.
+func (s *RoaringBitmap) And(other *RoaringBitmap) *RoaringBitmap {
+	return s.combine(other, and, false, false)
+}
+
+// Returns a new bitmap with the elements in either s or other.
+func (s *RoaringBitmap) Or(other *RoaringBitmap) *RoaringBitmap {
+	return s.combine(other, or, true, true)
+}
+
+// Returns a new bitmap with the elements in s but not in other.
+func (s *RoaringBitmap) AndNot(other *RoaringBitmap) *RoaringBitmap {
+	return s.combine(other, andNot, true, false)
+}
+
+// Returns a new bitmap with the elements in exactly one of s and other.
+func (s *RoaringBitmap) Xor(other *RoaringBitmap) *RoaringBitmap {
+	return s.combine(other, xor, true, true)
+}
+
+// Store every container as whichever of an array, a bitmap or a list of
+// runs takes the least room. Sets of consecutive IDs shrink the most.
+// Containers changed afterwards by set algebra go back to arrays or bitmaps.
+func (s *RoaringBitmap) RunOptimize() {
+	for i, c := range s.containers {
+		s.containers[i] = optimize(c)
+	}
+}
+
+// Encode the set in the portable Roaring format shared by the Roaring
+// libraries of other languages. All numbers are little-endian.
+func (s *RoaringBitmap) MarshalBinary() ([]byte, error) {
+	le := binary.LittleEndian
+	size := len(s.keys)
+
+	var runs []byte
+	for i, c := range s.containers {
+		if _, ok := c.(runContainer); ok {
+			if runs == nil {
+				runs = make([]byte, (size+7)/8)
+			}
+			runs[i/8] |= 1 << (i % 8)
+		}
+	}
+
+	var b []byte
+	if runs != nil {
+		b = le.AppendUint32(b, serialCookie|uint32(size-1)<<16)
+		b = append(b, runs...)
+	} else {
+		b = le.AppendUint32(b, serialCookieNoRun)
+		b = le.AppendUint32(b, uint32(size))
+	}
+	for i, c := range s.containers {
+		b = le.AppendUint16(b, s.keys[i])
+		b = le.AppendUint16(b, uint16(c.cardinality()-1))
+	}
+	if runs == nil || size >= noOffsetThreshold {
+		offset := len(b) + 4*size
+		for _, c := range s.containers {
+			b = le.AppendUint32(b, uint32(offset))
+			offset += serializedSize(c)
+		}
+	}
+
+	for _, c := range s.containers {
+		switch r, isRuns := c.(runContainer); {
+		case isRuns:
+			b = le.AppendUint16(b, uint16(len(r)))
+			for _, run := range r {
+				b = le.AppendUint16(b, run.start)
+				b = le.AppendUint16(b, run.last-run.start)
+			}
+		case c.cardinality() > arrayMax:
+			for _, w := range toBitmap(c).words {
+				b = le.AppendUint64(b, w)
+			}
+		default:
+			c.do(func(x uint16) bool {
+				b = le.AppendUint16(b, x)
+				return true
+			})
+		}
+	}
+	return b, nil
+}
+
+// Replace the elements of the set with those decoded from the portable
+// Roaring format written by MarshalBinary.
+func (s *RoaringBitmap) UnmarshalBinary(data []byte) error {
+	r := &byteReader{data: data}
+
+	var size int
+	var runs []byte
+	switch cookie := r.uint32(); {
+	case r.err != nil:
+		return r.err
+	case cookie&0xFFFF == serialCookie:
+		size = int(cookie>>16) + 1
+		runs = r.next((size + 7) / 8)
+	case cookie == serialCookieNoRun:
+		size = int(r.uint32())
+	default:
+		return fmt.Errorf("set: unknown roaring bitmap cookie %d", cookie)
+	}
+	if size > 1<<16 {
+		return fmt.Errorf("set: roaring bitmap with %d containers", size)
+	}
+
+	keys := make([]uint16, size)
+	cardinalities := make([]int, size)
+	for i := range keys {
+		keys[i] = r.uint16()
+		cardinalities[i] = int(r.uint16()) + 1
+		if i > 0 && keys[i] <= keys[i-1] && r.err == nil {
+			return errors.New("set: roaring bitmap keys out of order")
+		}
+	}
+	if runs == nil || size >= noOffsetThreshold {
+		r.next(4 * size)
+	}
+
+	containers := make([]container, size)
+	for i := range containers {
+		var c container
+		switch {
+		case runs != nil && runs[i/8]&(1<<(i%8)) != 0:
+			rc := make(runContainer, r.uint16())
+			for j := range rc {
+				start, length := r.uint16(), r.uint16()
+				if int(start)+int(length) > 0xFFFF || (j > 0 && start <= rc[j-1].last) {
+					return errors.New("set: invalid roaring bitmap run")
+				}
+				rc[j] = run{start, start + length}
+			}
+			c = rc
+		case cardinalities[i] > arrayMax:
+			b := &bitmapContainer{}
+			for j := range b.words {
+				b.words[j] = r.uint64()
+			}
+			b.n = cardinalities[i]
+			c = b
+		default:
+			a := make(arrayContainer, cardinalities[i])
+			for j := range a {
+				a[j] = r.uint16()
+				if j > 0 && a[j] <= a[j-1] && r.err == nil {
+					return errors.New("set: roaring bitmap values out of order")
+				}
+			}
+			c = a
+		}
+		if r.err != nil {
+			return r.err
+		}
+		if n := countValues(c); n != cardinalities[i] {
+			return fmt.Errorf("set: roaring bitmap container holds %d values, header says %d", n, cardinalities[i])
+		}
+		containers[i] = c
+	}
+
+	s.keys, s.containers = keys, containers
+	return nil
+}
+
+// Call f for each element in ascending order until it returns false
+func (s *RoaringBitmap) do(f func(uint32) bool) {
+	for i, c := range s.containers {
+		high := uint32(s.keys[i]) << 16
+		if !c.do(func(low uint16) bool { return f(high | uint32(low)) }) {
+			return
+		}
+	}
+}
+
+// Get the position of the container for key, or where it would go, and
+// whether it exists
+func (s *RoaringBitmap) index(key uint16) (int, bool) {
+	i := sort.Search(len(s.keys), func(i int) bool { return s.keys[i] >= key })
+	return i, i < len(s.keys) && s.keys[i] == key
+}
+
+// Combine s and other container by container using op. Containers found in
+// only one of the sets are copied over if keepOwn, respectively
+// keepOthers, is set.
+func (s *RoaringBitmap) combine(other *RoaringBitmap, op func(a, b container) container, keepOwn, keepOthers bool) *RoaringBitmap {
+	n := &RoaringBitmap{}
+	i, j := 0, 0
+	for i < len(s.keys) || j < len(other.keys) {
+		switch {
+		case j == len(other.keys) || (i < len(s.keys) && s.keys[i] < other.keys[j]):
+			if keepOwn {
+				n.append(s.keys[i], s.containers[i].clone())
+			}
+			i++
+		case i == len(s.keys) || other.keys[j] < s.keys[i]:
+			if keepOthers {
+				n.append(other.keys[j], other.containers[j].clone())
+			}
+			j++
+		default:
+			n.append(s.keys[i], op(s.containers[i], other.containers[j]))
+			i++
+			j++
+		}
+	}
+	return n
+}
+
+// Append a container, unless it is empty
+func (s *RoaringBitmap) append(key uint16, c container) {
+	if c.cardinality() > 0 {
+		s.keys = append(s.keys, key)
+		s.containers = append(s.containers, c)
+	}
+}
+
+func (s *RoaringBitmap) clone() *RoaringBitmap {
+	n := &RoaringBitmap{slices.Clone(s.keys), make([]container, len(s.containers))}
+	for i, c := range s.containers {
+		n.containers[i] = c.clone()
+	}
+	return n
+}
+
+// Get the sets as roaring bitmaps if they all are
+func allRoaringBitmaps(sets []Set) ([]*RoaringBitmap, bool) {
+	bitmaps := make([]*RoaringBitmap, len(sets))
+	for i, set := range sets {
+		b, ok := set.(*RoaringBitmap)
+		if !ok {
+			return nil, false
+		}
+		bitmaps[i] = b
+	}
+	return bitmaps, true
+}
+
+// Count the values of a container, recounting the bits of a bitmap rather
+// than trusting its count
+func countValues(c container) int {
+	if b, ok := c.(*bitmapContainer); ok {
+		n := 0
+		for _, w := range b.words {
+			n += bits.OnesCount64(w)
+		}
+		return n
+	}
+	return c.cardinality()
+}
+
+// Reads little-endian numbers from a byte slice, remembering whether it ran
+// short
+type byteReader struct {
+	data []byte
+	err  error
+}
+
+// Get the next n bytes, or zeros if there are not enough left
+func (r *byteReader) next(n int) []byte {
+	if r.err != nil || len(r.data) < n {
+		r.err = errRoaringTruncated
+		return make([]byte, n)
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *byteReader) uint16() uint16 {
+	return binary.LittleEndian.Uint16(r.next(2))
+}
+
+func (r *byteReader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.next(4))
+}
+
+func (r *byteReader) uint64() uint64 {
+	return binary.LittleEndian.Uint64(r.next(8))
+}
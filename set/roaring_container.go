@@ -0,0 +1,442 @@
+package set
+
+import (
+	"math/bits"
+	"slices"
+	"sort"
+)
+
+type (
+	// A container holds the low 16 bits of the elements of a roaring bitmap
+	// that share the same high 16 bits.
+	container interface {
+		cardinality() int
+		contains(x uint16) bool
+		// Add or remove x, returning the container to keep in place of this
+		// one, which may be of another kind.
+		add(x uint16) container
+		remove(x uint16) container
+		// Get the number of values less than x
+		rank(x uint16) int
+		// Get the value at position i, which must be in range
+		at(i int) uint16
+		// Call f for each value in ascending order until it returns false.
+		// Returns false if f did.
+		do(f func(uint16) bool) bool
+		clone() container
+	}
+
+	// Sorted values, used for up to arrayMax of them
+	arrayContainer []uint16
+
+	// One bit per possible value, used for more than arrayMax values
+	bitmapContainer struct {
+		words [bitmapWords]uint64
+		n     int
+	}
+
+	// Sorted, non-overlapping runs of consecutive values
+	runContainer []run
+
+	run struct {
+		start, last uint16
+	}
+)
+
+const (
+	// Largest number of values an array container holds. Past it a bitmap
+	// takes less room.
+	arrayMax    = 4096
+	bitmapWords = 1 << 16 / 64
+)
+
+func (a arrayContainer) cardinality() int {
+	return len(a)
+}
+
+// Get the position of the first value not less than x
+func (a arrayContainer) search(x uint16) int {
+	return sort.Search(len(a), func(i int) bool { return a[i] >= x })
+}
+
+func (a arrayContainer) contains(x uint16) bool {
+	i := a.search(x)
+	return i < len(a) && a[i] == x
+}
+
+func (a arrayContainer) add(x uint16) container {
+	i := a.search(x)
+	if i < len(a) && a[i] == x {
+		return a
+	}
+	if len(a) == arrayMax {
+		return toBitmap(a).add(x)
+	}
+	return slices.Insert(a, i, x)
+}
+
+func (a arrayContainer) remove(x uint16) container {
+	i := a.search(x)
+	if i < len(a) && a[i] == x {
+		return slices.Delete(a, i, i+1)
+	}
+	return a
+}
+
+func (a arrayContainer) rank(x uint16) int {
+	return a.search(x)
+}
+
+func (a arrayContainer) at(i int) uint16 {
+	return a[i]
+}
+
+func (a arrayContainer) do(f func(uint16) bool) bool {
+	for _, x := range a {
+		if !f(x) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a arrayContainer) clone() container {
+	return slices.Clone(a)
+}
+
+func (b *bitmapContainer) cardinality() int {
+	return b.n
+}
+
+func (b *bitmapContainer) contains(x uint16) bool {
+	return b.words[x/64]&(1<<(x%64)) != 0
+}
+
+func (b *bitmapContainer) add(x uint16) container {
+	if !b.contains(x) {
+		b.words[x/64] |= 1 << (x % 64)
+		b.n++
+	}
+	return b
+}
+
+func (b *bitmapContainer) remove(x uint16) container {
+	if !b.contains(x) {
+		return b
+	}
+	b.words[x/64] &^= 1 << (x % 64)
+	b.n--
+	if b.n <= arrayMax {
+		return toArray(b)
+	}
+	return b
+}
+
+func (b *bitmapContainer) rank(x uint16) int {
+	n := 0
+	for _, w := range b.words[:x/64] {
+		n += bits.OnesCount64(w)
+	}
+	return n + bits.OnesCount64(b.words[x/64]&(1<<(x%64)-1))
+}
+
+func (b *bitmapContainer) at(i int) uint16 {
+	for w := range b.words {
+		word := b.words[w]
+		if c := bits.OnesCount64(word); i >= c {
+			i -= c
+			continue
+		}
+		for ; i > 0; i-- {
+			word &= word - 1
+		}
+		return uint16(w*64 + bits.TrailingZeros64(word))
+	}
+	panic("set: container index out of range")
+}
+
+func (b *bitmapContainer) do(f func(uint16) bool) bool {
+	for w := range b.words {
+		for word := b.words[w]; word != 0; word &= word - 1 {
+			if !f(uint16(w*64 + bits.TrailingZeros64(word))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (b *bitmapContainer) clone() container {
+	c := *b
+	return &c
+}
+
+func (r runContainer) cardinality() int {
+	n := 0
+	for _, run := range r {
+		n += int(run.last-run.start) + 1
+	}
+	return n
+}
+
+// Get the position of the first run starting after x
+func (r runContainer) search(x uint16) int {
+	return sort.Search(len(r), func(i int) bool { return r[i].start > x })
+}
+
+func (r runContainer) contains(x uint16) bool {
+	i := r.search(x)
+	return i > 0 && r[i-1].last >= x
+}
+
+func (r runContainer) add(x uint16) container {
+	i := r.search(x)
+	if i > 0 && r[i-1].last >= x {
+		return r
+	}
+	joinsPrevious := i > 0 && int(r[i-1].last)+1 == int(x)
+	joinsNext := i < len(r) && int(x)+1 == int(r[i].start)
+	switch {
+	case joinsPrevious && joinsNext:
+		r[i-1].last = r[i].last
+		return slices.Delete(r, i, i+1)
+	case joinsPrevious:
+		r[i-1].last = x
+	case joinsNext:
+		r[i].start = x
+	default:
+		return slices.Insert(r, i, run{x, x})
+	}
+	return r
+}
+
+func (r runContainer) remove(x uint16) container {
+	i := r.search(x) - 1
+	if i < 0 || r[i].last < x {
+		return r
+	}
+	switch {
+	case r[i].start == r[i].last:
+		return slices.Delete(r, i, i+1)
+	case r[i].start == x:
+		r[i].start++
+	case r[i].last == x:
+		r[i].last--
+	default:
+		last := r[i].last
+		r[i].last = x - 1
+		return slices.Insert(r, i+1, run{x + 1, last})
+	}
+	return r
+}
+
+func (r runContainer) rank(x uint16) int {
+	n := 0
+	for _, run := range r {
+		if run.start >= x {
+			break
+		}
+		n += int(min(run.last, x-1)-run.start) + 1
+	}
+	return n
+}
+
+func (r runContainer) at(i int) uint16 {
+	for _, run := range r {
+		if n := int(run.last-run.start) + 1; i >= n {
+			i -= n
+			continue
+		}
+		return run.start + uint16(i)
+	}
+	panic("set: container index out of range")
+}
+
+func (r runContainer) do(f func(uint16) bool) bool {
+	for _, run := range r {
+		for x := int(run.start); x <= int(run.last); x++ {
+			if !f(uint16(x)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r runContainer) clone() container {
+	return slices.Clone(r)
+}
+
+// Get the values of c as a bitmap container. Bitmaps are returned as they
+// are, not copied.
+func toBitmap(c container) *bitmapContainer {
+	if b, ok := c.(*bitmapContainer); ok {
+		return b
+	}
+	b := &bitmapContainer{}
+	c.do(func(x uint16) bool {
+		b.words[x/64] |= 1 << (x % 64)
+		return true
+	})
+	b.n = c.cardinality()
+	return b
+}
+
+// Get the values of c as an array container
+func toArray(c container) arrayContainer {
+	if a, ok := c.(arrayContainer); ok {
+		return a
+	}
+	a := make(arrayContainer, 0, c.cardinality())
+	c.do(func(x uint16) bool {
+		a = append(a, x)
+		return true
+	})
+	return a
+}
+
+// Get the values of c as a run container
+func toRuns(c container) runContainer {
+	if r, ok := c.(runContainer); ok {
+		return r
+	}
+	var r runContainer
+	c.do(func(x uint16) bool {
+		if n := len(r); n > 0 && int(r[n-1].last)+1 == int(x) {
+			r[n-1].last = x
+		} else {
+			r = append(r, run{x, x})
+		}
+		return true
+	})
+	return r
+}
+
+// Get c in whichever kind of container takes the least room
+func optimize(c container) container {
+	runs := toRuns(c)
+	n := c.cardinality()
+	switch {
+	case serializedSize(runs) < min(2*n, bitmapWords*8):
+		return runs
+	case n <= arrayMax:
+		return toArray(c)
+	default:
+		return toBitmap(c)
+	}
+}
+
+// Get an array or bitmap container, whichever suits the number of values
+func normalize(c container) container {
+	switch c := c.(type) {
+	case arrayContainer:
+		if len(c) > arrayMax {
+			return toBitmap(c)
+		}
+	case *bitmapContainer:
+		if c.n <= arrayMax {
+			return toArray(c)
+		}
+	}
+	return c
+}
+
+// Get the values in both a and b
+func and(a, b container) container {
+	if _, ok := b.(arrayContainer); ok {
+		a, b = b, a
+	}
+	if a, ok := a.(arrayContainer); ok {
+		return a.filter(b, true)
+	}
+	return combineWords(a, b, func(x, y uint64) uint64 { return x & y })
+}
+
+// Get the values in a or b
+func or(a, b container) container {
+	x, xok := a.(arrayContainer)
+	y, yok := b.(arrayContainer)
+	if xok && yok {
+		return normalize(merge(x, y, func(inX, inY bool) bool { return true }))
+	}
+	return combineWords(a, b, func(x, y uint64) uint64 { return x | y })
+}
+
+// Get the values in a but not in b
+func andNot(a, b container) container {
+	if a, ok := a.(arrayContainer); ok {
+		return a.filter(b, false)
+	}
+	return combineWords(a, b, func(x, y uint64) uint64 { return x &^ y })
+}
+
+// Get the values in exactly one of a and b
+func xor(a, b container) container {
+	x, xok := a.(arrayContainer)
+	y, yok := b.(arrayContainer)
+	if xok && yok {
+		return normalize(merge(x, y, func(inX, inY bool) bool { return inX != inY }))
+	}
+	return combineWords(a, b, func(x, y uint64) uint64 { return x ^ y })
+}
+
+// Get the values of a that are, or are not, in c
+func (a arrayContainer) filter(c container, in bool) arrayContainer {
+	n := make(arrayContainer, 0, len(a))
+	for _, x := range a {
+		if c.contains(x) == in {
+			n = append(n, x)
+		}
+	}
+	return n
+}
+
+// Merge two sorted arrays, keeping the values for which keep returns true
+// given whether they are in x and in y
+func merge(x, y arrayContainer, keep func(inX, inY bool) bool) arrayContainer {
+	n := make(arrayContainer, 0, len(x)+len(y))
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case j == len(y) || (i < len(x) && x[i] < y[j]):
+			if keep(true, false) {
+				n = append(n, x[i])
+			}
+			i++
+		case i == len(x) || y[j] < x[i]:
+			if keep(false, true) {
+				n = append(n, y[j])
+			}
+			j++
+		default:
+			if keep(true, true) {
+				n = append(n, x[i])
+			}
+			i++
+			j++
+		}
+	}
+	return n
+}
+
+// Combine the bitmaps of a and b word by word
+func combineWords(a, b container, op func(x, y uint64) uint64) container {
+	x, y := toBitmap(a), toBitmap(b)
+	n := &bitmapContainer{}
+	for i := range n.words {
+		n.words[i] = op(x.words[i], y.words[i])
+		n.n += bits.OnesCount64(n.words[i])
+	}
+	return normalize(n)
+}
+
+// Get the number of bytes c takes in the serialized form
+func serializedSize(c container) int {
+	if r, ok := c.(runContainer); ok {
+		return 2 + 4*len(r)
+	}
+	if n := c.cardinality(); n <= arrayMax {
+		return 2 * n
+	}
+	return bitmapWords * 8
+}
@@ -0,0 +1,261 @@
+package set
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// Build a bitmap mixing sparse, dense and consecutive chunks, along with
+// the same elements in a map.
+func randomRoaring(gen *rand.Rand) (*RoaringBitmap, map[uint32]bool) {
+	s, m := NewRoaringBitmap(), map[uint32]bool{}
+	add := func(x uint32) {
+		s.Set(x)
+		m[x] = true
+	}
+	for i := 0; i < 200; i++ {
+		add(gen.Uint32())
+	}
+	for i := 0; i < 6000; i++ {
+		add(3<<16 | uint32(gen.Intn(1<<16)))
+	}
+	start := uint32(gen.Intn(1 << 16))
+	for i := uint32(0); i < 5000; i++ {
+		add(5<<16 + start + i)
+	}
+	for i := 0; i < 100; i++ {
+		add(uint32(gen.Intn(1 << 16)))
+	}
+	return s, m
+}
+
+func sortedKeys(m map[uint32]bool) []uint32 {
+	keys := make([]uint32, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func roaringElements(s *RoaringBitmap) []uint32 {
+	return slices.Collect(s.Uint32s())
+}
+
+func TestRoaringBitmap_Basics(t *testing.T) {
+	s := NewRoaringBitmap(1<<20, 7, 1<<31, 7)
+	if s.Len() != 3 || !s.ContainsAll(uint32(7), uint32(1<<20), uint32(1<<31)) {
+		t.Errorf("Set should hold 7, 2^20 and 2^31, got %v", s.ToSlice())
+	}
+	if s.Contains(7) || s.Contains(uint32(8)) {
+		t.Errorf("Set should only contain uint32s it was given")
+	}
+	if !s.Remove(uint32(1<<20)) || s.Remove(uint32(1<<20)) || s.Len() != 2 {
+		t.Errorf("Remove should report whether the element was present")
+	}
+	if len(s.keys) != 2 {
+		t.Errorf("Emptied containers should be dropped, got keys %v", s.keys)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Adding an int should panic")
+		}
+	}()
+	s.Add(1)
+}
+
+func TestRoaringBitmap_Containers(t *testing.T) {
+	s := NewRoaringBitmap()
+	for i := uint32(0); i < arrayMax; i++ {
+		s.Set(2 * i)
+	}
+	if _, ok := s.containers[0].(arrayContainer); !ok {
+		t.Fatalf("%d values should be kept in an array, got %T", arrayMax, s.containers[0])
+	}
+	s.Set(1)
+	if _, ok := s.containers[0].(*bitmapContainer); !ok {
+		t.Fatalf("%d values should be kept in a bitmap, got %T", arrayMax+1, s.containers[0])
+	}
+	s.Unset(0)
+	if _, ok := s.containers[0].(arrayContainer); !ok {
+		t.Fatalf("Back to %d values should be kept in an array, got %T", arrayMax, s.containers[0])
+	}
+	if s.Len() != arrayMax || !s.Test(1) || s.Test(0) || !s.Test(2*(arrayMax-1)) {
+		t.Errorf("Conversions should keep the elements")
+	}
+
+	r := NewRoaringBitmap()
+	for i := uint32(100); i < 20000; i++ {
+		r.Set(i)
+	}
+	r.RunOptimize()
+	if c, ok := r.containers[0].(runContainer); !ok || len(c) != 1 {
+		t.Fatalf("Consecutive values should be kept as one run, got %T", r.containers[0])
+	}
+	r.Unset(150)
+	r.Set(99)
+	r.Set(98)
+	r.Unset(19999)
+	if c := r.containers[0].(runContainer); !reflect.DeepEqual(c, runContainer{{98, 149}, {151, 19998}}) {
+		t.Errorf("Runs should be split and extended, got %v", c)
+	}
+	if r.Len() != 19998-98 || r.Test(150) || !r.Test(151) {
+		t.Errorf("Run container should hold 98..19998 but 150")
+	}
+}
+
+func TestRoaringBitmap_RankSelect(t *testing.T) {
+	gen := rand.New(rand.NewSource(1))
+	s, m := randomRoaring(gen)
+	want := sortedKeys(m)
+	for _, optimize := range []bool{false, true} {
+		if optimize {
+			s.RunOptimize()
+		}
+		for i := 0; i < len(want); i += 97 {
+			if x, ok := s.Select(i); !ok || x != want[i] {
+				t.Fatalf("Select(%d) should be %d, got %d", i, want[i], x)
+			}
+			if r := s.Rank(want[i]); r != i {
+				t.Fatalf("Rank(%d) should be %d, got %d", want[i], i, r)
+			}
+		}
+		if _, ok := s.Select(len(want)); ok {
+			t.Errorf("Select past the end should fail")
+		}
+		if r := s.Rank(want[len(want)-1] + 1); r != len(want) && want[len(want)-1] != 1<<32-1 {
+			t.Errorf("Rank past the last element should be %d, got %d", len(want), r)
+		}
+	}
+}
+
+func TestRoaringBitmap_Algebra(t *testing.T) {
+	gen := rand.New(rand.NewSource(2))
+	for round := 0; round < 4; round++ {
+		a, ma := randomRoaring(gen)
+		b, mb := randomRoaring(gen)
+		if round%2 == 1 {
+			a.RunOptimize()
+		}
+		if round >= 2 {
+			b.RunOptimize()
+		}
+		for _, c := range []struct {
+			name string
+			got  *RoaringBitmap
+			keep func(inA, inB bool) bool
+		}{
+			{"And", a.And(b), func(inA, inB bool) bool { return inA && inB }},
+			{"Or", a.Or(b), func(inA, inB bool) bool { return inA || inB }},
+			{"AndNot", a.AndNot(b), func(inA, inB bool) bool { return inA && !inB }},
+			{"Xor", a.Xor(b), func(inA, inB bool) bool { return inA != inB }},
+		} {
+			want := map[uint32]bool{}
+			for x := range ma {
+				if c.keep(true, mb[x]) {
+					want[x] = true
+				}
+			}
+			for x := range mb {
+				if c.keep(ma[x], true) {
+					want[x] = true
+				}
+			}
+			if got := roaringElements(c.got); !slices.Equal(got, sortedKeys(want)) {
+				t.Errorf("Round %d: %s gave %d elements, want %d", round, c.name, len(got), len(want))
+			}
+		}
+		if !slices.Equal(roaringElements(a), sortedKeys(ma)) {
+			t.Errorf("Round %d: set algebra should leave the receiver alone", round)
+		}
+	}
+}
+
+func TestRoaringBitmap_MixedAlgebra(t *testing.T) {
+	s := NewRoaringBitmap(1, 2, 3, 1<<20)
+	h := NewHashSet(uint32(2), uint32(1<<20), uint32(9))
+	if u := s.Union(h); u.Len() != 5 || !sameType(u, s) {
+		t.Errorf("Union with a HashSet should give a roaring bitmap of 5, got %T %v", u, u.ToSlice())
+	}
+	if i := s.Intersection(h); !Equal(i, NewHashSet(uint32(2), uint32(1<<20))) {
+		t.Errorf("Intersection should be 2, 2^20, got %v", i.ToSlice())
+	}
+	if d := h.Difference(s); !Equal(d, NewRoaringBitmap(9)) {
+		t.Errorf("Difference should be 9, got %v", d.ToSlice())
+	}
+	if !NewRoaringBitmap(2, 9).IsSubset(h) || s.IsSubset(h) {
+		t.Errorf("Subset should compare elements")
+	}
+}
+
+func TestRoaringBitmap_MarshalBinary(t *testing.T) {
+	data, err := NewRoaringBitmap(1, 2, 70000).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x3a, 0x30, 0, 0, 2, 0, 0, 0, // cookie, 2 containers
+		0, 0, 1, 0, 1, 0, 0, 0, // keys and cardinalities - 1
+		24, 0, 0, 0, 28, 0, 0, 0, // offsets
+		1, 0, 2, 0, 0x70, 0x11, // values
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Encoding should be\n%v, got\n%v", want, data)
+	}
+
+	gen := rand.New(rand.NewSource(3))
+	for _, optimize := range []bool{false, true} {
+		s, m := randomRoaring(gen)
+		if optimize {
+			s.RunOptimize()
+		}
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := NewRoaringBitmap(42)
+		if err := d.UnmarshalBinary(data); err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(roaringElements(d), sortedKeys(m)) || !reflect.DeepEqual(d, s) {
+			t.Errorf("Decoding should give back the set")
+		}
+		if err := d.UnmarshalBinary(data[:len(data)-1]); err == nil {
+			t.Errorf("Decoding truncated data should fail")
+		}
+	}
+
+	r := NewRoaringBitmap()
+	for x := uint32(10); x < 20; x++ {
+		r.Set(x)
+	}
+	r.RunOptimize()
+	data, _ = r.MarshalBinary()
+	want = []byte{
+		0x3b, 0x30, 0, 0, 1, // cookie with 1 container, run flags
+		0, 0, 9, 0, // key and cardinality - 1
+		1, 0, 10, 0, 9, 0, // 1 run from 10 of length 9 + 1
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("Encoding of runs should be\n%v, got\n%v", want, data)
+	}
+
+	for _, bad := range [][]byte{nil, {1, 2, 3, 4}, {0x3a, 0x30, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0}} {
+		if err := NewRoaringBitmap().UnmarshalBinary(bad); err == nil {
+			t.Errorf("Decoding %v should fail", bad)
+		}
+	}
+}
+
+func TestRoaringBitmap_UnmarshalText(t *testing.T) {
+	s := NewRoaringBitmap(5)
+	if err := s.UnmarshalText([]byte("[4000000000, 3]")); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(roaringElements(s), []uint32{3, 4000000000}) {
+		t.Errorf("Set should be 3, 4000000000, got %v", s.ToSlice())
+	}
+}
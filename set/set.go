@@ -1,9 +1,19 @@
 package set
 
 import (
+	"encoding"
+	"encoding/json"
+	"iter"
 	"sync"
+
+	"github.com/billryan/collections"
 )
 
+// Set is the interface of all sets. The sets of this package also implement
+// Do, All, Iterator, SymmetricDifference, Equal, IsDisjoint and MarshalText.
+// The functions Do, All, IteratorOf, SymmetricDifference, Equal and
+// IsDisjoint give the same for any Set, falling back on the methods of the
+// interface for sets that lack them.
 type Set interface {
 	// Adds the specified element to this set if it is not already present (optional operation).
 	Add(e interface{})
@@ -46,12 +56,18 @@ type Set interface {
 	Clone() Set
 
 	// Return a new set with elements common to the set and all others.
+	// The others may be of any implementation; the result has the
+	// implementation of the set.
 	Intersection(others ...Set) Set
 
 	// Return a new set with elements from the set and all others.
+	// The others may be of any implementation; the result has the
+	// implementation of the set.
 	Union(others ...Set) Set
 
 	// Return a new set with elements in the set that are not in the others.
+	// The others may be of any implementation; the result has the
+	// implementation of the set.
 	Difference(others ...Set) Set
 
 	// Test whether every element in the set is in other. set <= other
@@ -70,6 +86,74 @@ type Set interface {
 	UnmarshalText(text []byte) error
 }
 
+// Call f for each element of s until it returns false.
+func Do(s Set, f func(interface{}) bool) {
+	if c, ok := s.(collections.Collection); ok {
+		c.Do(f)
+		return
+	}
+	for _, e := range s.ToSlice() {
+		if !f(e) {
+			return
+		}
+	}
+}
+
+// Returns a sequence over the elements of s. Unlike Foreach it stops as soon
+// as the consumer does.
+func All(s Set) iter.Seq[interface{}] {
+	if a, ok := s.(interface{ All() iter.Seq[interface{}] }); ok {
+		return a.All()
+	}
+	return func(yield func(interface{}) bool) {
+		Do(s, yield)
+	}
+}
+
+// Returns an iterator over a snapshot of the elements of s. Both the key and
+// the value of each item are the element.
+func IteratorOf(s Set) collections.Iterator {
+	if i, ok := s.(interface{ Iterator() collections.Iterator }); ok {
+		return i.Iterator()
+	}
+	return newIterator(s.ToSlice())
+}
+
+// Return a new set with elements in either s or other but not both. The
+// result has the implementation of s.
+func SymmetricDifference(s, other Set) Set {
+	if d, ok := s.(interface{ SymmetricDifference(Set) Set }); ok {
+		return d.SymmetricDifference(other)
+	}
+	n := s.Difference(other)
+	n.AddAll(other.Difference(s).ToSlice()...)
+	return n
+}
+
+// Test whether s and other contain the same elements.
+func Equal(s, other Set) bool {
+	if e, ok := s.(interface{ Equal(Set) bool }); ok {
+		return e.Equal(other)
+	}
+	return equal(s, other)
+}
+
+// Test whether s has no elements in common with other.
+func IsDisjoint(s, other Set) bool {
+	if d, ok := s.(interface{ IsDisjoint(Set) bool }); ok {
+		return d.IsDisjoint(other)
+	}
+	return isDisjoint(s, other)
+}
+
+// Encode s as a JSON array, as MarshalText of the sets of this package does
+func marshalText(s Set) ([]byte, error) {
+	if m, ok := s.(encoding.TextMarshaler); ok {
+		return m.MarshalText()
+	}
+	return json.Marshal(sortedElements(s))
+}
+
 // Create a new hash set
 func NewHashSet(initial ...interface{}) Set {
 	s := &HashSet{make(map[interface{}]nothing)}
@@ -0,0 +1,403 @@
+package set
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/maphash"
+	"iter"
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/billryan/collections"
+)
+
+type (
+	// ShardedSet is a concurrent set split into shards, each a map guarded
+	// by its own lock, so that goroutines working on different shards do
+	// not contend. Single element operations are linearizable, and Len,
+	// ToSlice, Snapshot and Clear see or change the whole set at a single
+	// point in time. Foreach and Do visit the set shard by shard without
+	// holding any lock while calling f, so f may change the set.
+	ShardedSet struct {
+		shards []shard
+		size   atomic.Int64
+	}
+
+	shard struct {
+		sync.RWMutex
+		hash map[interface{}]nothing
+	}
+)
+
+// Create a new sharded set with a number of shards suited to the number of
+// CPUs
+func NewShardedSet(initial ...interface{}) *ShardedSet {
+	return NewShardedSetWithShards(4*runtime.GOMAXPROCS(0), initial...)
+}
+
+// Create a new sharded set with n shards, rounded up to a power of two
+func NewShardedSetWithShards(n int, initial ...interface{}) *ShardedSet {
+	if n < 1 {
+		panic(fmt.Sprintf("set: %d shards", n))
+	}
+	s := &ShardedSet{shards: make([]shard, 1<<bits.Len(uint(n-1)))}
+	for i := range s.shards {
+		s.shards[i].hash = make(map[interface{}]nothing)
+	}
+
+	for _, v := range initial {
+		s.Add(v)
+	}
+
+	return s
+}
+
+// Adds the specified element to this set if it is not already present (optional operation).
+func (s *ShardedSet) Add(e interface{}) {
+	s.AddIfAbsent(e)
+}
+
+// Adds the specified element to this set if it is not already present.
+// Returns true if it was added. Of several goroutines adding the same
+// element at once, exactly one gets true.
+func (s *ShardedSet) AddIfAbsent(e interface{}) bool {
+	sh := s.shardOf(e)
+	sh.Lock()
+	defer sh.Unlock()
+	if _, exist := sh.hash[e]; exist {
+		return false
+	}
+	sh.hash[e] = nothing{}
+	s.size.Add(1)
+	return true
+}
+
+// Adds all of the elements to this set if they're not already present (optional operation).
+func (s *ShardedSet) AddAll(es ...interface{}) {
+	for _, e := range es {
+		s.AddIfAbsent(e)
+	}
+}
+
+// Removes all of the elements from this set (optional operation).
+func (s *ShardedSet) Clear() {
+	s.lockAll()
+	defer s.unlockAll()
+	for i := range s.shards {
+		s.shards[i].hash = make(map[interface{}]nothing)
+	}
+	s.size.Store(0)
+}
+
+// Returns true if this set contains the specified element.
+func (s *ShardedSet) Contains(e interface{}) bool {
+	sh := s.shardOf(e)
+	sh.RLock()
+	defer sh.RUnlock()
+	_, exist := sh.hash[e]
+	return exist
+}
+
+// Returns true if this set contains all of the elements of the specified collection.
+func (s *ShardedSet) ContainsAll(es ...interface{}) bool {
+	for _, e := range es {
+		if !s.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Call f for each item in the set
+func (s *ShardedSet) Foreach(f func(interface{})) {
+	s.Do(func(e interface{}) bool {
+		f(e)
+		return true
+	})
+}
+
+// Call f for each item in the set until it returns false. Each shard is
+// copied under its lock, and f is called on the copy.
+func (s *ShardedSet) Do(f func(interface{}) bool) {
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.RLock()
+		elements := make([]interface{}, 0, len(sh.hash))
+		for e := range sh.hash {
+			elements = append(elements, e)
+		}
+		sh.RUnlock()
+		for _, e := range elements {
+			if !f(e) {
+				return
+			}
+		}
+	}
+}
+
+// Returns a sequence over the elements in this set.
+func (s *ShardedSet) All() iter.Seq[interface{}] {
+	return s.Do
+}
+
+// Returns an iterator over a snapshot of the elements in this set.
+func (s *ShardedSet) Iterator() collections.Iterator {
+	return newIterator(s.ToSlice())
+}
+
+// Call f for each item in the set, set result as new key
+func (s *ShardedSet) Map(f func(interface{}) interface{}) Set {
+	n := s.empty()
+	s.Foreach(func(e interface{}) {
+		n.Add(f(e))
+	})
+	return n
+}
+
+// Returns true if this set contains no elements.
+func (s *ShardedSet) IsEmpty() bool {
+	return s.size.Load() == 0
+}
+
+// Removes the specified element from this set if it is present (optional operation).
+// Of several goroutines removing the same element at once, exactly one
+// gets true.
+func (s *ShardedSet) Remove(e interface{}) bool {
+	sh := s.shardOf(e)
+	sh.Lock()
+	defer sh.Unlock()
+	if _, exist := sh.hash[e]; !exist {
+		return false
+	}
+	delete(sh.hash, e)
+	s.size.Add(-1)
+	return true
+}
+
+// Removes the specified elements from this set if it is present (optional operation).
+// Return true if all element exist.
+func (s *ShardedSet) RemoveAll(es ...interface{}) bool {
+	existAll := true
+	for _, e := range es {
+		if !s.Remove(e) {
+			existAll = false
+		}
+	}
+	return existAll
+}
+
+// Removes the elements for which pred returns true, returning how many
+// were removed. Each shard is locked while it is scanned, so pred must not
+// use the set.
+func (s *ShardedSet) RemoveIf(pred func(interface{}) bool) int {
+	removed := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.Lock()
+		for e := range sh.hash {
+			if pred(e) {
+				delete(sh.hash, e)
+				s.size.Add(-1)
+				removed++
+			}
+		}
+		sh.Unlock()
+	}
+	return removed
+}
+
+// Return the number of elements in set s (cardinality of s).
+func (s *ShardedSet) Len() uint32 {
+	return uint32(s.size.Load())
+}
+
+// Returns an slice containing all of the elements in this set at one point
+// in time.
+func (s *ShardedSet) ToSlice() []interface{} {
+	s.rLockAll()
+	defer s.rUnlockAll()
+	slice := make([]interface{}, 0, s.size.Load())
+	for i := range s.shards {
+		for e := range s.shards[i].hash {
+			slice = append(slice, e)
+		}
+	}
+	return slice
+}
+
+// Returns a HashSet holding the elements of this set at one point in time.
+func (s *ShardedSet) Snapshot() *HashSet {
+	s.rLockAll()
+	defer s.rUnlockAll()
+	n := make(map[interface{}]nothing, s.size.Load())
+	for i := range s.shards {
+		for e := range s.shards[i].hash {
+			n[e] = nothing{}
+		}
+	}
+	return &HashSet{n}
+}
+
+// Returns a deep clone of set, taken at one point in time
+func (s *ShardedSet) Clone() Set {
+	n := s.empty()
+	s.rLockAll()
+	defer s.rUnlockAll()
+	for i := range s.shards {
+		for e := range s.shards[i].hash {
+			n.shards[i].hash[e] = nothing{}
+		}
+	}
+	n.size.Store(s.size.Load())
+	return n
+}
+
+// Return a new set with elements common to the set and all others.
+func (s *ShardedSet) Intersection(others ...Set) Set {
+	return intersection(s.empty(), s, others)
+}
+
+// Return a new set with elements from the set and all others.
+func (s *ShardedSet) Union(others ...Set) Set {
+	return union(s.empty(), s, others)
+}
+
+// Return a new set with elements in the set that are not in the others.
+func (s *ShardedSet) Difference(others ...Set) Set {
+	return difference(s.empty(), s, others)
+}
+
+// Return a new set with elements in either the set or other but not both.
+func (s *ShardedSet) SymmetricDifference(other Set) Set {
+	return symmetricDifference(s.empty(), s, other)
+}
+
+// Test whether the set and other contain the same elements.
+func (s *ShardedSet) Equal(other Set) bool {
+	return equal(s, other)
+}
+
+// Test whether the set has no elements in common with other.
+func (s *ShardedSet) IsDisjoint(other Set) bool {
+	return isDisjoint(s, other)
+}
+
+// Test whether every element in the set is in other. set <= other
+func (s *ShardedSet) IsSubset(other Set) bool {
+	return isSubset(s, other)
+}
+
+// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+func (s *ShardedSet) IsProperSubset(other Set) bool {
+	return s.Len() < other.Len() && s.IsSubset(other)
+}
+
+// Test whether every element in other is in the set. set >= other
+func (s *ShardedSet) IsSuperset(other Set) bool {
+	return other.IsSubset(s)
+}
+
+// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+func (s *ShardedSet) IsProperSuperset(other Set) bool {
+	return s.Len() > other.Len() && s.IsSuperset(other)
+}
+
+// Replaces the elements of the set with those of a JSON array.
+func (s *ShardedSet) UnmarshalText(text []byte) error {
+	s.lazyInit()
+	var v []interface{}
+	err := json.Unmarshal(text, &v)
+	if err == nil {
+		s.Clear()
+		s.AddAll(v...)
+	}
+	return err
+}
+
+// Encode the set as a JSON array, sorted so that equal sets encode alike.
+func (s *ShardedSet) MarshalText() ([]byte, error) {
+	return json.Marshal(sortedElements(s))
+}
+
+// Encode the set as a JSON array, as MarshalText does.
+func (s *ShardedSet) MarshalJSON() ([]byte, error) {
+	return s.MarshalText()
+}
+
+// Replaces the elements of the set with those of a JSON array.
+func (s *ShardedSet) UnmarshalJSON(data []byte) error {
+	return s.UnmarshalText(data)
+}
+
+// Encode the set with gob, which keeps the types of the elements. Types
+// other than the basic ones must be registered with gob.Register.
+func (s *ShardedSet) GobEncode() ([]byte, error) {
+	return gobEncode(sortedElements(s))
+}
+
+// Replaces the elements of the set with those encoded by GobEncode.
+func (s *ShardedSet) GobDecode(data []byte) error {
+	s.lazyInit()
+	return decodeInto(s, data, gobDecode)
+}
+
+// Encode the set in a compact binary form. Only nil and basic types other
+// than complex numbers and uintptr can be encoded.
+func (s *ShardedSet) MarshalBinary() ([]byte, error) {
+	return marshalBinary(sortedElements(s))
+}
+
+// Replaces the elements of the set with those encoded by MarshalBinary.
+func (s *ShardedSet) UnmarshalBinary(data []byte) error {
+	s.lazyInit()
+	return decodeInto(s, data, unmarshalBinary)
+}
+
+// Give a zero ShardedSet its shards, so that it can be decoded into
+func (s *ShardedSet) lazyInit() {
+	if s.shards == nil {
+		s.shards = NewShardedSet().shards
+	}
+}
+
+// Seed of the hashes routing elements to shards
+var shardSeed = maphash.MakeSeed()
+
+// Get the shard holding e. Elements are routed by the hash maps use, so
+// that elements that are == share a shard and pointers go by identity.
+func (s *ShardedSet) shardOf(e interface{}) *shard {
+	return &s.shards[maphash.Comparable(shardSeed, e)&uint64(len(s.shards)-1)]
+}
+
+// Lock every shard, always in the same order so that two goroutines doing
+// so cannot deadlock
+func (s *ShardedSet) lockAll() {
+	for i := range s.shards {
+		s.shards[i].Lock()
+	}
+}
+
+func (s *ShardedSet) unlockAll() {
+	for i := range s.shards {
+		s.shards[i].Unlock()
+	}
+}
+
+func (s *ShardedSet) rLockAll() {
+	for i := range s.shards {
+		s.shards[i].RLock()
+	}
+}
+
+func (s *ShardedSet) rUnlockAll() {
+	for i := range s.shards {
+		s.shards[i].RUnlock()
+	}
+}
+
+// Create an empty set with as many shards as s
+func (s *ShardedSet) empty() *ShardedSet {
+	return NewShardedSetWithShards(len(s.shards))
+}
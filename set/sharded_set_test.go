@@ -0,0 +1,208 @@
+package set
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func newShardedSet(initial ...interface{}) Set {
+	return NewShardedSet(initial...)
+}
+
+func TestNewShardedSet(t *testing.T) {
+	s := NewShardedSet()
+	if s.Len() != 0 || !s.IsEmpty() {
+		t.Error("Length of empty init set should be 0")
+	}
+
+	s = NewShardedSetWithShards(3, 1, 4, 8, 4)
+	if s.Len() != 3 {
+		t.Error("Length should be 3")
+	}
+	if len(s.shards) != 4 {
+		t.Errorf("Shard count %d should be rounded up to 4", len(s.shards))
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Zero shards should panic")
+		}
+	}()
+	NewShardedSetWithShards(0)
+}
+
+func TestShardedSet_AddIfAbsent(t *testing.T) {
+	s := NewShardedSet()
+	if !s.AddIfAbsent("k1") {
+		t.Error("First add of 'k1' should report true")
+	}
+	if s.AddIfAbsent("k1") {
+		t.Error("Second add of 'k1' should report false")
+	}
+	if !s.Contains("k1") || s.Len() != 1 {
+		t.Error("Set should hold only 'k1'")
+	}
+}
+
+func TestShardedSet_Remove(t *testing.T) {
+	s := NewShardedSet(1, 2, 4)
+	if !s.Remove(2) {
+		t.Error("Removing 2 should report true")
+	}
+	if s.Remove(2) {
+		t.Error("Removing 2 again should report false")
+	}
+	if s.Contains(2) || s.Len() != 2 {
+		t.Error("Set should be 1, 4")
+	}
+	if s.RemoveAll(1, 3) {
+		t.Error("RemoveAll(1, 3) should report false as 3 is absent")
+	}
+	if !s.Equal(NewHashSet(4)) {
+		t.Error("Set should be 4")
+	}
+}
+
+func TestShardedSet_Equality(t *testing.T) {
+	type point struct{ X float64 }
+	s := NewShardedSetWithShards(64)
+	points := make([]*point, 100)
+	for i := range points {
+		points[i] = &point{float64(i)}
+		s.Add(points[i])
+	}
+	for i, p := range points {
+		p.X = -float64(i)
+		if !s.Contains(p) {
+			t.Fatalf("Set should find point %d by identity after it changed", i)
+		}
+	}
+	for i, p := range points {
+		if !s.Remove(p) {
+			t.Fatalf("Removing point %d should report true", i)
+		}
+	}
+	s.Add(point{0})
+	s.Add(point{math.Copysign(0, -1)})
+	if s.Len() != 1 || !s.Contains(point{math.Copysign(0, -1)}) {
+		t.Errorf("Points at 0 and -0 are == and should be one element, got %v", s.ToSlice())
+	}
+}
+
+func TestShardedSet_RemoveIf(t *testing.T) {
+	s := NewShardedSet()
+	for i := 0; i < 100; i++ {
+		s.Add(i)
+	}
+	removed := s.RemoveIf(func(e interface{}) bool { return e.(int)%2 == 0 })
+	if removed != 50 || s.Len() != 50 {
+		t.Errorf("RemoveIf removed %d leaving %d, want 50 and 50", removed, s.Len())
+	}
+	if s.Contains(10) || !s.Contains(11) {
+		t.Error("Set should hold only odd numbers")
+	}
+}
+
+func TestShardedSet_Snapshot(t *testing.T) {
+	s := NewShardedSet(1, 2, 4)
+	snap := s.Snapshot()
+	s.Add(8)
+	if !snap.Equal(NewHashSet(1, 2, 4)) {
+		t.Error("Snapshot should not see later changes")
+	}
+	c := s.Clone()
+	s.Clear()
+	if !s.IsEmpty() || c.Len() != 4 || !c.ContainsAll(1, 2, 4, 8) {
+		t.Error("Clone should not see later changes")
+	}
+}
+
+func TestShardedSet_DoRemovingCurrent(t *testing.T) {
+	s := NewShardedSet(1, 2, 3, 4)
+	s.Foreach(func(e interface{}) {
+		s.Remove(e)
+	})
+	if !s.IsEmpty() {
+		t.Error("Foreach should allow removing elements")
+	}
+}
+
+func TestShardedSet_UnmarshalText(t *testing.T) {
+	s := NewShardedSet("old")
+	if err := s.UnmarshalText([]byte(`["billryan", "test", "test"]`)); err != nil {
+		t.Fatalf("error while unmarshal text %s", err)
+	}
+	if s.Len() != 2 || !s.ContainsAll("billryan", "test") {
+		t.Error("Set should be 'billryan' and 'test'")
+	}
+}
+
+// Run under -race: goroutines add, remove and read overlapping elements
+// while the counters of successful adds and removes must match Len.
+func TestShardedSet_Stress(t *testing.T) {
+	const (
+		workers = 8
+		keys    = 1000
+		rounds  = 5000
+	)
+	s := NewShardedSetWithShards(8)
+	var added, removed atomic.Int64
+	wg := &sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				k := (i*7 + w*131) % keys
+				switch i % 4 {
+				case 0, 1:
+					if s.AddIfAbsent(k) {
+						added.Add(1)
+					}
+				case 2:
+					if s.Remove(k) {
+						removed.Add(1)
+					}
+				default:
+					snap := s.Snapshot()
+					if snap.Len() > keys {
+						t.Errorf("Snapshot holds %d elements of %d keys", snap.Len(), keys)
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	want := added.Load() - removed.Load()
+	if int64(s.Len()) != want {
+		t.Errorf("Len %d should be %d successful adds less removes", s.Len(), want)
+	}
+	if got := len(s.ToSlice()); int64(got) != want {
+		t.Errorf("ToSlice holds %d elements, want %d", got, want)
+	}
+}
+
+// Of many goroutines adding the same elements, exactly one wins each.
+func TestShardedSet_AddIfAbsentRace(t *testing.T) {
+	s := NewShardedSet()
+	var wins atomic.Int64
+	wg := &sync.WaitGroup{}
+	for w := 0; w < 16; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				if s.AddIfAbsent(i) {
+					wins.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if wins.Load() != 500 || s.Len() != 500 {
+		t.Errorf("%d wins and length %d, want 500", wins.Load(), s.Len())
+	}
+}
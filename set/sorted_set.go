@@ -0,0 +1,436 @@
+package set
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"runtime"
+
+	"github.com/billryan/collections"
+	"github.com/billryan/collections/skip"
+)
+
+type (
+	// SortedSet is a Set keeping its elements ordered by a less function,
+	// backed by a skip list. HeadSet, TailSet and SubSet return views sharing
+	// the elements of the set they were made from: changes made through a
+	// view show in the set and the other way round.
+	SortedSet struct {
+		list *skip.SkipList
+		less func(a, b interface{}) bool
+		// Bounds of a view, nil when unbounded
+		from, to *bound
+	}
+
+	bound struct {
+		e         interface{}
+		inclusive bool
+	}
+)
+
+var errNoLess = errors.New("set: cannot decode into a SortedSet without a less function")
+
+// Create a new sorted set, using the less function to determine the order.
+func NewSortedSet(less func(a, b interface{}) bool, initial ...interface{}) *SortedSet {
+	s := &SortedSet{list: skip.New(less), less: less}
+
+	for _, v := range initial {
+		s.Add(v)
+	}
+
+	return s
+}
+
+// Adds the specified element to this set if it is not already present (optional operation).
+// Adding an element outside the range of a view panics.
+func (s *SortedSet) Add(e interface{}) {
+	if !s.inRange(e) {
+		panic(fmt.Sprintf("set: %v is out of the range of the view", e))
+	}
+	s.list.Insert(e, nil)
+}
+
+// Adds all of the elements to this set if they're not already present (optional operation).
+func (s *SortedSet) AddAll(es ...interface{}) {
+	for _, e := range es {
+		s.Add(e)
+	}
+}
+
+// Removes all of the elements from this set (optional operation).
+func (s *SortedSet) Clear() {
+	for _, e := range s.ToSlice() {
+		s.list.Remove(e)
+	}
+}
+
+// Returns true if this set contains the specified element. Elements of a type
+// the less function fails to assert, such as a string in a set of ints, are
+// not contained. Any other panic of less is passed on.
+func (s *SortedSet) Contains(e interface{}) (found bool) {
+	defer recoverTypeAssertion(&found)
+	return s.inRange(e) && s.list.IndexOf(e) >= 0
+}
+
+// Returns true if this set contains all of the elements of the specified collection.
+func (s *SortedSet) ContainsAll(es ...interface{}) bool {
+	for _, e := range es {
+		if !s.Contains(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Call f for each item in the set, in ascending order
+func (s *SortedSet) Foreach(f func(interface{})) {
+	s.Do(func(e interface{}) bool {
+		f(e)
+		return true
+	})
+}
+
+// Call f for each item in the set in ascending order until it returns false
+func (s *SortedSet) Do(f func(interface{}) bool) {
+	low, high := s.ranks()
+	it := s.list.IteratorAt(low)
+	for i := low; i < high && it.Next(); i++ {
+		if !f(it.Key()) {
+			return
+		}
+	}
+}
+
+// Call f for each item in the set in descending order until it returns false.
+// The skip list only links forward, so the elements are first collected in
+// one walk, and f sees them as they were when Descend was called.
+func (s *SortedSet) Descend(f func(interface{}) bool) {
+	es := s.ToSlice()
+	for i := len(es) - 1; i >= 0; i-- {
+		if !f(es[i]) {
+			return
+		}
+	}
+}
+
+// Returns a sequence over the elements in this set, in ascending order.
+func (s *SortedSet) All() iter.Seq[interface{}] {
+	return s.Do
+}
+
+// Returns a sequence over the elements in this set, in descending order.
+func (s *SortedSet) Backward() iter.Seq[interface{}] {
+	return s.Descend
+}
+
+// Returns an iterator over a snapshot of the elements in this set, in ascending order.
+func (s *SortedSet) Iterator() collections.Iterator {
+	return newIterator(s.ToSlice())
+}
+
+// Call f for each item in the set, set result as new key. The results need
+// not be ordered by the same less function, so they are collected into a
+// HashSet.
+func (s *SortedSet) Map(f func(interface{}) interface{}) Set {
+	n := NewHashSet()
+	s.Do(func(e interface{}) bool {
+		n.Add(f(e))
+		return true
+	})
+	return n
+}
+
+// Returns true if this set contains no elements.
+func (s *SortedSet) IsEmpty() bool {
+	return s.Len() == 0
+}
+
+// Removes the specified element from this set if it is present (optional operation).
+func (s *SortedSet) Remove(e interface{}) bool {
+	if !s.Contains(e) {
+		return false
+	}
+	s.list.Remove(e)
+	return true
+}
+
+// Removes the specified elements from this set if it is present (optional operation).
+// Return true if all element exist.
+func (s *SortedSet) RemoveAll(es ...interface{}) bool {
+	existAll := true
+	for _, e := range es {
+		if !s.Remove(e) {
+			existAll = false
+		}
+	}
+	return existAll
+}
+
+// Return the number of elements in set s (cardinality of s).
+func (s *SortedSet) Len() uint32 {
+	low, high := s.ranks()
+	return uint32(high - low)
+}
+
+// Returns an slice containing all of the elements in this set, in ascending order.
+func (s *SortedSet) ToSlice() []interface{} {
+	slice := make([]interface{}, 0, s.Len())
+	s.Do(func(e interface{}) bool {
+		slice = append(slice, e)
+		return true
+	})
+	return slice
+}
+
+// Returns a deep clone of set. Cloning a view gives a new set holding the
+// elements of the view.
+func (s *SortedSet) Clone() Set {
+	return union(s.empty(), s, nil)
+}
+
+// Return a new set with elements common to the set and all others.
+func (s *SortedSet) Intersection(others ...Set) Set {
+	return intersection(s.empty(), s, others)
+}
+
+// Return a new set with elements from the set and all others.
+func (s *SortedSet) Union(others ...Set) Set {
+	return union(s.empty(), s, others)
+}
+
+// Return a new set with elements in the set that are not in the others.
+func (s *SortedSet) Difference(others ...Set) Set {
+	return difference(s.empty(), s, others)
+}
+
+// Return a new set with elements in either the set or other but not both.
+func (s *SortedSet) SymmetricDifference(other Set) Set {
+	return symmetricDifference(s.empty(), s, other)
+}
+
+// Test whether the set and other contain the same elements.
+func (s *SortedSet) Equal(other Set) bool {
+	return equal(s, other)
+}
+
+// Test whether the set has no elements in common with other.
+func (s *SortedSet) IsDisjoint(other Set) bool {
+	return isDisjoint(s, other)
+}
+
+// Test whether every element in the set is in other. set <= other
+func (s *SortedSet) IsSubset(other Set) bool {
+	return isSubset(s, other)
+}
+
+// Test whether the set is a proper subset of other, that is, set <= other and set != other.
+func (s *SortedSet) IsProperSubset(other Set) bool {
+	return s.Len() < other.Len() && s.IsSubset(other)
+}
+
+// Test whether every element in other is in the set. set >= other
+func (s *SortedSet) IsSuperset(other Set) bool {
+	return other.IsSubset(s)
+}
+
+// Test whether the set is a proper superset of other, that is, set >= other and set != other.
+func (s *SortedSet) IsProperSuperset(other Set) bool {
+	return s.Len() > other.Len() && s.IsSuperset(other)
+}
+
+// Replaces the elements of the set with those of a JSON array.
+func (s *SortedSet) UnmarshalText(text []byte) error {
+	if s.list == nil {
+		return errNoLess
+	}
+	var v []interface{}
+	err := json.Unmarshal(text, &v)
+	if err == nil {
+		s.Clear()
+		s.AddAll(v...)
+	}
+	return err
+}
+
+// Encode the set as a JSON array, in order.
+func (s *SortedSet) MarshalText() ([]byte, error) {
+	return json.Marshal(s.ToSlice())
+}
+
+// Encode the set as a JSON array, as MarshalText does.
+func (s *SortedSet) MarshalJSON() ([]byte, error) {
+	return s.MarshalText()
+}
+
+// Replaces the elements of the set with those of a JSON array.
+func (s *SortedSet) UnmarshalJSON(data []byte) error {
+	return s.UnmarshalText(data)
+}
+
+// Encode the set with gob, which keeps the types of the elements. Types
+// other than the basic ones must be registered with gob.Register.
+func (s *SortedSet) GobEncode() ([]byte, error) {
+	return gobEncode(s.ToSlice())
+}
+
+// Replaces the elements of the set with those encoded by GobEncode.
+func (s *SortedSet) GobDecode(data []byte) error {
+	if s.list == nil {
+		return errNoLess
+	}
+	return decodeInto(s, data, gobDecode)
+}
+
+// Encode the set in a compact binary form. Only nil and basic types other
+// than complex numbers and uintptr can be encoded.
+func (s *SortedSet) MarshalBinary() ([]byte, error) {
+	return marshalBinary(s.ToSlice())
+}
+
+// Replaces the elements of the set with those encoded by MarshalBinary.
+func (s *SortedSet) UnmarshalBinary(data []byte) error {
+	if s.list == nil {
+		return errNoLess
+	}
+	return decodeInto(s, data, unmarshalBinary)
+}
+
+// Returns the lowest element in this set.
+func (s *SortedSet) First() (interface{}, bool) {
+	low, high := s.ranks()
+	return s.at(low, low, high)
+}
+
+// Returns the highest element in this set.
+func (s *SortedSet) Last() (interface{}, bool) {
+	low, high := s.ranks()
+	return s.at(high-1, low, high)
+}
+
+// Returns the greatest element in this set less than or equal to e.
+func (s *SortedSet) Floor(e interface{}) (interface{}, bool) {
+	low, high := s.ranks()
+	return s.at(min(s.rankAfter(e), high)-1, low, high)
+}
+
+// Returns the least element in this set greater than or equal to e.
+func (s *SortedSet) Ceiling(e interface{}) (interface{}, bool) {
+	low, high := s.ranks()
+	return s.at(max(s.list.Rank(e), low), low, high)
+}
+
+// Returns the least element in this set strictly greater than e.
+func (s *SortedSet) Higher(e interface{}) (interface{}, bool) {
+	low, high := s.ranks()
+	return s.at(max(s.rankAfter(e), low), low, high)
+}
+
+// Returns the greatest element in this set strictly less than e.
+func (s *SortedSet) Lower(e interface{}) (interface{}, bool) {
+	low, high := s.ranks()
+	return s.at(min(s.list.Rank(e), high)-1, low, high)
+}
+
+// Returns a view of the elements strictly less than to.
+func (s *SortedSet) HeadSet(to interface{}) *SortedSet {
+	return s.view(nil, &bound{to, false})
+}
+
+// Returns a view of the elements greater than or equal to from.
+func (s *SortedSet) TailSet(from interface{}) *SortedSet {
+	return s.view(&bound{from, true}, nil)
+}
+
+// Returns a view of the elements ranging from from, inclusive, to to, exclusive.
+func (s *SortedSet) SubSet(from, to interface{}) *SortedSet {
+	return s.view(&bound{from, true}, &bound{to, false})
+}
+
+// Create a view sharing the elements of s, narrowed to the given bounds
+func (s *SortedSet) view(from, to *bound) *SortedSet {
+	v := &SortedSet{list: s.list, less: s.less, from: s.from, to: s.to}
+	if from != nil && (v.from == nil || s.less(v.from.e, from.e) || (!s.less(from.e, v.from.e) && !from.inclusive)) {
+		v.from = from
+	}
+	if to != nil && (v.to == nil || s.less(to.e, v.to.e) || (!s.less(v.to.e, to.e) && !to.inclusive)) {
+		v.to = to
+	}
+	return v
+}
+
+// Create an empty, unbounded set ordered like s
+func (s *SortedSet) empty() Set {
+	return NewSortedSet(s.less)
+}
+
+// Test whether e lies within the bounds of the set. Elements of a type less
+// fails to assert are not.
+func (s *SortedSet) inRange(e interface{}) (in bool) {
+	defer recoverTypeAssertion(&in)
+	if s.from != nil {
+		if s.less(e, s.from.e) || (!s.from.inclusive && !s.less(s.from.e, e)) {
+			return false
+		}
+	}
+	if s.to != nil {
+		if s.less(s.to.e, e) || (!s.to.inclusive && !s.less(e, s.to.e)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Set *ok to false if less panicked on a failed type assertion. Other panics
+// are passed on, so that bugs in less are not mistaken for missing elements.
+func recoverTypeAssertion(ok *bool) {
+	if r := recover(); r != nil {
+		if _, typeAssertion := r.(*runtime.TypeAssertionError); !typeAssertion {
+			panic(r)
+		}
+		*ok = false
+	}
+}
+
+// Get the positions in the underlying list of the first element of the set
+// and of the element just past its last one
+func (s *SortedSet) ranks() (int, int) {
+	low, high := 0, s.list.Len()
+	if s.from != nil {
+		if s.from.inclusive {
+			low = s.list.Rank(s.from.e)
+		} else {
+			low = s.rankAfter(s.from.e)
+		}
+	}
+	if s.to != nil {
+		if s.to.inclusive {
+			high = s.rankAfter(s.to.e)
+		} else {
+			high = s.list.Rank(s.to.e)
+		}
+	}
+	if high < low {
+		high = low
+	}
+	return low, high
+}
+
+// Get the number of elements in the underlying list less than or equal to e
+func (s *SortedSet) rankAfter(e interface{}) int {
+	rank := s.list.Rank(e)
+	if s.list.IndexOf(e) >= 0 {
+		rank++
+	}
+	return rank
+}
+
+// Get the element at position i of the underlying list if it lies within
+// [low, high)
+func (s *SortedSet) at(i, low, high int) (interface{}, bool) {
+	if i < low || i >= high {
+		return nil, false
+	}
+	e, _ := s.list.At(i)
+	return e, true
+}
@@ -0,0 +1,183 @@
+package set
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/billryan/collections/compare"
+)
+
+func newIntSortedSet(initial ...interface{}) Set {
+	return NewSortedSet(compare.Int.Less(), initial...)
+}
+
+func TestSortedSet_Order(t *testing.T) {
+	s := NewSortedSet(compare.Int.Less(), 5, 1, 4, 2, 3, 3)
+	if s.Len() != 5 {
+		t.Errorf("Length should be 5, got %d", s.Len())
+	}
+	if got := fmt.Sprint(s.ToSlice()); got != "[1 2 3 4 5]" {
+		t.Errorf("Slice should be sorted, got %s", got)
+	}
+
+	desc := make([]interface{}, 0)
+	for e := range s.Backward() {
+		desc = append(desc, e)
+		if len(desc) == 3 {
+			break
+		}
+	}
+	if got := fmt.Sprint(desc); got != "[5 4 3]" {
+		t.Errorf("Descending iteration should stop after 3, got %s", got)
+	}
+	desc = desc[:0]
+	s.SubSet(2, 5).Descend(func(e interface{}) bool {
+		desc = append(desc, e)
+		return true
+	})
+	if got := fmt.Sprint(desc); got != "[4 3 2]" {
+		t.Errorf("Descending iteration of the view should be [4 3 2], got %s", got)
+	}
+
+	sum := 0
+	s.Foreach(func(e interface{}) { sum += e.(int) })
+	if sum != 15 {
+		t.Errorf("Foreach should visit every element")
+	}
+}
+
+func TestSortedSet_Navigation(t *testing.T) {
+	s := NewSortedSet(compare.Int.Less(), 10, 20, 30)
+
+	cases := []struct {
+		name string
+		f    func(interface{}) (interface{}, bool)
+		e    int
+		want interface{}
+	}{
+		{"Floor", s.Floor, 20, 20},
+		{"Floor", s.Floor, 25, 20},
+		{"Floor", s.Floor, 5, nil},
+		{"Ceiling", s.Ceiling, 20, 20},
+		{"Ceiling", s.Ceiling, 25, 30},
+		{"Ceiling", s.Ceiling, 35, nil},
+		{"Higher", s.Higher, 20, 30},
+		{"Higher", s.Higher, 30, nil},
+		{"Lower", s.Lower, 20, 10},
+		{"Lower", s.Lower, 10, nil},
+	}
+	for _, c := range cases {
+		got, ok := c.f(c.e)
+		if got != c.want || ok != (c.want != nil) {
+			t.Errorf("%s(%d) should be %v, got %v", c.name, c.e, c.want, got)
+		}
+	}
+
+	if first, _ := s.First(); first != 10 {
+		t.Errorf("First should be 10, got %v", first)
+	}
+	if last, _ := s.Last(); last != 30 {
+		t.Errorf("Last should be 30, got %v", last)
+	}
+	if _, ok := NewSortedSet(compare.Int.Less()).First(); ok {
+		t.Error("Empty set should have no first element")
+	}
+}
+
+func TestSortedSet_Views(t *testing.T) {
+	s := NewSortedSet(compare.Int.Less(), 1, 2, 3, 4, 5, 6, 7, 8)
+
+	head := s.HeadSet(4)
+	if got := fmt.Sprint(head.ToSlice()); got != "[1 2 3]" || head.Len() != 3 {
+		t.Errorf("HeadSet(4) should be [1 2 3], got %s", got)
+	}
+	tail := s.TailSet(6)
+	if got := fmt.Sprint(tail.ToSlice()); got != "[6 7 8]" {
+		t.Errorf("TailSet(6) should be [6 7 8], got %s", got)
+	}
+	sub := s.SubSet(3, 7)
+	if got := fmt.Sprint(sub.ToSlice()); got != "[3 4 5 6]" {
+		t.Errorf("SubSet(3, 7) should be [3 4 5 6], got %s", got)
+	}
+	if got := fmt.Sprint(sub.HeadSet(100).TailSet(5).ToSlice()); got != "[5 6]" {
+		t.Errorf("Nested views should narrow the range, got %s", got)
+	}
+	if last, _ := sub.Last(); last != 6 {
+		t.Errorf("Last of the view should be 6, got %v", last)
+	}
+	if floor, _ := sub.Floor(100); floor != 6 {
+		t.Errorf("Floor in the view should be 6, got %v", floor)
+	}
+	if _, ok := sub.Lower(3); ok {
+		t.Error("Nothing in the view should be lower than 3")
+	}
+
+	// Views share their elements with the set
+	if sub.Contains(8) || sub.Remove(8) {
+		t.Error("View should not contain 8")
+	}
+	sub.Remove(4)
+	if s.Contains(4) {
+		t.Error("Removing through the view should remove from the set")
+	}
+	s.Add(40)
+	s.Add(5)
+	if !tail.Contains(40) || tail.Len() != 4 {
+		t.Error("Adding to the set should show in the view")
+	}
+	sub.Clear()
+	if got := fmt.Sprint(s.ToSlice()); got != "[1 2 7 8 40]" {
+		t.Errorf("Clearing the view should only remove its elements, got %s", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Adding outside the view should panic")
+		}
+	}()
+	head.Add(10)
+}
+
+func TestSortedSet_Algebra(t *testing.T) {
+	s := NewSortedSet(compare.Int.Less(), 3, 1, 2)
+	u := s.Union(NewHashSet(5, 4))
+	if _, ok := u.(*SortedSet); !ok {
+		t.Errorf("Union should be a *SortedSet, got %T", u)
+	}
+	if got := fmt.Sprint(u.ToSlice()); got != "[1 2 3 4 5]" {
+		t.Errorf("Union should be sorted, got %s", got)
+	}
+
+	c := s.SubSet(2, 10).Clone()
+	s.Clear()
+	if got := fmt.Sprint(c.ToSlice()); got != "[2 3]" {
+		t.Errorf("Clone of a view should hold its elements, got %s", got)
+	}
+
+	// Elements of a type the less function fails to assert are not contained
+	ints := NewSortedSet(func(a, b interface{}) bool { return a.(int) < b.(int) }, 1, 2, 3)
+	if i := NewHashSet("x", 2).Intersection(ints); i.Len() != 1 || !i.Contains(2) {
+		t.Errorf("Intersection should be [2], got %v", i.ToSlice())
+	}
+	if ints.Contains("x") || ints.SubSet(1, 3).Contains("x") || ints.Remove("x") {
+		t.Error("Set should not contain an element it cannot compare")
+	}
+
+	// Other panics of the less function are passed on
+	defer func() {
+		if r := recover(); r != "bug" {
+			t.Errorf("Contains should pass on the panic of less, got %v", r)
+		}
+	}()
+	NewSortedSet(func(a, b interface{}) bool { panic("bug") }, 1).Contains(2)
+}
+
+func TestSortedSet_UnmarshalText(t *testing.T) {
+	s := NewSortedSet(compare.String.Less(), "z")
+	if err := s.UnmarshalText([]byte(`["b", "a", "b"]`)); err != nil {
+		t.Fatalf("error while unmarshal text %s", err)
+	}
+	if got := fmt.Sprint(s.ToSlice()); got != "[a b]" {
+		t.Errorf("Set should be [a b], got %s", got)
+	}
+}
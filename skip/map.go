@@ -0,0 +1,121 @@
+package skip
+
+import (
+	"iter"
+)
+
+type (
+	// Map is a generically typed ordered map backed by a skip list.
+	Map[K, V any] struct {
+		list *SkipList
+	}
+)
+
+// Create a new ordered map, using the three-way compare function to
+// determine the order of the keys.
+func NewMap[K, V any](compare func(a, b K) int) *Map[K, V] {
+	return &Map[K, V]{New(func(a, b interface{}) bool {
+		return compare(a.(K), b.(K)) < 0
+	})}
+}
+
+// Get the value stored under key, and whether it was found.
+func (this *Map[K, V]) Get(key K) (V, bool) {
+	if this.list.size == 0 {
+		var zero V
+		return zero, false
+	}
+	n := this.list.find(key)
+	if n == nil {
+		var zero V
+		return zero, false
+	}
+	v, _ := n.value.(V)
+	return v, true
+}
+
+// Store value under key, replacing any previous value.
+func (this *Map[K, V]) Put(key K, value V) {
+	this.list.Insert(key, value)
+}
+
+// Delete key from the map. Returns true if it was present.
+func (this *Map[K, V]) Delete(key K) bool {
+	if this.list.size == 0 || this.list.find(key) == nil {
+		return false
+	}
+	this.list.Remove(key)
+	return true
+}
+
+// Get the number of entries in the map.
+func (this *Map[K, V]) Len() int {
+	return this.list.Len()
+}
+
+// Get the entry with the smallest key, if any.
+func (this *Map[K, V]) Min() (K, V, bool) {
+	if this.list.size == 0 {
+		return entry[K, V](nil)
+	}
+	return entry[K, V](this.list.root.next[0])
+}
+
+// Get the entry with the largest key, if any.
+func (this *Map[K, V]) Max() (K, V, bool) {
+	return entry[K, V](this.list.last())
+}
+
+// Call f for each entry in ascending key order until f returns false.
+func (this *Map[K, V]) Ascend(f func(K, V) bool) {
+	if this.list.size == 0 {
+		return
+	}
+	for cur := this.list.root.next[0]; cur != nil; cur = cur.next[0] {
+		k, v, _ := entry[K, V](cur)
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// Get a sequence over the entries in ascending key order.
+func (this *Map[K, V]) All() iter.Seq2[K, V] {
+	return this.Ascend
+}
+
+// Get a sequence over the entries in descending key order.
+func (this *Map[K, V]) Backward() iter.Seq2[K, V] {
+	return this.Descend
+}
+
+// Call f for each entry in descending key order until f returns false.
+// The skip list only links forward, so this takes O(n) extra space.
+func (this *Map[K, V]) Descend(f func(K, V) bool) {
+	if this.list.size == 0 {
+		return
+	}
+	nodes := make([]*node, 0, this.list.size)
+	for cur := this.list.root.next[0]; cur != nil; cur = cur.next[0] {
+		nodes = append(nodes, cur)
+	}
+	for i := len(nodes) - 1; i >= 0; i-- {
+		k, v, _ := entry[K, V](nodes[i])
+		if !f(k, v) {
+			return
+		}
+	}
+}
+
+// Unpack a node into a key, value and presence triple. A nil key or value
+// stored for an interface type comes back as the zero value.
+func entry[K, V any](n *node) (K, V, bool) {
+	if n == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	k, _ := n.key.(K)
+	v, _ := n.value.(V)
+	return k, v, true
+}
@@ -2,13 +2,19 @@ package skip
 
 import (
 	"fmt"
+	"iter"
 	"math/rand"
 	"time"
+
+	"github.com/billryan/collections"
 )
 
 type (
 	node struct {
-		next  []*node
+		next []*node
+		// width[i] is the number of level 0 steps taken by next[i]. A nil
+		// link spans to a virtual node just past the end of the list.
+		width []int
 		key   interface{}
 		value interface{}
 	}
@@ -19,14 +25,19 @@ type (
 		gen         *rand.Rand
 		probability float64
 	}
+	iterator struct {
+		next *node
+		cur  *node
+	}
 )
 
 // Create a new skip list
 func New(less func(interface{}, interface{}) bool) *SkipList {
 	gen := rand.New(rand.NewSource(time.Now().UnixNano()))
-	n := &node{make([]*node, 0), nil, nil}
+	n := &node{make([]*node, 0), make([]int, 0), nil, nil}
 	return &SkipList{n, 0, less, gen, 0.75}
 }
+
 func (this *SkipList) Do(f func(interface{}, interface{}) bool) {
 	if this.size == 0 {
 		return
@@ -40,31 +51,78 @@ func (this *SkipList) Do(f func(interface{}, interface{}) bool) {
 	}
 }
 
+// Get an iterator over the items in key order
+func (this *SkipList) Iterator() collections.Iterator {
+	if this.size == 0 {
+		return &iterator{}
+	}
+	return &iterator{this.root.next[0], nil}
+}
+
+// Get an iterator over the items in key order, starting at position i
+func (this *SkipList) IteratorAt(i int) collections.Iterator {
+	if i >= this.size {
+		return &iterator{}
+	}
+	if i < 0 {
+		i = 0
+	}
+	return &iterator{this.nodeAt(i), nil}
+}
+
+// Get a sequence over the items in key order
+func (this *SkipList) All() iter.Seq2[interface{}, interface{}] {
+	return func(yield func(interface{}, interface{}) bool) {
+		this.Do(yield)
+	}
+}
+
 // Get an item from the skip list
 func (this *SkipList) Get(key interface{}) interface{} {
 	if this.size == 0 {
 		return nil
 	}
 
-	cur := this.root
-	// Start at the top
-	for i := len(cur.next) - 1; i >= 0; i-- {
-		for this.less(cur.next[i].key, key) {
-			cur = cur.next[i]
-		}
+	if n := this.find(key); n != nil {
+		return n.value
 	}
-	cur = cur.next[0]
 
-	if this.equals(cur.key, key) {
-		return cur.value
+	return nil
+}
+
+// Get the key and value at position i in key order. Panics if i is out of
+// range.
+func (this *SkipList) At(i int) (interface{}, interface{}) {
+	n := this.nodeAt(i)
+	return n.key, n.value
+}
+
+// Get the position of key in key order, or -1 if it is not in the list
+func (this *SkipList) IndexOf(key interface{}) int {
+	prev, rank := this.getPrevious(key)
+	if len(prev) == 0 {
+		return -1
 	}
+	cur := prev[0].next[0]
+	if cur != nil && this.equals(cur.key, key) {
+		return rank[0]
+	}
+	return -1
+}
 
-	return nil
+// Get the number of keys in the list that are less than key. This is the
+// position key has, or would have if it were inserted.
+func (this *SkipList) Rank(key interface{}) int {
+	_, rank := this.getPrevious(key)
+	if len(rank) == 0 {
+		return 0
+	}
+	return rank[0]
 }
 
 // Add a new item into the skip list
 func (this *SkipList) Insert(key interface{}, value interface{}) {
-	prev := this.getPrevious(key)
+	prev, rank := this.getPrevious(key)
 
 	// Already in the list so just update the value
 	if len(prev) > 0 && prev[0].next[0] != nil && this.equals(prev[0].next[0].key, key) {
@@ -74,20 +132,35 @@ func (this *SkipList) Insert(key interface{}, value interface{}) {
 
 	h := len(this.root.next)
 	nh := this.pickHeight()
-	n := &node{make([]*node, nh), key, value}
+	n := &node{make([]*node, nh), make([]int, nh), key, value}
+	this.size++
+
+	// Position of the new node, counting the root as 0
+	pos := 1
+	if h > 0 {
+		pos = rank[0] + 1
+	}
+	for i := range n.width {
+		n.width[i] = this.size + 1 - pos
+	}
 
 	// Higher than anything seen before, so tack it on top
 	if nh > h {
 		this.root.next = append(this.root.next, n)
+		this.root.width = append(this.root.width, pos)
 	}
 
 	// Update the previous nodes
-	for i := 0; i < h && i < nh; i++ {
-		n.next[i] = prev[i].next[i]
-		prev[i].next[i] = n
+	for i := 0; i < h; i++ {
+		if i < nh {
+			n.next[i] = prev[i].next[i]
+			n.width[i] = prev[i].width[i] - (pos - 1 - rank[i])
+			prev[i].next[i] = n
+			prev[i].width[i] = pos - rank[i]
+		} else {
+			prev[i].width[i]++
+		}
 	}
-
-	this.size++
 }
 
 // Get the length of the skip list
@@ -97,7 +170,7 @@ func (this *SkipList) Len() int {
 
 // Remove an item from the skip list
 func (this *SkipList) Remove(key interface{}) interface{} {
-	prev := this.getPrevious(key)
+	prev, _ := this.getPrevious(key)
 	if len(prev) == 0 {
 		return nil
 	}
@@ -107,8 +180,11 @@ func (this *SkipList) Remove(key interface{}) interface{} {
 	if cur != nil && this.equals(key, cur.key) {
 		// Change all the linked lists
 		for i := 0; i < len(prev); i++ {
-			if prev[i] != nil && prev[i].next[i] != nil {
+			if prev[i].next[i] == cur {
+				prev[i].width[i] += cur.width[i] - 1
 				prev[i].next[i] = cur.next[i]
+			} else {
+				prev[i].width[i]--
 			}
 		}
 
@@ -116,6 +192,7 @@ func (this *SkipList) Remove(key interface{}) interface{} {
 		for i := len(this.root.next) - 1; i >= 0; i-- {
 			if this.root.next[i] == nil {
 				this.root.next = this.root.next[:i]
+				this.root.width = this.root.width[:i]
 			} else {
 				break
 			}
@@ -148,18 +225,73 @@ func (this *SkipList) String() string {
 }
 
 // Get a vertical list of nodes of all the things that occur
-//  immediately before "key"
-func (this *SkipList) getPrevious(key interface{}) []*node {
+//  immediately before "key", along with their positions
+func (this *SkipList) getPrevious(key interface{}) ([]*node, []int) {
 	cur := this.root
+	pos := 0
 	h := len(cur.next)
 	nodes := make([]*node, h)
+	ranks := make([]int, h)
 	for i := h - 1; i >= 0; i-- {
 		for cur.next[i] != nil && this.less(cur.next[i].key, key) {
+			pos += cur.width[i]
 			cur = cur.next[i]
 		}
 		nodes[i] = cur
+		ranks[i] = pos
 	}
-	return nodes
+	return nodes, ranks
+}
+
+// Find the node at position i, skipping ahead by link widths
+func (this *SkipList) nodeAt(i int) *node {
+	if i < 0 || i >= this.size {
+		panic(fmt.Sprintf("skip: index %d out of range [0:%d]", i, this.size))
+	}
+	cur := this.root
+	pos := 0
+	for l := len(cur.next) - 1; l >= 0; l-- {
+		for cur.next[l] != nil && pos+cur.width[l] <= i+1 {
+			pos += cur.width[l]
+			cur = cur.next[l]
+		}
+	}
+	return cur
+}
+
+// Find the node holding "key", or nil if there is none
+func (this *SkipList) find(key interface{}) *node {
+	prev, _ := this.getPrevious(key)
+	if len(prev) == 0 {
+		return nil
+	}
+	cur := prev[0].next[0]
+	if cur != nil && this.equals(cur.key, key) {
+		return cur
+	}
+	return nil
+}
+
+// Find the first node in the list, or nil if the list is empty
+func (this *SkipList) first() *node {
+	if this.size == 0 {
+		return nil
+	}
+	return this.root.next[0]
+}
+
+// Find the last node in the list, or nil if the list is empty
+func (this *SkipList) last() *node {
+	if this.size == 0 {
+		return nil
+	}
+	cur := this.root
+	for i := len(cur.next) - 1; i >= 0; i-- {
+		for cur.next[i] != nil {
+			cur = cur.next[i]
+		}
+	}
+	return cur
 }
 
 // Defines an equals method in terms of "less"
@@ -178,3 +310,24 @@ func (this *SkipList) pickHeight() int {
 	}
 	return h
 }
+
+func (this *iterator) Next() bool {
+	if this.next == nil {
+		return false
+	}
+	this.cur = this.next
+	this.next = this.next.next[0]
+	return true
+}
+
+func (this *iterator) Key() interface{} {
+	return this.cur.key
+}
+
+func (this *iterator) Value() interface{} {
+	return this.cur.value
+}
+
+func (this *iterator) Err() error {
+	return nil
+}
@@ -36,3 +36,149 @@ func Test(t *testing.T) {
 		t.Errorf("expecting sorted iteration of all keys")
 	}
 }
+
+func TestRemove(t *testing.T) {
+	sl := New(func(a, b interface{}) bool {
+		return a.(int) < b.(int)
+	})
+	for i := 0; i < 1000; i++ {
+		sl.Insert(i, i)
+	}
+	if sl.Get(1000) != nil {
+		t.Errorf("expecting no value past the last key")
+	}
+	for i := 0; i < 1000; i += 2 {
+		if sl.Remove(i) != i {
+			t.Errorf("expecting to remove %d", i)
+		}
+	}
+	if sl.Len() != 500 {
+		t.Errorf("expecting len 500")
+	}
+	prev := -1
+	sl.Do(func(k, v interface{}) bool {
+		if k.(int)%2 == 0 || k.(int) <= prev {
+			t.Errorf("unexpected key %d after %d", k, prev)
+		}
+		prev = k.(int)
+		return true
+	})
+	for i := 1; i < 1000; i += 2 {
+		if sl.Get(i) != i {
+			t.Errorf("expecting sl[%d] == %d", i, i)
+		}
+	}
+}
+
+func TestIterator(t *testing.T) {
+	sl := New(func(a, b interface{}) bool {
+		return a.(int) < b.(int)
+	})
+	if sl.Iterator().Next() {
+		t.Errorf("expecting empty iterator")
+	}
+	for _, k := range []int{3, 1, 2} {
+		sl.Insert(k, k*100)
+	}
+
+	it := sl.Iterator()
+	ks := make([]int, 0)
+	for it.Next() {
+		if it.Value().(int) != it.Key().(int)*100 {
+			t.Errorf("expecting value of %v to be %v", it.Key(), it.Key().(int)*100)
+		}
+		ks = append(ks, it.Key().(int))
+	}
+	if len(ks) != 3 || ks[0] != 1 || ks[2] != 3 || it.Err() != nil {
+		t.Errorf("expecting sorted iteration of all keys")
+	}
+
+	ks = ks[:0]
+	for k := range sl.All() {
+		ks = append(ks, k.(int))
+		if len(ks) == 2 {
+			break
+		}
+	}
+	if len(ks) != 2 || ks[1] != 2 {
+		t.Errorf("expecting iteration to stop after 2 keys")
+	}
+}
+
+func TestRandomAccess(t *testing.T) {
+	sl := New(func(a, b interface{}) bool {
+		return a.(int) < b.(int)
+	})
+	keys := make([]int, 0)
+	for i := 0; i < 500; i++ {
+		sl.Insert(i*2, i)
+		keys = append(keys, i*2)
+	}
+	for i := 0; i < 500; i += 3 {
+		sl.Remove(i * 2)
+	}
+	keys = keys[:0]
+	sl.Do(func(k, v interface{}) bool {
+		keys = append(keys, k.(int))
+		return true
+	})
+
+	for i, k := range keys {
+		if key, _ := sl.At(i); key.(int) != k {
+			t.Fatalf("expecting key %d at %d, got %v", k, i, key)
+		}
+		if sl.IndexOf(k) != i {
+			t.Fatalf("expecting %d at index %d, got %d", k, i, sl.IndexOf(k))
+		}
+	}
+	if sl.IndexOf(1) != -1 || sl.IndexOf(-2) != -1 || sl.IndexOf(10000) != -1 {
+		t.Errorf("expecting missing keys to have index -1")
+	}
+
+	ks := sl.Keys().Slice(10, 13)
+	if len(ks) != 3 || ks[0] != keys[10] || ks[2] != keys[12] {
+		t.Errorf("expecting keys 10 to 12, got %v", ks)
+	}
+	if v := sl.Values().At(5); v.(int) != keys[5]/2 {
+		t.Errorf("expecting value %d, got %v", keys[5]/2, v)
+	}
+	if sl.Values().IndexOf(keys[7]/2) != 7 {
+		t.Errorf("expecting value of key %d at index 7", keys[7])
+	}
+	entries := sl.Entries()
+	if entries.IndexOf(entries.At(42)) != 42 {
+		t.Errorf("expecting entry at index 42")
+	}
+
+	sl.Insert(keys[3], []int{1})
+	if sl.Values().IndexOf([]int{1}) != -1 || entries.IndexOf(entries.At(3)) != -1 {
+		t.Errorf("expecting values that cannot be compared not to be found")
+	}
+	if entries.IndexOf(entries.At(4)) != 4 {
+		t.Errorf("expecting entry at index 4")
+	}
+}
+
+func TestRank(t *testing.T) {
+	sl := New(func(a, b interface{}) bool {
+		return a.(int) < b.(int)
+	})
+	if sl.Rank(5) != 0 || sl.IteratorAt(0).Next() {
+		t.Errorf("expecting empty list to rank everything first")
+	}
+	for i := 0; i < 10; i++ {
+		sl.Insert(i*10, i)
+	}
+	if sl.Rank(30) != 3 || sl.Rank(35) != 4 || sl.Rank(-1) != 0 || sl.Rank(1000) != 10 {
+		t.Errorf("expecting ranks to count the smaller keys")
+	}
+
+	it := sl.IteratorAt(8)
+	ks := make([]int, 0)
+	for it.Next() {
+		ks = append(ks, it.Key().(int))
+	}
+	if len(ks) != 2 || ks[0] != 80 || ks[1] != 90 {
+		t.Errorf("expecting iteration from position 8, got %v", ks)
+	}
+}
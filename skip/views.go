@@ -0,0 +1,109 @@
+package skip
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/billryan/collections"
+)
+
+type (
+	// view exposes one aspect of the items of a skip list as a
+	// collections.RandomAccess
+	view struct {
+		list    *SkipList
+		item    func(n *node) interface{}
+		indexOf func(item interface{}) int
+	}
+)
+
+// Get a view of the keys in order
+func (this *SkipList) Keys() collections.RandomAccess {
+	return &view{this, func(n *node) interface{} {
+		return n.key
+	}, this.IndexOf}
+}
+
+// Get a view of the values in key order. IndexOf on this view takes O(n) and
+// never finds values that == cannot compare, such as slices.
+func (this *SkipList) Values() collections.RandomAccess {
+	v := &view{list: this}
+	v.item = func(n *node) interface{} {
+		return n.value
+	}
+	v.indexOf = func(item interface{}) int {
+		i := 0
+		for cur := this.first(); cur != nil; cur = cur.next[0] {
+			if same(cur.value, item) {
+				return i
+			}
+			i++
+		}
+		return -1
+	}
+	return v
+}
+
+// Get a view of the key/value pairs in key order, as collections.Entry items.
+// IndexOf on this view never finds entries whose values == cannot compare.
+func (this *SkipList) Entries() collections.RandomAccess {
+	return &view{this, func(n *node) interface{} {
+		return collections.Entry{Key: n.key, Value: n.value}
+	}, func(item interface{}) int {
+		e, ok := item.(collections.Entry)
+		if !ok {
+			return -1
+		}
+		i := this.IndexOf(e.Key)
+		if i < 0 || !same(this.nodeAt(i).value, e.Value) {
+			return -1
+		}
+		return i
+	}}
+}
+
+func (this *view) Do(f func(interface{}) bool) {
+	for cur := this.list.first(); cur != nil; cur = cur.next[0] {
+		if !f(this.item(cur)) {
+			return
+		}
+	}
+}
+
+func (this *view) Len() int {
+	return this.list.size
+}
+
+func (this *view) At(i int) interface{} {
+	return this.item(this.list.nodeAt(i))
+}
+
+func (this *view) Slice(start, end int) []interface{} {
+	if start < 0 || end < start || end > this.list.size {
+		panic(fmt.Sprintf("skip: slice bounds [%d:%d] out of range [0:%d]", start, end, this.list.size))
+	}
+	items := make([]interface{}, 0, end-start)
+	if start == end {
+		return items
+	}
+	for cur := this.list.nodeAt(start); len(items) < end-start; cur = cur.next[0] {
+		items = append(items, this.item(cur))
+	}
+	return items
+}
+
+func (this *view) IndexOf(item interface{}) int {
+	return this.indexOf(item)
+}
+
+// Test whether a == b, taking values that == cannot compare, which would make
+// it panic, as different
+func same(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.ValueOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
@@ -0,0 +1,98 @@
+package splay
+
+import (
+	"iter"
+)
+
+type (
+	// Map is a generically typed ordered map backed by a splay tree.
+	Map[K, V any] struct {
+		tree *SplayTree
+	}
+	entry[K, V any] struct {
+		key   K
+		value V
+	}
+)
+
+// Create a new ordered map, using the three-way compare function to
+// determine the order of the keys.
+func NewMap[K, V any](compare func(a, b K) int) *Map[K, V] {
+	return &Map[K, V]{New(func(a, b interface{}) bool {
+		return compare(a.(*entry[K, V]).key, b.(*entry[K, V]).key) < 0
+	})}
+}
+
+// Get the value stored under key, and whether it was found.
+func (this *Map[K, V]) Get(key K) (V, bool) {
+	e := this.tree.Get(&entry[K, V]{key: key})
+	if e == nil {
+		var zero V
+		return zero, false
+	}
+	return e.(*entry[K, V]).value, true
+}
+
+// Store value under key, replacing any previous value.
+func (this *Map[K, V]) Put(key K, value V) {
+	this.tree.Add(&entry[K, V]{key, value})
+}
+
+// Delete key from the map. Returns true if it was present.
+func (this *Map[K, V]) Delete(key K) bool {
+	length := this.tree.Len()
+	this.tree.Remove(&entry[K, V]{key: key})
+	return this.tree.Len() < length
+}
+
+// Get the number of entries in the map.
+func (this *Map[K, V]) Len() int {
+	return this.tree.Len()
+}
+
+// Get the entry with the smallest key, if any.
+func (this *Map[K, V]) Min() (K, V, bool) {
+	return unpack[K, V](this.tree.First())
+}
+
+// Get the entry with the largest key, if any.
+func (this *Map[K, V]) Max() (K, V, bool) {
+	return unpack[K, V](this.tree.Last())
+}
+
+// Call f for each entry in ascending key order until f returns false.
+func (this *Map[K, V]) Ascend(f func(K, V) bool) {
+	this.tree.InOrder(func(v interface{}) bool {
+		e := v.(*entry[K, V])
+		return f(e.key, e.value)
+	})
+}
+
+// Get a sequence over the entries in ascending key order.
+func (this *Map[K, V]) All() iter.Seq2[K, V] {
+	return this.Ascend
+}
+
+// Get a sequence over the entries in descending key order.
+func (this *Map[K, V]) Backward() iter.Seq2[K, V] {
+	return this.Descend
+}
+
+// Call f for each entry in descending key order until f returns false.
+func (this *Map[K, V]) Descend(f func(K, V) bool) {
+	this.tree.ReverseInOrder(func(v interface{}) bool {
+		e := v.(*entry[K, V])
+		return f(e.key, e.value)
+	})
+}
+
+// Unpack a stored entry into a key, value and presence triple
+func unpack[K, V any](v Any) (K, V, bool) {
+	if v == nil {
+		var k K
+		var v V
+		return k, v, false
+	}
+	e := v.(*entry[K, V])
+	return e.key, e.value, true
+}
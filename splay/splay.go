@@ -2,22 +2,30 @@ package splay
 
 import (
 	"fmt"
+	"iter"
+
+	"github.com/billryan/collections"
 )
 
 type (
 	Any       interface{}
 	LessFunc  func(interface{}, interface{}) bool
-	VisitFunc func(interface{}) bool
+	VisitFunc = func(interface{}) bool
 
 	node struct {
 		value               Any
 		parent, left, right *node
+		// Number of nodes in the subtree rooted here
+		size int
 	}
 	nodei struct {
 		step int
 		node *node
 		prev *nodei
 	}
+	iterator struct {
+		next, cur *node
+	}
 
 	SplayTree struct {
 		length int
@@ -79,6 +87,52 @@ func (this *SplayTree) Get(item Any) Any {
 	}
 	return nil
 }
+
+// Get the value at position i in order. Panics if i is out of range.
+func (this *SplayTree) At(i int) interface{} {
+	n := this.nodeAt(i)
+	this.splay(n)
+	return n.value
+}
+
+// Get the values from position start up to but excluding end, in order.
+// Panics if the bounds are out of range.
+func (this *SplayTree) Slice(start, end int) []interface{} {
+	if start < 0 || end < start || end > this.length {
+		panic(fmt.Sprintf("splay: slice bounds [%d:%d] out of range [0:%d]", start, end, this.length))
+	}
+	values := make([]interface{}, 0, end-start)
+	if start == end {
+		return values
+	}
+	n := this.nodeAt(start)
+	this.splay(n)
+	for ; len(values) < end-start; n = n.successor() {
+		values = append(values, n.value)
+	}
+	return values
+}
+
+// Get the position of value in order, or -1 if it is not in the tree
+func (this *SplayTree) IndexOf(value interface{}) int {
+	rank := 0
+	n := this.root
+	for n != nil {
+		if this.less(value, n.value) {
+			n = n.left
+			continue
+		}
+		if this.less(n.value, value) {
+			rank += n.left.sizeOf() + 1
+			n = n.right
+			continue
+		}
+		rank += n.left.sizeOf()
+		this.splay(n)
+		return rank
+	}
+	return -1
+}
 func (this *SplayTree) Has(value Any) bool {
 	return this.Get(value) != nil
 }
@@ -89,7 +143,7 @@ func (this *SplayTree) Init() {
 
 func (this *SplayTree) Add(value Any) {
 	if this.length == 0 {
-		this.root = &node{value, nil, nil, nil}
+		this.root = &node{value, nil, nil, nil, 1}
 		this.length = 1
 		return
 	}
@@ -98,7 +152,7 @@ func (this *SplayTree) Add(value Any) {
 	for {
 		if this.less(value, n.value) {
 			if n.left == nil {
-				n.left = &node{value, n, nil, nil}
+				n.left = &node{value, n, nil, nil, 1}
 				this.length++
 				n = n.left
 				break
@@ -109,7 +163,7 @@ func (this *SplayTree) Add(value Any) {
 
 		if this.less(n.value, value) {
 			if n.right == nil {
-				n.right = &node{value, n, nil, nil}
+				n.right = &node{value, n, nil, nil, 1}
 				this.length++
 				n = n.right
 				break
@@ -119,13 +173,17 @@ func (this *SplayTree) Add(value Any) {
 		}
 
 		n.value = value
-		break
+		this.splay(n)
+		return
+	}
+	for p := n.parent; p != nil; p = p.parent {
+		p.size++
 	}
 	this.splay(n)
 }
 
 func (this *SplayTree) PreOrder(visit VisitFunc) {
-	if this.length == 1 {
+	if this.length == 0 {
 		return
 	}
 	i := &nodei{0, this.root, nil}
@@ -135,7 +193,7 @@ func (this *SplayTree) PreOrder(visit VisitFunc) {
 		case 0:
 			i.step++
 			if !visit(i.node.value) {
-				break
+				return
 			}
 		// Left
 		case 1:
@@ -155,7 +213,7 @@ func (this *SplayTree) PreOrder(visit VisitFunc) {
 	}
 }
 func (this *SplayTree) InOrder(visit VisitFunc) {
-	if this.length == 1 {
+	if this.length == 0 {
 		return
 	}
 	i := &nodei{0, this.root, nil}
@@ -171,7 +229,7 @@ func (this *SplayTree) InOrder(visit VisitFunc) {
 		case 1:
 			i.step++
 			if !visit(i.node.value) {
-				break
+				return
 			}
 		// Right
 		case 2:
@@ -184,8 +242,38 @@ func (this *SplayTree) InOrder(visit VisitFunc) {
 		}
 	}
 }
+func (this *SplayTree) ReverseInOrder(visit VisitFunc) {
+	if this.length == 0 {
+		return
+	}
+	i := &nodei{0, this.root, nil}
+	for i != nil {
+		switch i.step {
+		// Right
+		case 0:
+			i.step++
+			if i.node.right != nil {
+				i = &nodei{0, i.node.right, i}
+			}
+		// Value
+		case 1:
+			i.step++
+			if !visit(i.node.value) {
+				return
+			}
+		// Left
+		case 2:
+			i.step++
+			if i.node.left != nil {
+				i = &nodei{0, i.node.left, i}
+			}
+		default:
+			i = i.prev
+		}
+	}
+}
 func (this *SplayTree) PostOrder(visit VisitFunc) {
-	if this.length == 1 {
+	if this.length == 0 {
 		return
 	}
 	i := &nodei{0, this.root, nil}
@@ -207,7 +295,7 @@ func (this *SplayTree) PostOrder(visit VisitFunc) {
 		case 2:
 			i.step++
 			if !visit(i.node.value) {
-				break
+				return
 			}
 		default:
 			i = i.prev
@@ -217,6 +305,25 @@ func (this *SplayTree) PostOrder(visit VisitFunc) {
 func (this *SplayTree) Do(visit VisitFunc) {
 	this.InOrder(visit)
 }
+
+// Get an iterator over the values in order. Keys are the values themselves.
+func (this *SplayTree) Iterator() collections.Iterator {
+	if this.length == 0 {
+		return &iterator{}
+	}
+	n := this.root
+	for n.left != nil {
+		n = n.left
+	}
+	return &iterator{n, nil}
+}
+
+// Get a sequence over the values in order
+func (this *SplayTree) All() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		this.InOrder(yield)
+	}
+}
 func (this *SplayTree) Len() int {
 	return this.length
 }
@@ -235,96 +342,36 @@ func (this *SplayTree) Remove(value Any) {
 			n = n.right
 			continue
 		}
-
-		// First splay the parent node
-		if n.parent != nil {
-			this.splay(n.parent)
-		}
-
-		// No children
-		if n.left == nil && n.right == nil {
-			// guess we're the root node
-			if n.parent == nil {
-				this.root = nil
-				break
-			}
-			if n.parent.left == n {
-				n.parent.left = nil
-			} else {
-				n.parent.right = nil
-			}
-		} else if n.left == nil {
-			// root node
-			if n.parent == nil {
-				this.root = n.right
-				break
-			}
-			if n.parent.left == n {
-				n.parent.left = n.right
-			} else {
-				n.parent.right = n.right
-			}
-		} else if n.right == nil {
-			// root node
-			if n.parent == nil {
-				this.root = n.left
-				break
-			}
-			if n.parent.left == n {
-				n.parent.left = n.left
-			} else {
-				n.parent.right = n.left
-			}
-		} else {
-			// find the successor
-			s := n.right
-			for s.left != nil {
-				s = s.left
-			}
-
-			np := n.parent
-			nl := n.left
-			nr := n.right
-
-			sp := s.parent
-			sr := s.right
-
-			// Update parent
-			s.parent = np
-			if np == nil {
-				this.root = s
-			} else {
-				if np.left == n {
-					np.left = s
-				} else {
-					np.right = s
-				}
-			}
-
-			// Update left
-			s.left = nl
-			s.left.parent = s
-
-			// Update right
-			if nr != s {
-				s.right = nr
-				s.right.parent = s
-			}
-
-			// Update successor parent
-			if sp.left == s {
-				sp.left = sr
-			} else {
-				sp.right = sr
-			}
-		}
-
 		break
 	}
+	if n == nil {
+		return
+	}
 
-	if n != nil {
-		this.length--
+	// Bring the node to the top, then join its two subtrees
+	this.splay(n)
+	l, r := n.left, n.right
+	if l == nil {
+		this.root = r
+		if r != nil {
+			r.parent = nil
+		}
+	} else {
+		// The largest node on the left has no right child once splayed
+		l.parent = nil
+		this.root = l
+		m := l
+		for m.right != nil {
+			m = m.right
+		}
+		this.splay(m)
+		m.right = r
+		if r != nil {
+			r.parent = m
+		}
+		m.resize()
 	}
+	this.length--
 }
 func (this *SplayTree) String() string {
 	if this.length == 0 {
@@ -333,6 +380,25 @@ func (this *SplayTree) String() string {
 	return this.root.String()
 }
 
+// Find the node at position i using the subtree sizes
+func (this *SplayTree) nodeAt(i int) *node {
+	if i < 0 || i >= this.length {
+		panic(fmt.Sprintf("splay: index %d out of range [0:%d]", i, this.length))
+	}
+	n := this.root
+	for {
+		left := n.left.sizeOf()
+		if i < left {
+			n = n.left
+		} else if i > left {
+			i -= left + 1
+			n = n.right
+		} else {
+			return n
+		}
+	}
+}
+
 // Splay a node in the tree (send it to the top)
 func (this *SplayTree) splay(n *node) {
 	// Already root, nothing to do
@@ -371,48 +437,6 @@ func (this *SplayTree) splay(n *node) {
 	this.splay(n)
 }
 
-// Swap two nodes in the tree
-func (this *SplayTree) swap(n1, n2 *node) {
-	p1 := n1.parent
-	l1 := n1.left
-	r1 := n1.right
-
-	p2 := n2.parent
-	l2 := n2.left
-	r2 := n2.right
-
-	// Update node links
-	n1.parent = p2
-	n1.left = l2
-	n1.right = r2
-
-	n2.parent = p1
-	n2.left = l1
-	n2.right = r1
-
-	// Update parent links
-	if p1 != nil {
-		if p1.left == n1 {
-			p1.left = n2
-		} else {
-			p1.right = n2
-		}
-	}
-	if p2 != nil {
-		if p2.left == n2 {
-			p2.left = n1
-		} else {
-			p2.right = n1
-		}
-	}
-
-	if n1 == this.root {
-		this.root = n2
-	} else if n2 == this.root {
-		this.root = n1
-	}
-}
-
 // Node methods
 func (this *node) String() string {
 	str := "{" + fmt.Sprint(this.value) + "|"
@@ -456,6 +480,9 @@ func (this *node) rotateLeft() {
 	// Update this
 	this.parent = pivot
 	this.right = child
+
+	this.resize()
+	pivot.resize()
 }
 func (this *node) rotateRight() {
 	parent := this.parent
@@ -486,4 +513,56 @@ func (this *node) rotateRight() {
 	// Update this
 	this.parent = pivot
 	this.left = child
+
+	this.resize()
+	pivot.resize()
+}
+
+// Get the size of the subtree rooted at this node, which may be nil
+func (this *node) sizeOf() int {
+	if this == nil {
+		return 0
+	}
+	return this.size
+}
+
+// Recompute the size of this node from its children
+func (this *node) resize() {
+	this.size = this.left.sizeOf() + this.right.sizeOf() + 1
+}
+
+// Find the next node in order. Rotations preserve the order, so this stays
+// valid while the tree is splayed.
+func (this *node) successor() *node {
+	if this.right != nil {
+		n := this.right
+		for n.left != nil {
+			n = n.left
+		}
+		return n
+	}
+	n := this
+	for n.parent != nil && n.parent.right == n {
+		n = n.parent
+	}
+	return n.parent
+}
+
+// Iterator methods
+func (this *iterator) Next() bool {
+	if this.next == nil {
+		return false
+	}
+	this.cur = this.next
+	this.next = this.next.successor()
+	return true
+}
+func (this *iterator) Key() interface{} {
+	return this.cur.value
+}
+func (this *iterator) Value() interface{} {
+	return this.cur.value
+}
+func (this *iterator) Err() error {
+	return nil
 }
@@ -1,7 +1,7 @@
 package splay
 
 import (
-	//"fmt"
+	"fmt"
 	"testing"
 )
 
@@ -24,4 +24,113 @@ func Test(t *testing.T) {
 	if tree.Len() != 3 {
 		t.Errorf("expecting len 3")
 	}
-}
\ No newline at end of file
+}
+
+func TestOrder(t *testing.T) {
+	tree := New(func(a, b interface{}) bool {
+		return a.(int) < b.(int)
+	})
+
+	for _, v := range []int{5, 3, 8, 1, 4, 7, 9, 2, 6} {
+		tree.Add(v)
+	}
+	for _, v := range []int{5, 1, 9, 3} {
+		tree.Remove(v)
+	}
+	if tree.Len() != 5 {
+		t.Errorf("expecting len 5")
+	}
+
+	var asc, desc []int
+	tree.InOrder(func(v interface{}) bool {
+		asc = append(asc, v.(int))
+		return true
+	})
+	tree.ReverseInOrder(func(v interface{}) bool {
+		desc = append(desc, v.(int))
+		return len(desc) < 2
+	})
+	if fmt.Sprint(asc) != "[2 4 6 7 8]" {
+		t.Errorf("expecting in order traversal, got %v", asc)
+	}
+	if fmt.Sprint(desc) != "[8 7]" {
+		t.Errorf("expecting reverse traversal to stop early, got %v", desc)
+	}
+
+	one := New(tree.less)
+	one.Add(1)
+	n := 0
+	one.InOrder(func(v interface{}) bool {
+		n++
+		return true
+	})
+	if n != 1 {
+		t.Errorf("expecting single item tree to be visited once")
+	}
+}
+
+func TestIterator(t *testing.T) {
+	tree := New(func(a, b interface{}) bool {
+		return a.(int) < b.(int)
+	})
+	if tree.Iterator().Next() {
+		t.Errorf("expecting empty iterator")
+	}
+	for _, v := range []int{5, 3, 8, 1, 4} {
+		tree.Add(v)
+	}
+
+	it := tree.Iterator()
+	vs := make([]int, 0)
+	for it.Next() {
+		vs = append(vs, it.Value().(int))
+		// Splaying the tree while iterating keeps the order intact
+		tree.Get(8)
+	}
+	if fmt.Sprint(vs) != "[1 3 4 5 8]" || it.Err() != nil {
+		t.Errorf("expecting in order iteration, got %v", vs)
+	}
+
+	vs = vs[:0]
+	for v := range tree.All() {
+		vs = append(vs, v.(int))
+	}
+	if fmt.Sprint(vs) != "[1 3 4 5 8]" {
+		t.Errorf("expecting in order sequence, got %v", vs)
+	}
+}
+
+func TestRandomAccess(t *testing.T) {
+	tree := New(func(a, b interface{}) bool {
+		return a.(int) < b.(int)
+	})
+	for i := 0; i < 500; i++ {
+		tree.Add((i * 7919) % 1000)
+	}
+	for i := 0; i < 1000; i += 3 {
+		tree.Remove(i)
+	}
+	values := make([]int, 0)
+	tree.InOrder(func(v interface{}) bool {
+		values = append(values, v.(int))
+		return true
+	})
+	if len(values) != tree.Len() {
+		t.Fatalf("expecting %d values, got %d", tree.Len(), len(values))
+	}
+
+	for i, v := range values {
+		if got := tree.At(i); got.(int) != v {
+			t.Fatalf("expecting %d at %d, got %v", v, i, got)
+		}
+		if tree.IndexOf(v) != i {
+			t.Fatalf("expecting %d at index %d, got %d", v, i, tree.IndexOf(v))
+		}
+	}
+	if tree.IndexOf(3) != -1 {
+		t.Errorf("expecting removed value to have index -1")
+	}
+	if s := tree.Slice(20, 25); fmt.Sprint(s) != fmt.Sprint(values[20:25]) {
+		t.Errorf("expecting %v, got %v", values[20:25], s)
+	}
+}
@@ -1,5 +1,11 @@
 package stack
 
+import (
+	"iter"
+
+	"github.com/billryan/collections"
+)
+
 type (
 	Stack struct {
 		top *node
@@ -8,7 +14,12 @@ type (
 	node struct {
 		value interface{}
 		prev *node
-	}	
+	}
+	iterator struct {
+		next  *node
+		index int
+		value interface{}
+	}
 )
 // Create a new stack
 func New() *Stack {
@@ -41,4 +52,43 @@ func (this *Stack) Push(value interface{}) {
 	n := &node{value,this.top}
 	this.top = n
 	this.length++
-}
\ No newline at end of file
+}
+// Call f for each item from the top to the bottom of the stack until it returns false
+func (this *Stack) Do(f func(interface{}) bool) {
+	for n := this.top; n != nil; n = n.prev {
+		if !f(n.value) {
+			return
+		}
+	}
+}
+// Get an iterator over the items from the top to the bottom of the stack.
+// Keys are the positions of the items.
+func (this *Stack) Iterator() collections.Iterator {
+	return &iterator{this.top, -1, nil}
+}
+// Get a sequence over the items from the top to the bottom of the stack
+func (this *Stack) All() iter.Seq[interface{}] {
+	return this.Do
+}
+
+func (this *iterator) Next() bool {
+	if this.next == nil {
+		return false
+	}
+	this.value = this.next.value
+	this.next = this.next.prev
+	this.index++
+	return true
+}
+
+func (this *iterator) Key() interface{} {
+	return this.index
+}
+
+func (this *iterator) Value() interface{} {
+	return this.value
+}
+
+func (this *iterator) Err() error {
+	return nil
+}
@@ -39,4 +39,31 @@ func Test(t *testing.T) {
 	if s.Peek().(int) != 2 {
 		t.Errorf("Top of the stack should be 2")
 	}	
-}
\ No newline at end of file
+}
+
+func TestIterator(t *testing.T) {
+	s := New()
+	for i := 0; i < 3; i++ {
+		s.Push(i)
+	}
+
+	it := s.Iterator()
+	n := 0
+	for it.Next() {
+		if it.Key().(int) != n || it.Value().(int) != 2-n {
+			t.Errorf("expecting item %d to be %d", n, 2-n)
+		}
+		n++
+	}
+	if n != 3 || it.Err() != nil {
+		t.Errorf("expecting 3 items without error")
+	}
+
+	vs := make([]int, 0)
+	for v := range s.All() {
+		vs = append(vs, v.(int))
+	}
+	if len(vs) != 3 || vs[0] != 2 || vs[2] != 0 {
+		t.Errorf("expecting items from top to bottom")
+	}
+}
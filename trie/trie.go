@@ -2,6 +2,9 @@ package trie
 
 import (
 	"fmt"
+	"iter"
+
+	"github.com/billryan/collections"
 )
 
 type (
@@ -19,6 +22,10 @@ type (
 		node *node
 		prev *iterator
 	}
+	cursor struct {
+		top *iterator
+		cur *node
+	}
 )
 
 func toBytes(obj interface{}) []byte {
@@ -39,6 +46,43 @@ func (this *Trie) Do(handler func(interface{},interface{})bool) {
 		this.root.do(handler)
 	}
 }
+// Get an iterator over the items in byte order of their keys
+func (this *Trie) Iterator() collections.Iterator {
+	if this.size == 0 {
+		return &cursor{}
+	}
+	return &cursor{&iterator{0, this.root, nil}, nil}
+}
+// Get a sequence over the items in byte order of their keys
+func (this *Trie) All() iter.Seq2[interface{}, interface{}] {
+	return func(yield func(interface{}, interface{}) bool) {
+		this.Do(yield)
+	}
+}
+// Get a view of the keys in byte order
+func (this *Trie) Keys() collections.Collection {
+	return collections.DoFunc(func(f func(interface{}) bool) {
+		this.Do(func(k, v interface{}) bool {
+			return f(k)
+		})
+	})
+}
+// Get a view of the values in byte order of their keys
+func (this *Trie) Values() collections.Collection {
+	return collections.DoFunc(func(f func(interface{}) bool) {
+		this.Do(func(k, v interface{}) bool {
+			return f(v)
+		})
+	})
+}
+// Get a view of the key/value pairs in byte order, as collections.Entry items
+func (this *Trie) Entries() collections.Collection {
+	return collections.DoFunc(func(f func(interface{}) bool) {
+		this.Do(func(k, v interface{}) bool {
+			return f(collections.Entry{Key: k, Value: v})
+		})
+	})
+}
 func (this *Trie) Get(key interface{}) interface{} {
 	if this.size == 0 {
 		return nil
@@ -145,4 +189,35 @@ func (this *node) do(handler func(interface{}, interface{}) bool) bool {
 		}
 	}
 	return true
-}
\ No newline at end of file
+}
+
+// Cursor methods
+func (this *cursor) Next() bool {
+	for this.top != nil {
+		i := this.top
+		if i.step >= len(i.node.next) {
+			this.top = i.prev
+			continue
+		}
+		n := i.node.next[i.step]
+		i.step++
+		if n == nil {
+			continue
+		}
+		this.top = &iterator{0, n, i}
+		if n.key != nil {
+			this.cur = n
+			return true
+		}
+	}
+	return false
+}
+func (this *cursor) Key() interface{} {
+	return this.cur.key
+}
+func (this *cursor) Value() interface{} {
+	return this.cur.value
+}
+func (this *cursor) Err() error {
+	return nil
+}
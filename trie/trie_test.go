@@ -28,4 +28,34 @@ func Test(t *testing.T) {
 	if len(vs) != 2 || vs[0] != 1 || vs[1] != 2 {
 		t.Errorf("expected in order traversal")
 	}
-}
\ No newline at end of file
+}
+
+func TestIterator(t *testing.T) {
+	x := New()
+	if x.Iterator().Next() {
+		t.Errorf("expected empty iterator")
+	}
+	x.Insert("b", 2)
+	x.Insert("ab", 12)
+	x.Insert("a", 1)
+
+	it := x.Iterator()
+	ks := make([]string, 0)
+	for it.Next() {
+		ks = append(ks, it.Key().(string))
+	}
+	if len(ks) != 3 || ks[0] != "a" || ks[1] != "ab" || ks[2] != "b" || it.Err() != nil {
+		t.Errorf("expected in order iteration, got %v", ks)
+	}
+
+	ks = ks[:0]
+	for k, v := range x.All() {
+		if k.(string) == "ab" && v.(int) != 12 {
+			t.Errorf("expected ab to be 12")
+		}
+		ks = append(ks, k.(string))
+	}
+	if len(ks) != 3 {
+		t.Errorf("expected 3 keys, got %v", ks)
+	}
+}
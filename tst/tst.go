@@ -2,6 +2,9 @@ package tst
 
 import (
 	"fmt"
+	"iter"
+
+	"github.com/billryan/collections"
 )
 
 type (
@@ -19,6 +22,12 @@ type (
 		length int
 		root   *Node
 	}
+	cursor struct {
+		i     *NodeIterator
+		bs    []byte
+		key   string
+		value interface{}
+	}
 )
 
 // Create a new ternary search tree
@@ -30,56 +39,58 @@ func New() *TernarySearchTree {
 
 // Iterate over the collection
 func (this *TernarySearchTree) Do(callback func(string, interface{}) bool) {
-	if this.Len() == 0 {
-		return
-	}
-	bs := []byte{}
-	i := &NodeIterator{0, this.root, nil}
-	for i != nil {
-		switch i.step {
-		// Left
-		case 0:
-			i.step++
-			if i.node.left != nil {
-				i = &NodeIterator{0, i.node.left, i}
-				continue
-			}
-		// Value
-		case 1:
-			i.step++
-			if i.node.key > 0 {
-				bs = append(bs, i.node.key)
-			}
-			if i.node.value != nil {
-				if !callback(string(bs), i.node.value) {
-					return
-				}
-				continue
-			}
-		// Middle
-		case 2:
-			i.step++
-			if i.node.middle != nil {
-				i = &NodeIterator{0, i.node.middle, i}
-				continue
-			}
-		// Right
-		case 3:
-			if len(bs) > 0 {
-				bs = bs[:len(bs)-1]
-			}
-			i.step++
-			if i.node.right != nil {
-				i = &NodeIterator{0, i.node.right, i}
-				continue
-			}
-		// Backtrack
-		case 4:
-			i = i.prev
+	c := this.cursor()
+	for c.Next() {
+		if !callback(c.key, c.value) {
+			return
 		}
 	}
 }
 
+// Get an iterator over the collection in key order
+func (this *TernarySearchTree) Iterator() collections.Iterator {
+	return this.cursor()
+}
+
+// Get a sequence over the collection in key order
+func (this *TernarySearchTree) All() iter.Seq2[string, interface{}] {
+	return this.Do
+}
+
+// Get a view of the keys in order
+func (this *TernarySearchTree) Keys() collections.Collection {
+	return collections.DoFunc(func(f func(interface{}) bool) {
+		this.Do(func(k string, v interface{}) bool {
+			return f(k)
+		})
+	})
+}
+
+// Get a view of the values in key order
+func (this *TernarySearchTree) Values() collections.Collection {
+	return collections.DoFunc(func(f func(interface{}) bool) {
+		this.Do(func(k string, v interface{}) bool {
+			return f(v)
+		})
+	})
+}
+
+// Get a view of the key/value pairs in key order, as collections.Entry items
+func (this *TernarySearchTree) Entries() collections.Collection {
+	return collections.DoFunc(func(f func(interface{}) bool) {
+		this.Do(func(k string, v interface{}) bool {
+			return f(collections.Entry{Key: k, Value: v})
+		})
+	})
+}
+
+func (this *TernarySearchTree) cursor() *cursor {
+	if this.Len() == 0 {
+		return &cursor{}
+	}
+	return &cursor{i: &NodeIterator{0, this.root, nil}}
+}
+
 // Get the value at the specified key. Returns nil if not found.
 func (this *TernarySearchTree) Get(key string) interface{} {
 	if this.length == 0 {
@@ -312,3 +323,64 @@ func (this *Node) String() string {
 	str += "}"
 	return str
 }
+
+// Advance to the next key/value pair. The traversal state is kept between
+// calls so it can be resumed after each value.
+func (this *cursor) Next() bool {
+	for this.i != nil {
+		i := this.i
+		switch i.step {
+		// Left
+		case 0:
+			i.step++
+			if i.node.left != nil {
+				this.i = &NodeIterator{0, i.node.left, i}
+				continue
+			}
+		// Value
+		case 1:
+			i.step++
+			if i.node.key > 0 {
+				this.bs = append(this.bs, i.node.key)
+			}
+			if i.node.value != nil {
+				this.key = string(this.bs)
+				this.value = i.node.value
+				return true
+			}
+		// Middle
+		case 2:
+			i.step++
+			if i.node.middle != nil {
+				this.i = &NodeIterator{0, i.node.middle, i}
+				continue
+			}
+		// Right
+		case 3:
+			if len(this.bs) > 0 {
+				this.bs = this.bs[:len(this.bs)-1]
+			}
+			i.step++
+			if i.node.right != nil {
+				this.i = &NodeIterator{0, i.node.right, i}
+				continue
+			}
+		// Backtrack
+		case 4:
+			this.i = i.prev
+		}
+	}
+	return false
+}
+
+func (this *cursor) Key() interface{} {
+	return this.key
+}
+
+func (this *cursor) Value() interface{} {
+	return this.value
+}
+
+func (this *cursor) Err() error {
+	return nil
+}
@@ -80,4 +80,37 @@ func BenchmarkMapInsert(b *testing.B) {
 	for i, str := range strs {
 		m[str] = i
 	}
-}
\ No newline at end of file
+}
+
+func TestIterator(t *testing.T) {
+	tree := New()
+	if tree.Iterator().Next() {
+		t.Errorf("expecting empty iterator")
+	}
+	tree.Insert("test", 1)
+	tree.Insert("testing", 2)
+	tree.Insert("abcd", 0)
+
+	it := tree.Iterator()
+	keys := []string{}
+	for it.Next() {
+		keys = append(keys, it.Key().(string))
+	}
+	if len(keys) != 3 || keys[0] != "abcd" || keys[1] != "test" || keys[2] != "testing" || it.Err() != nil {
+		t.Errorf("expecting keys in order, got %v", keys)
+	}
+
+	keys = keys[:0]
+	for key, val := range tree.All() {
+		if key == "testing" && val.(int) != 2 {
+			t.Errorf("expecting testing=2")
+		}
+		keys = append(keys, key)
+		if len(keys) == 2 {
+			break
+		}
+	}
+	if len(keys) != 2 {
+		t.Errorf("expecting iteration to stop after 2 keys")
+	}
+}
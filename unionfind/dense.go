@@ -0,0 +1,144 @@
+// Package unionfind provides disjoint-set forests, which partition elements
+// into groups and merge groups in nearly constant amortized time, using
+// path compression and union by rank.
+//
+// Dense works on the ints 0 to n-1 and suits elements that are already
+// numbered, such as grid cells. UnionFind works on any comparable type,
+// numbering its elements as they are added.
+package unionfind
+
+import (
+	"fmt"
+
+	"github.com/billryan/collections/set"
+)
+
+type (
+	// Dense is a disjoint-set forest over the ints 0 to Len()-1.
+	Dense struct {
+		parent []int
+		rank   []uint8
+		// Number of elements in the group, kept for roots only
+		size  []int
+		count int
+	}
+)
+
+// Create a forest of n singleton groups, 0 to n-1
+func NewDense(n int) *Dense {
+	d := &Dense{
+		parent: make([]int, 0, n),
+		rank:   make([]uint8, 0, n),
+		size:   make([]int, 0, n),
+	}
+	for i := 0; i < n; i++ {
+		d.Add()
+	}
+	return d
+}
+
+// Add a new singleton group, returning its element, which is the previous
+// Len().
+func (d *Dense) Add() int {
+	x := len(d.parent)
+	d.parent = append(d.parent, x)
+	d.rank = append(d.rank, 0)
+	d.size = append(d.size, 1)
+	d.count++
+	return x
+}
+
+// Get the number of elements.
+func (d *Dense) Len() int {
+	return len(d.parent)
+}
+
+// Get the number of groups.
+func (d *Dense) Count() int {
+	return d.count
+}
+
+// Get the representative of the group of x. Elements of the same group have
+// the same representative until the group is merged. Panics if x is out of
+// range.
+func (d *Dense) Find(x int) int {
+	d.check(x)
+	root := x
+	for d.parent[root] != root {
+		root = d.parent[root]
+	}
+	// Point the whole path at the root
+	for d.parent[x] != root {
+		d.parent[x], x = root, d.parent[x]
+	}
+	return root
+}
+
+// Merge the groups of x and y. Returns false if they were already the same
+// group.
+func (d *Dense) Union(x, y int) bool {
+	x, y = d.Find(x), d.Find(y)
+	if x == y {
+		return false
+	}
+	// Hang the shallower tree under the deeper one
+	if d.rank[x] < d.rank[y] {
+		x, y = y, x
+	}
+	d.parent[y] = x
+	if d.rank[x] == d.rank[y] {
+		d.rank[x]++
+	}
+	d.size[x] += d.size[y]
+	d.count--
+	return true
+}
+
+// Test whether x and y are in the same group.
+func (d *Dense) Connected(x, y int) bool {
+	return d.Find(x) == d.Find(y)
+}
+
+// Get the number of elements in the group of x.
+func (d *Dense) SetSize(x int) int {
+	return d.size[d.Find(x)]
+}
+
+// Get the groups as sets of ints, ordered by their smallest element.
+func (d *Dense) Groups() []set.Set {
+	members := d.members()
+	groups := make([]set.Set, len(members))
+	for i, m := range members {
+		g := set.NewHashSet()
+		for _, x := range m {
+			g.Add(x)
+		}
+		groups[i] = g
+	}
+	return groups
+}
+
+// Get the elements of each group in order, the groups ordered by their
+// smallest element
+func (d *Dense) members() [][]int {
+	members := make([][]int, 0, d.count)
+	// Position in members of the group of each root
+	index := make(map[int]int, d.count)
+	for x := range d.parent {
+		root := d.Find(x)
+		i, ok := index[root]
+		if !ok {
+			i = len(members)
+			index[root] = i
+			members = append(members, make([]int, 0, d.size[root]))
+		}
+		members[i] = append(members[i], x)
+	}
+	return members
+}
+
+func (d *Dense) check(x int) {
+	if x < 0 || x >= len(d.parent) {
+		panic(fmt.Sprintf("unionfind: element %d out of range [0, %d)", x, len(d.parent)))
+	}
+}
@@ -0,0 +1,100 @@
+package unionfind
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/billryan/collections/set"
+)
+
+func TestDense(t *testing.T) {
+	d := NewDense(6)
+	if d.Len() != 6 || d.Count() != 6 {
+		t.Errorf("Got %d elements in %d groups, want 6 in 6", d.Len(), d.Count())
+	}
+	if !d.Union(0, 1) || !d.Union(2, 3) || !d.Union(1, 3) {
+		t.Error("Unions of distinct groups should report true")
+	}
+	if d.Union(0, 2) {
+		t.Error("Union of the same group should report false")
+	}
+	if !d.Connected(0, 3) || d.Connected(0, 4) {
+		t.Error("0 should be connected to 3 but not 4")
+	}
+	if d.SetSize(2) != 4 || d.SetSize(5) != 1 || d.Count() != 3 {
+		t.Errorf("Got sizes %d and %d in %d groups", d.SetSize(2), d.SetSize(5), d.Count())
+	}
+
+	if x := d.Add(); x != 6 || d.Count() != 4 {
+		t.Errorf("Add should make singleton 6, got %d in %d groups", x, d.Count())
+	}
+	groups := d.Groups()
+	want := []set.Set{set.NewBitSet(0, 1, 2, 3), set.NewBitSet(4), set.NewBitSet(5), set.NewBitSet(6)}
+	if len(groups) != len(want) {
+		t.Fatalf("Got %d groups, want %d", len(groups), len(want))
+	}
+	for i := range want {
+		if !set.Equal(groups[i], want[i]) {
+			t.Errorf("Group %d is %v, want %v", i, groups[i].ToSlice(), want[i].ToSlice())
+		}
+	}
+}
+
+func TestDense_OutOfRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Find out of range should panic")
+		}
+	}()
+	NewDense(2).Find(2)
+}
+
+// Compare against groups tracked naively as labels
+func TestDense_Random(t *testing.T) {
+	const n = 500
+	d := NewDense(n)
+	label := make([]int, n)
+	for i := range label {
+		label[i] = i
+	}
+	count := n
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := 0; i < 400; i++ {
+		x, y := r.IntN(n), r.IntN(n)
+		merged := d.Union(x, y)
+		if lx, ly := label[x], label[y]; lx != ly {
+			for j := range label {
+				if label[j] == ly {
+					label[j] = lx
+				}
+			}
+			count--
+			if !merged {
+				t.Fatalf("Union(%d, %d) should merge", x, y)
+			}
+		} else if merged {
+			t.Fatalf("Union(%d, %d) should not merge", x, y)
+		}
+	}
+	if d.Count() != count {
+		t.Errorf("Count %d, want %d", d.Count(), count)
+	}
+	for i := 0; i < 1000; i++ {
+		x, y := r.IntN(n), r.IntN(n)
+		if d.Connected(x, y) != (label[x] == label[y]) {
+			t.Fatalf("Connected(%d, %d) disagrees", x, y)
+		}
+	}
+	total := 0
+	for _, g := range d.Groups() {
+		total += int(g.Len())
+		g.Foreach(func(e interface{}) {
+			if d.SetSize(e.(int)) != int(g.Len()) {
+				t.Fatalf("SetSize(%d) disagrees with its group", e)
+			}
+		})
+	}
+	if total != n {
+		t.Errorf("Groups hold %d elements, want %d", total, n)
+	}
+}
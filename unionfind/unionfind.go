@@ -0,0 +1,108 @@
+package unionfind
+
+import (
+	"github.com/billryan/collections/set"
+)
+
+type (
+	// UnionFind is a disjoint-set forest over elements of any comparable
+	// type. Elements are added by Add or Union. Queries about elements that
+	// were never added treat them as singleton groups.
+	UnionFind[T comparable] struct {
+		index    map[T]int
+		elements []T
+		dense    Dense
+	}
+)
+
+// Create a forest with a singleton group for each of the elements
+func New[T comparable](elements ...T) *UnionFind[T] {
+	u := &UnionFind[T]{index: make(map[T]int, len(elements))}
+	for _, e := range elements {
+		u.Add(e)
+	}
+	return u
+}
+
+// Add a singleton group for x. Returns false if x was already present.
+func (u *UnionFind[T]) Add(x T) bool {
+	if _, ok := u.index[x]; ok {
+		return false
+	}
+	u.id(x)
+	return true
+}
+
+// Test whether x was added.
+func (u *UnionFind[T]) Contains(x T) bool {
+	_, ok := u.index[x]
+	return ok
+}
+
+// Get the number of elements.
+func (u *UnionFind[T]) Len() int {
+	return len(u.elements)
+}
+
+// Get the number of groups.
+func (u *UnionFind[T]) Count() int {
+	return u.dense.Count()
+}
+
+// Get the representative of the group of x, which is x itself if it was
+// never added.
+func (u *UnionFind[T]) Find(x T) T {
+	i, ok := u.index[x]
+	if !ok {
+		return x
+	}
+	return u.elements[u.dense.Find(i)]
+}
+
+// Merge the groups of x and y, adding them if they are not present. Returns
+// false if they were already the same group.
+func (u *UnionFind[T]) Union(x, y T) bool {
+	return u.dense.Union(u.id(x), u.id(y))
+}
+
+// Test whether x and y are in the same group.
+func (u *UnionFind[T]) Connected(x, y T) bool {
+	return u.Find(x) == u.Find(y)
+}
+
+// Get the number of elements in the group of x.
+func (u *UnionFind[T]) SetSize(x T) int {
+	i, ok := u.index[x]
+	if !ok {
+		return 1
+	}
+	return u.dense.SetSize(i)
+}
+
+// Get the groups as hash sets, ordered by the first added element of each.
+func (u *UnionFind[T]) Groups() []set.Set {
+	members := u.dense.members()
+	groups := make([]set.Set, len(members))
+	for i, m := range members {
+		g := set.NewHashSet()
+		for _, x := range m {
+			g.Add(u.elements[x])
+		}
+		groups[i] = g
+	}
+	return groups
+}
+
+// Get the dense element for x, adding it if it is not present
+func (u *UnionFind[T]) id(x T) int {
+	if i, ok := u.index[x]; ok {
+		return i
+	}
+	if u.index == nil {
+		u.index = make(map[T]int)
+	}
+	i := u.dense.Add()
+	u.index[x] = i
+	u.elements = append(u.elements, x)
+	return i
+}
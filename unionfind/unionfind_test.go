@@ -0,0 +1,59 @@
+package unionfind
+
+import (
+	"testing"
+
+	"github.com/billryan/collections/set"
+)
+
+func TestUnionFind(t *testing.T) {
+	u := New("alice", "bob")
+	if !u.Union("alice", "carol") || !u.Union("dave", "erin") {
+		t.Error("Unions of distinct groups should report true")
+	}
+	if u.Union("carol", "alice") {
+		t.Error("Union of the same group should report false")
+	}
+	if u.Len() != 5 || u.Count() != 3 {
+		t.Errorf("Got %d elements in %d groups, want 5 in 3", u.Len(), u.Count())
+	}
+	if !u.Connected("alice", "carol") || u.Connected("alice", "bob") {
+		t.Error("alice should be connected to carol but not bob")
+	}
+	if u.Find("carol") != u.Find("alice") || u.SetSize("carol") != 2 {
+		t.Error("carol should share the group of alice")
+	}
+	if u.Add("bob") || !u.Add("frank") {
+		t.Error("Add should report whether the element is new")
+	}
+
+	groups := u.Groups()
+	want := []set.Set{
+		set.NewHashSet("alice", "carol"),
+		set.NewHashSet("bob"),
+		set.NewHashSet("dave", "erin"),
+		set.NewHashSet("frank"),
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("Got %d groups, want %d", len(groups), len(want))
+	}
+	for i := range want {
+		if !set.Equal(groups[i], want[i]) {
+			t.Errorf("Group %d is %v, want %v", i, groups[i].ToSlice(), want[i].ToSlice())
+		}
+	}
+}
+
+func TestUnionFind_Absent(t *testing.T) {
+	var u UnionFind[int]
+	if u.Find(7) != 7 || u.SetSize(7) != 1 || u.Contains(7) {
+		t.Error("An absent element should be a singleton that was never added")
+	}
+	if !u.Connected(7, 7) || u.Connected(7, 8) {
+		t.Error("An absent element should be connected only to itself")
+	}
+	u.Union(7, 8)
+	if !u.Contains(8) || !u.Connected(7, 8) || u.Count() != 1 {
+		t.Error("The zero value should take unions")
+	}
+}